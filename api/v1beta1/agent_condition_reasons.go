@@ -0,0 +1,14 @@
+package v1beta1
+
+// InvalidIgnitionOverrideReason is the SpecSyncedCondition Reason set when
+// Spec.IgnitionConfigOverrides fails local parsing/schema validation (bad
+// JSON, or an ignition.version this cluster doesn't support) before the
+// reconciler ever calls the backend. Distinct from the generic
+// InputErrorReason so downstream automation can point the user straight at
+// their ignition override instead of guessing which field was wrong.
+const InvalidIgnitionOverrideReason = "InvalidIgnitionOverride"
+
+// InvalidInstallerArgsReason is the SpecSyncedCondition Reason set when
+// Spec.InstallerArgs contains a flag outside the coreos-installer allow-list,
+// caught by local validation before the reconciler calls the backend.
+const InvalidInstallerArgsReason = "InvalidInstallerArgs"
@@ -0,0 +1,21 @@
+package v1beta1
+
+import conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+// CSRApprovalCondition reports whether the most recent CertificateSigningRequest
+// AgentCSRApprover evaluated for this Agent's day-2 join was approved, so a
+// rejection (wrong requester, hostname/IP mismatch, stale CSR) is visible on
+// the Agent CR instead of only as a ClientSet event on the spoke cluster.
+const CSRApprovalCondition conditionsv1.ConditionType = "CSRApproval"
+
+const (
+	// CSRApprovedReason is CSRApprovalCondition's Reason when the last CSR
+	// AgentCSRApprover looked at for this Agent passed verification and was
+	// approved.
+	CSRApprovedReason = "CSRApproved"
+
+	// CSRRejectedReason is CSRApprovalCondition's Reason when AgentCSRApprover
+	// declined to approve a pending CSR; Message carries the specific
+	// attribute mismatch that triggered the rejection.
+	CSRRejectedReason = "CSRRejected"
+)
@@ -0,0 +1,30 @@
+package v1beta1
+
+import conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+// CSRPolicyCondition reports the outcome of the most recent CSR policy
+// decision applyCSRPolicy made for this Agent's day-2 join: the pending CSR
+// was approved, denied (by AgentCSRApprover's attribute checks or the
+// DenyCsrsAnnotation override), or there was no pending CSR to act on.
+// Distinct from CSRApprovalCondition, which only ever reports an
+// approved/rejected outcome and has no representation for the steady state
+// of "nothing pending right now".
+const CSRPolicyCondition conditionsv1.ConditionType = "CSRPolicy"
+
+// CSRPolicyReason is CSRPolicyCondition's machine-readable Reason.
+type CSRPolicyReason string
+
+const (
+	// CSRPolicyApprovedReason means the last pending CSR seen for this Agent
+	// was approved.
+	CSRPolicyApprovedReason CSRPolicyReason = "Approved"
+
+	// CSRPolicyDeniedReason means the last pending CSR seen for this Agent
+	// was denied; Message carries the reason (an attribute mismatch, or the
+	// DenyCsrsAnnotation override).
+	CSRPolicyDeniedReason CSRPolicyReason = "Denied"
+
+	// CSRPolicyNoActionReason means there was no pending CSR for this Agent
+	// to evaluate.
+	CSRPolicyNoActionReason CSRPolicyReason = "NoAction"
+)
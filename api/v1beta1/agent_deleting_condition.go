@@ -0,0 +1,22 @@
+package v1beta1
+
+import conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+// DeletingCondition reports whether AgentReconciler's finalizer is actively
+// draining a deleted Agent: unbinding a bound host and deregistering it from
+// the backend cluster, including its InfraEnv-side record. It's only set
+// once the Agent has a non-zero DeletionTimestamp, so an operator watching a
+// teardown that's taking a while can tell it's progressing (or why it's
+// stuck) instead of the object just silently sitting there pending deletion.
+const DeletingCondition conditionsv1.ConditionType = "Deleting"
+
+const (
+	// DeletingReason is DeletingCondition's Reason while the finalizer is
+	// draining cleanly.
+	DeletingReason = "Deleting"
+
+	// DeleteFailedReason is DeletingCondition's Reason when the backend
+	// cleanup the finalizer is waiting on errored; Message carries the
+	// backend error.
+	DeleteFailedReason = "DeleteFailed"
+)
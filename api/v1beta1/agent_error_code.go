@@ -0,0 +1,43 @@
+package v1beta1
+
+// AgentErrorCode is a machine-readable classification of why
+// SpecSyncedCondition went false, set as the condition's Reason alongside a
+// human-readable Message, so GitOps controllers and other downstream
+// automation can decide how to react (retry, surface to a user, give up)
+// without parsing free-form backend error strings.
+type AgentErrorCode string
+
+const (
+	// ClusterNotFoundErrorCode means the ClusterDeployment's backend cluster
+	// record doesn't exist yet (e.g. the ClusterDeployment controller hasn't
+	// registered it with the backend). Transient: retry once it appears.
+	ClusterNotFoundErrorCode AgentErrorCode = "ClusterNotFound"
+
+	// InvalidIgnitionOverrideErrorCode means Spec.IgnitionConfigOverride
+	// failed backend validation (e.g. malformed JSON or an invalid Ignition
+	// document). Permanent until the user fixes the override.
+	InvalidIgnitionOverrideErrorCode AgentErrorCode = "InvalidIgnitionOverride"
+
+	// InvalidInstallerArgsErrorCode means Spec.InstallerArgs failed backend
+	// validation. Permanent until the user fixes the args.
+	InvalidInstallerArgsErrorCode AgentErrorCode = "InvalidInstallerArgs"
+
+	// HostNotFoundErrorCode means the backend has no host record matching
+	// this Agent (e.g. it was deregistered). Permanent: the Agent CR should
+	// be deleted rather than retried.
+	HostNotFoundErrorCode AgentErrorCode = "HostNotFound"
+
+	// ApprovalRejectedErrorCode means UpdateHostApprovedInternal rejected
+	// approving this host. Permanent until the rejection reason is addressed.
+	ApprovalRejectedErrorCode AgentErrorCode = "ApprovalRejected"
+
+	// BindConflictErrorCode means Bind/UnbindHostInternal/RebindHostInternal
+	// failed because the host is already bound, or bound elsewhere, in a way
+	// that conflicts with the requested operation. Transient: retry once the
+	// conflicting state clears.
+	BindConflictErrorCode AgentErrorCode = "BindConflict"
+
+	// InternalBackendErrorCode is an unclassified backend failure (e.g. a 5xx
+	// with no more specific signal). Transient: retry with backoff.
+	InternalBackendErrorCode AgentErrorCode = "InternalBackendError"
+)
@@ -0,0 +1,35 @@
+package v1beta1
+
+import conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+// ReadyCondition is the stable, top-level summary condition third-party
+// controllers (e.g. a NodePool waiting on its hosts) should watch instead of
+// ANDing RequirementsMet, Connected, Installed, Validated and Bound together
+// themselves. It's the one field this API guarantees stays semantically
+// stable even if the sub-conditions it's derived from change.
+const ReadyCondition conditionsv1.ConditionType = "Ready"
+
+const (
+	// ReadyReason is ReadyCondition's Reason once every sub-condition that
+	// applies to the Agent's current state reports healthy.
+	ReadyReason = "Ready"
+
+	// NotReadyReason is ReadyCondition's Reason while at least one
+	// applicable sub-condition is unhealthy.
+	NotReadyReason = "NotReady"
+)
+
+const (
+	ReadyMsg    = "The agent's requirements, connectivity, validations and binding are all satisfied"
+	NotReadyMsg = "The agent is not ready: one or more of its requirements, connectivity, validations, installation or binding is not satisfied"
+)
+
+// HostRoleLabel, set by the reconciler once the backend host's role is
+// known, lets a higher-level controller select Agents by role the same way
+// it would select Nodes via node-role.kubernetes.io labels.
+const HostRoleLabel = "agent-install.openshift.io/role"
+
+const (
+	HostRoleMaster = "master"
+	HostRoleWorker = "worker"
+)
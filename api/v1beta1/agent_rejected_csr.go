@@ -0,0 +1,19 @@
+package v1beta1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// RejectedCSR records one CertificateSigningRequest AgentCSRApprover (or the
+// DenyCsrsAnnotation override) declined to approve, giving an operator the
+// same audit trail `kubectl certificate deny` would leave, without needing
+// spoke-cluster access to see it. Agent.Status.DebugInfo.RejectedCSRs holds
+// the most recent maxRejectedCSRs of these.
+type RejectedCSR struct {
+	// Name is the denied CertificateSigningRequest's name on the spoke cluster.
+	Name string `json:"name"`
+
+	// Reason is why the CSR was denied.
+	Reason string `json:"reason"`
+
+	// DeniedAt is when the denial was recorded.
+	DeniedAt metav1.Time `json:"deniedAt"`
+}
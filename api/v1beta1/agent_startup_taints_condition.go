@@ -0,0 +1,24 @@
+package v1beta1
+
+import conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+
+// StartupTaintsCondition reports whether every taint AgentReconciler is
+// responsible for (Spec.StartupTaints, plus any "node.startup/*" taint when
+// Spec.IgnoreAllStartupTaints is set) has been removed from the joined Node.
+// It stays False while the Node carries one of those taints, whether because
+// the host hasn't reached HostStageDone yet or because removal itself is
+// still pending, so a workload scheduled before the host is actually ready
+// can be traced back to this condition instead of looking like a silent
+// scheduling race.
+const StartupTaintsCondition conditionsv1.ConditionType = "StartupTaintsCleared"
+
+const (
+	// StartupTaintsClearedReason is StartupTaintsCondition's Reason once
+	// every targeted taint has been confirmed absent from the Node.
+	StartupTaintsClearedReason = "StartupTaintsCleared"
+
+	// StartupTaintsPendingReason is StartupTaintsCondition's Reason while a
+	// targeted taint is still present, whether because the Node isn't Ready
+	// yet, the host hasn't reached HostStageDone, or there is no Node at all.
+	StartupTaintsPendingReason = "StartupTaintsPending"
+)
@@ -0,0 +1,66 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-agent-install-openshift-io-v1beta1-agent,mutating=false,failurePolicy=fail,sideEffects=None,groups=agent-install.openshift.io,resources=agents,verbs=create;update,versions=v1beta1,name=vagent.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-agent-install-openshift-io-v1beta1-agent,mutating=true,failurePolicy=fail,sideEffects=None,groups=agent-install.openshift.io,resources=agents,verbs=create;update,versions=v1beta1,name=magent.kb.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers Agent's defaulting and validating
+// webhooks with mgr, following the same multi-kind webhook setup sibling
+// kubebuilder-based controller projects use for their CRDs.
+func (a *Agent) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(a).
+		Complete()
+}
+
+var _ webhook.Defaulter = &Agent{}
+
+// Default canonicalizes Spec.InstallerArgs ordering so the reconciler's
+// "same args, nothing to do" idempotence check doesn't depend on whatever
+// order the user happened to list flags in.
+func (a *Agent) Default() {
+	canonical, err := CanonicalizeInstallerArgs(a.Spec.InstallerArgs)
+	if err != nil {
+		// Malformed JSON is rejected by ValidateCreate/ValidateUpdate; leave
+		// it untouched here so the validating webhook reports the real error
+		// instead of this defaulting step masking it.
+		return
+	}
+	a.Spec.InstallerArgs = canonical
+}
+
+var _ webhook.Validator = &Agent{}
+
+// ValidateCreate rejects an InstallerArgs value that fails ValidateInstallerArgs
+// (malformed JSON, a flag outside the allow-list, or a path-taking flag
+// pointed outside allowedInstallerArgPathPrefixes) at admission time, rather
+// than accepting the object and surfacing the problem later as
+// SpecSyncedCondition=False.
+func (a *Agent) ValidateCreate() (admission.Warnings, error) {
+	return nil, a.validateInstallerArgs()
+}
+
+// ValidateUpdate applies the same InstallerArgs checks as ValidateCreate.
+func (a *Agent) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	return nil, a.validateInstallerArgs()
+}
+
+// ValidateDelete has nothing to check; deletion is always allowed.
+func (a *Agent) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (a *Agent) validateInstallerArgs() error {
+	if err := ValidateInstallerArgs(a.Spec.InstallerArgs); err != nil {
+		return fmt.Errorf("spec.installerArgs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// UnhealthyCondition is a single Agent condition type/status pair that, once
+// observed continuously for at least Timeout, marks the Agent a candidate for
+// remediation. HostStatus is an alternative to Type/Status for conditions
+// that live on Agent.Status.DebugInfo.State instead of Agent.Status.Conditions
+// (e.g. the host backend status getting stuck in "disconnected" or "error").
+type UnhealthyCondition struct {
+	// Type is the Agent condition type to watch, e.g. "Connected" or "Validated".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Status is the condition status that is considered unhealthy.
+	// +optional
+	Status corev1.ConditionStatus `json:"status,omitempty"`
+
+	// HostStatus is a backend host status (e.g. "disconnected", "error")
+	// that is considered unhealthy when observed instead of a condition.
+	// +optional
+	HostStatus string `json:"hostStatus,omitempty"`
+
+	// Timeout is how long Type/Status (or HostStatus) must hold continuously
+	// before the Agent becomes a remediation candidate.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// RemediationStrategyType is the action AgentHealthCheck takes against a
+// remediation target once it is within the maxUnhealthy budget.
+type RemediationStrategyType string
+
+const (
+	// RemediationStrategyAnnotateOnly only annotates the Agent with a
+	// remediation marker; nothing further is automated.
+	RemediationStrategyAnnotateOnly RemediationStrategyType = "AnnotateOnly"
+
+	// RemediationStrategyReboot reboots the host by cycling the linked
+	// BareMetalHost's online field false then true, falling back to
+	// V2ResetHost when the Agent has no linked BMH.
+	RemediationStrategyReboot RemediationStrategyType = "Reboot"
+
+	// RemediationStrategyReprovision clears the Agent's bound
+	// ClusterDeployment so the host is returned to the discovery pool and
+	// re-provisioned from scratch.
+	RemediationStrategyReprovision RemediationStrategyType = "Reprovision"
+)
+
+// RemediationAnnotation marks an Agent as currently under remediation by an
+// AgentHealthCheck, so a later reconcile of the same or another
+// AgentHealthCheck doesn't double-remediate it while the action is pending.
+const RemediationAnnotation = "agent-install.openshift.io/remediation"
+
+// AgentHealthCheckSpec defines the desired state of AgentHealthCheck, modeled
+// on Cluster API's MachineHealthCheck: a selector over Agents, the conditions
+// that make a selected Agent unhealthy, and a budget limiting how many of the
+// selected Agents may be remediated at once.
+type AgentHealthCheckSpec struct {
+	// Selector matches the Agents this AgentHealthCheck monitors.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// UnhealthyConditions lists the condition/timeout pairs that mark a
+	// selected Agent as a remediation candidate. An Agent is unhealthy if
+	// any entry matches.
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
+
+	// MaxUnhealthy caps how many of the selected Agents may be remediated
+	// at once, as an absolute number or a percentage of the selected set
+	// (e.g. 1, "40%"). Defaults to 100% when unset.
+	// +optional
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+
+	// RemediationStrategy is the action taken against Agents within the
+	// maxUnhealthy budget. Defaults to RemediationStrategyAnnotateOnly.
+	// +optional
+	RemediationStrategy RemediationStrategyType `json:"remediationStrategy,omitempty"`
+}
+
+// AgentHealthCheckStatus defines the observed state of AgentHealthCheck.
+type AgentHealthCheckStatus struct {
+	// ExpectedHosts is the number of Agents currently matched by Selector.
+	// +optional
+	ExpectedHosts int32 `json:"expectedHosts,omitempty"`
+
+	// CurrentHealthy is the number of matched Agents with no unhealthy
+	// condition past its timeout.
+	// +optional
+	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
+
+	// RemediationsAllowed is how many more Agents may start remediation
+	// before MaxUnhealthy is reached.
+	// +optional
+	RemediationsAllowed int32 `json:"remediationsAllowed,omitempty"`
+
+	// Targets lists the Agents currently being remediated.
+	// +optional
+	Targets []corev1.ObjectReference `json:"targets,omitempty"`
+
+	// Conditions defines the current state of the AgentHealthCheck.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=ahc
+// +kubebuilder:printcolumn:name="ExpectedHosts",type="integer",JSONPath=".status.expectedHosts"
+// +kubebuilder:printcolumn:name="CurrentHealthy",type="integer",JSONPath=".status.currentHealthy"
+
+// AgentHealthCheck provides MachineHealthCheck-style auto-remediation for
+// unhealthy Agents: Agents matching Spec.Selector are scanned every
+// reconcile, and those that have held an unhealthy condition past its
+// timeout are remediated one batch at a time, up to Spec.MaxUnhealthy.
+type AgentHealthCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AgentHealthCheckSpec   `json:"spec,omitempty"`
+	Status AgentHealthCheckStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AgentHealthCheckList contains a list of AgentHealthCheck
+type AgentHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AgentHealthCheck `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AgentHealthCheck{}, &AgentHealthCheckList{})
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy returns a deep copy of in.
+func (in *UnhealthyCondition) DeepCopy() *UnhealthyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AgentHealthCheckSpec) DeepCopyInto(out *AgentHealthCheckSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.UnhealthyConditions != nil {
+		out.UnhealthyConditions = make([]UnhealthyCondition, len(in.UnhealthyConditions))
+		for i := range in.UnhealthyConditions {
+			in.UnhealthyConditions[i].DeepCopyInto(&out.UnhealthyConditions[i])
+		}
+	}
+	if in.MaxUnhealthy != nil {
+		out.MaxUnhealthy = new(intstr.IntOrString)
+		*out.MaxUnhealthy = *in.MaxUnhealthy
+	}
+}
+
+// DeepCopy returns a deep copy of in.
+func (in *AgentHealthCheckSpec) DeepCopy() *AgentHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AgentHealthCheckStatus) DeepCopyInto(out *AgentHealthCheckStatus) {
+	*out = *in
+	if in.Targets != nil {
+		out.Targets = make([]corev1.ObjectReference, len(in.Targets))
+		copy(out.Targets, in.Targets)
+	}
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		for i := range in.Conditions {
+			in.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
+}
+
+// DeepCopy returns a deep copy of in.
+func (in *AgentHealthCheckStatus) DeepCopy() *AgentHealthCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentHealthCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AgentHealthCheck) DeepCopyInto(out *AgentHealthCheck) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy returns a deep copy of in.
+func (in *AgentHealthCheck) DeepCopy() *AgentHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *AgentHealthCheck) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *AgentHealthCheckList) DeepCopyInto(out *AgentHealthCheckList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]AgentHealthCheck, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a deep copy of in.
+func (in *AgentHealthCheckList) DeepCopy() *AgentHealthCheckList {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentHealthCheckList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *AgentHealthCheckList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
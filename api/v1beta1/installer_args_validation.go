@@ -0,0 +1,156 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// allowedInstallerArgs is the set of coreos-installer flags AgentWebhook
+// accepts for Spec.InstallerArgs, rejecting anything else at admission time
+// instead of letting a malformed or dangerous value reach the reconciler and
+// surface only as a SpecSyncedCondition=False after the object was already
+// accepted.
+var allowedInstallerArgs = map[string]bool{
+	"--append-karg":       true,
+	"--delete-karg":       true,
+	"--save-partlabel":    true,
+	"--save-partindex":    true,
+	"--insecure":          true,
+	"--insecure-ignition": true,
+	"--image-url":         true,
+	"--copy-network":      true,
+	"--network-dir":       true,
+	"-n":                  true,
+	"--dry-run":           true,
+}
+
+// valueTakingInstallerArgs are the allowedInstallerArgs flags that consume
+// the next array element as their value rather than standing alone, so
+// groupInstallerArgPairs keeps a flag and its value together when
+// CanonicalizeInstallerArgs reorders pairs. Getting this list wrong doesn't
+// just mis-sort output: it splits a flag from its own value, letting an
+// unrelated neighboring flag land between them and turning a valid
+// coreos-installer invocation into garbage.
+var valueTakingInstallerArgs = map[string]bool{
+	"--append-karg":    true,
+	"--delete-karg":    true,
+	"--save-partlabel": true,
+	"--save-partindex": true,
+	"--image-url":      true,
+	"--network-dir":    true,
+}
+
+// pathValueInstallerArgs are the valueTakingInstallerArgs flags whose value
+// is specifically a filesystem path, so that path can be checked against
+// allowedInstallerArgPathPrefixes instead of accepted as-is
+// (coreos-installer's --network-dir, for example, would otherwise let
+// --copy-network pull nmconnection files from anywhere readable on the
+// host).
+var pathValueInstallerArgs = map[string]bool{
+	"--network-dir": true,
+}
+
+// allowedInstallerArgPathPrefixes restricts pathValueInstallerArgs values to
+// locations coreos-installer is actually expected to read from.
+var allowedInstallerArgPathPrefixes = []string{
+	"/etc/assisted/network",
+	"/mnt/config/network",
+}
+
+// ValidateInstallerArgs parses raw as a JSON array of coreos-installer
+// arguments, checks every flag against allowedInstallerArgs, and checks the
+// value following any pathValueInstallerArgs flag against
+// allowedInstallerArgPathPrefixes.
+func ValidateInstallerArgs(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	args, err := parseInstallerArgs(raw)
+	if err != nil {
+		return err
+	}
+
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !allowedInstallerArgs[arg] {
+			return fmt.Errorf("installer arg %q is not in the allow-list", arg)
+		}
+		if !pathValueInstallerArgs[arg] {
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("installer arg %q requires a path argument", arg)
+		}
+		if !hasAllowedPathPrefix(args[i+1]) {
+			return fmt.Errorf("installer arg %q value %q is outside the allowed path prefixes %v", arg, args[i+1], allowedInstallerArgPathPrefixes)
+		}
+	}
+	return nil
+}
+
+// CanonicalizeInstallerArgs re-marshals raw with its arguments sorted, so
+// that two InstallerArgs values differing only in user-supplied ordering
+// compare equal. It assumes raw has already passed ValidateInstallerArgs.
+func CanonicalizeInstallerArgs(raw string) (string, error) {
+	if raw == "" {
+		return raw, nil
+	}
+
+	args, err := parseInstallerArgs(raw)
+	if err != nil {
+		return "", err
+	}
+
+	pairs := groupInstallerArgPairs(args)
+	sort.Slice(pairs, func(i, j int) bool {
+		return strings.Join(pairs[i], " ") < strings.Join(pairs[j], " ")
+	})
+
+	canonical := make([]string, 0, len(args))
+	for _, pair := range pairs {
+		canonical = append(canonical, pair...)
+	}
+
+	out, err := json.Marshal(canonical)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func parseInstallerArgs(raw string) ([]string, error) {
+	var args []string
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return nil, fmt.Errorf("installerArgs is not a valid JSON string array: %w", err)
+	}
+	return args, nil
+}
+
+// groupInstallerArgPairs groups args into [flag] or [flag, value] slices, so
+// a value-taking flag's value travels with it when the pairs are reordered.
+func groupInstallerArgPairs(args []string) [][]string {
+	var pairs [][]string
+	for i := 0; i < len(args); i++ {
+		if valueTakingInstallerArgs[args[i]] && i+1 < len(args) {
+			pairs = append(pairs, []string{args[i], args[i+1]})
+			i++
+			continue
+		}
+		pairs = append(pairs, []string{args[i]})
+	}
+	return pairs
+}
+
+func hasAllowedPathPrefix(path string) bool {
+	for _, prefix := range allowedInstallerArgPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
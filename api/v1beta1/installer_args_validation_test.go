@@ -0,0 +1,62 @@
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateInstallerArgs", func() {
+	It("accepts an empty value", func() {
+		Expect(ValidateInstallerArgs("")).To(Succeed())
+	})
+
+	It("accepts allow-listed flags", func() {
+		Expect(ValidateInstallerArgs(`["--append-karg", "ip=dhcp", "--save-partindex", "1", "-n"]`)).To(Succeed())
+	})
+
+	It("rejects malformed JSON", func() {
+		Expect(ValidateInstallerArgs(`"--append-karg"]`)).To(HaveOccurred())
+	})
+
+	It("rejects a flag outside the allow-list", func() {
+		Expect(ValidateInstallerArgs(`["--not-a-real-flag"]`)).To(HaveOccurred())
+	})
+
+	It("accepts --network-dir pointed at an allowed path prefix", func() {
+		Expect(ValidateInstallerArgs(`["--copy-network", "--network-dir", "/etc/assisted/network"]`)).To(Succeed())
+	})
+
+	It("rejects --network-dir pointed outside the allowed path prefixes", func() {
+		Expect(ValidateInstallerArgs(`["--copy-network", "--network-dir", "/root/.ssh"]`)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CanonicalizeInstallerArgs", func() {
+	It("leaves an empty value untouched", func() {
+		out, err := CanonicalizeInstallerArgs("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal(""))
+	})
+
+	It("produces the same result regardless of input ordering", func() {
+		a, err := CanonicalizeInstallerArgs(`["-n", "--append-karg", "ip=dhcp"]`)
+		Expect(err).ToNot(HaveOccurred())
+
+		b, err := CanonicalizeInstallerArgs(`["--append-karg", "ip=dhcp", "-n"]`)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(a).To(Equal(b))
+	})
+
+	It("keeps a path-taking flag's value adjacent to it after reordering", func() {
+		out, err := CanonicalizeInstallerArgs(`["-n", "--network-dir", "/etc/assisted/network", "--copy-network"]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(ContainSubstring(`"--network-dir","/etc/assisted/network"`))
+	})
+
+	It("keeps every value-taking flag adjacent to its value after reordering, not just path-taking ones", func() {
+		out, err := CanonicalizeInstallerArgs(`["-n", "--append-karg", "ip=dhcp"]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(ContainSubstring(`"--append-karg","ip=dhcp"`))
+	})
+})
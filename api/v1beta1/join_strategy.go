@@ -0,0 +1,25 @@
+package v1beta1
+
+// JoinStrategy selects how a host's kubelet authenticates to the spoke
+// cluster's API server when joining as a Node, set on
+// AgentClusterInstallSpec.JoinStrategy.
+type JoinStrategy string
+
+const (
+	// JoinStrategyCSRApproval is the default, existing behavior: the
+	// kubelet's bootstrapper service account submits a client CSR, and
+	// AgentReconciler approves both it and the subsequent node-serving CSR
+	// after validating the requester against agent inventory. This is a
+	// best-effort, post-hoc trust decision, since approval happens after the
+	// kubelet has already authenticated with a cluster-wide bootstrapper
+	// identity.
+	JoinStrategyCSRApproval JoinStrategy = "CSRApproval"
+
+	// JoinStrategyBootstrapToken has AgentReconciler provision a short-lived,
+	// single-use kubeadm-style bootstrap token on the spoke cluster ahead of
+	// time and hand it to the host through its discovery ignition; the
+	// kubelet authenticates with that token instead of the shared
+	// bootstrapper service account, so only the resulting node-serving CSR
+	// needs approving.
+	JoinStrategyBootstrapToken JoinStrategy = "BootstrapToken"
+)
@@ -0,0 +1,94 @@
+// Package adminactions implements the kube-facing admin actions exposed for
+// installed and day-2 clusters: a real-time info snapshot assembled
+// straight from the target cluster's own API, and ClusterVersion upgrade
+// cancellation. Reconciling the AMS subscription and regenerating manifests
+// don't belong here - both only replay work the cluster package's Manager
+// already does internally (CompleteInstallation's AMS call and
+// GenerateAdditionalManifests, respectively) rather than talking to the
+// target cluster - so those two are wired directly on Manager instead.
+package adminactions
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterVersionName is the one ClusterVersion object every OpenShift
+// cluster runs, named "version" by convention.
+const clusterVersionName = "version"
+
+// ClusterInfo is the real-time snapshot AdminGetClusterInfo assembles from
+// the target cluster's own API, rather than from anything assisted-service
+// persisted about it at install time.
+type ClusterInfo struct {
+	NodeNames        []string
+	ClusterVersion   string
+	OperatorStatuses []OperatorStatus
+}
+
+// OperatorStatus mirrors one ClusterOperator's reported Available/Degraded/
+// Progressing conditions.
+type OperatorStatus struct {
+	Name       string
+	Conditions map[configv1.ClusterStatusConditionType]configv1.ConditionStatus
+}
+
+// CollectClusterInfo lists Nodes, the ClusterVersion and every
+// ClusterOperator through cl, the same informer-backed client
+// clustercache.ClusterCache warms for day-2 polling.
+func CollectClusterInfo(ctx context.Context, cl client.Client) (*ClusterInfo, error) {
+	var nodes corev1.NodeList
+	if err := cl.List(ctx, &nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, n := range nodes.Items {
+		nodeNames = append(nodeNames, n.Name)
+	}
+
+	var cv configv1.ClusterVersion
+	if err := cl.Get(ctx, client.ObjectKey{Name: clusterVersionName}, &cv); err != nil {
+		return nil, errors.Wrap(err, "failed to get ClusterVersion")
+	}
+	version := ""
+	if len(cv.Status.History) > 0 {
+		version = cv.Status.History[0].Version
+	}
+
+	var operators configv1.ClusterOperatorList
+	if err := cl.List(ctx, &operators); err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster operators")
+	}
+	statuses := make([]OperatorStatus, 0, len(operators.Items))
+	for _, op := range operators.Items {
+		conditions := make(map[configv1.ClusterStatusConditionType]configv1.ConditionStatus, len(op.Status.Conditions))
+		for _, c := range op.Status.Conditions {
+			conditions[c.Type] = c.Status
+		}
+		statuses = append(statuses, OperatorStatus{Name: op.Name, Conditions: conditions})
+	}
+
+	return &ClusterInfo{NodeNames: nodeNames, ClusterVersion: version, OperatorStatuses: statuses}, nil
+}
+
+// CancelUpgrade clears spec.desiredUpdate on the target cluster's
+// ClusterVersion - the same field `oc adm upgrade --clear` plumbs - so an
+// upgrade it's mid-rollout of stops advancing.
+func CancelUpgrade(ctx context.Context, cl client.Client) error {
+	var cv configv1.ClusterVersion
+	if err := cl.Get(ctx, client.ObjectKey{Name: clusterVersionName}, &cv); err != nil {
+		return errors.Wrap(err, "failed to get ClusterVersion")
+	}
+	if cv.Spec.DesiredUpdate == nil {
+		return errors.New("no upgrade is in progress")
+	}
+	cv.Spec.DesiredUpdate = nil
+	if err := cl.Update(ctx, &cv); err != nil {
+		return errors.Wrap(err, "failed to clear desired update")
+	}
+	return nil
+}
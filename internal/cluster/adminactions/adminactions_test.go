@@ -0,0 +1,70 @@
+package adminactions
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeClient(objs ...client.Object) client.Client {
+	sch := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+	Expect(configv1.AddToScheme(sch)).To(Succeed())
+	return fakeclient.NewClientBuilder().WithScheme(sch).WithObjects(objs...).Build()
+}
+
+func clusterVersion(desiredUpdate *configv1.Update) *configv1.ClusterVersion {
+	return &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterVersionName},
+		Spec:       configv1.ClusterVersionSpec{DesiredUpdate: desiredUpdate},
+		Status: configv1.ClusterVersionStatus{
+			History: []configv1.UpdateHistory{{Version: "4.15.2"}},
+		},
+	}
+}
+
+var _ = Describe("CollectClusterInfo", func() {
+	It("assembles nodes, ClusterVersion and operator statuses", func() {
+		op := &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "authentication"},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+					{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse},
+				},
+			},
+		}
+		cl := newFakeClient(clusterVersion(nil), op)
+
+		info, err := CollectClusterInfo(context.Background(), cl)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ClusterVersion).To(Equal("4.15.2"))
+		Expect(info.OperatorStatuses).To(HaveLen(1))
+		Expect(info.OperatorStatuses[0].Name).To(Equal("authentication"))
+		Expect(info.OperatorStatuses[0].Conditions[configv1.OperatorAvailable]).To(Equal(configv1.ConditionTrue))
+	})
+})
+
+var _ = Describe("CancelUpgrade", func() {
+	It("clears an in-progress desired update", func() {
+		cl := newFakeClient(clusterVersion(&configv1.Update{Version: "4.15.3"}))
+
+		Expect(CancelUpgrade(context.Background(), cl)).To(Succeed())
+
+		var cv configv1.ClusterVersion
+		Expect(cl.Get(context.Background(), client.ObjectKey{Name: clusterVersionName}, &cv)).To(Succeed())
+		Expect(cv.Spec.DesiredUpdate).To(BeNil())
+	})
+
+	It("errors when no upgrade is in progress", func() {
+		cl := newFakeClient(clusterVersion(nil))
+		Expect(CancelUpgrade(context.Background(), cl)).To(HaveOccurred())
+	})
+})
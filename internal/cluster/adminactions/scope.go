@@ -0,0 +1,21 @@
+package adminactions
+
+import "context"
+
+// Scope names an RBAC-style permission the admin actions in this package
+// require, distinct from the per-cluster-owner scopes the rest of the API
+// checks, since these actions operate on clusters the caller need not own.
+type Scope string
+
+// ClusterActionsScope gates every admin action this package (and its
+// Manager-level AMS/manifest counterparts) exposes: fetching a live
+// cluster snapshot, cancelling an upgrade, reconciling the AMS
+// subscription, and regenerating manifests.
+const ClusterActionsScope Scope = "admin:cluster-actions"
+
+// Authorizer checks whether the caller in ctx holds scope, keeping the
+// admin action entry points agnostic of whichever RBAC/token backend
+// actually authorizes the caller.
+type Authorizer interface {
+	Authorize(ctx context.Context, scope Scope) error
+}
@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/openshift/assisted-service/internal/cluster/adminactions"
+	"github.com/openshift/assisted-service/internal/common"
+	eventgen "github.com/openshift/assisted-service/internal/common/events"
+	logutil "github.com/openshift/assisted-service/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// AdminGetClusterInfo returns a real-time snapshot of clusterID's nodes,
+// ClusterVersion and ClusterOperators, assembled straight from its own API
+// through the same kubeconfig the maintenance Actuator resolves a client
+// from.
+func (m *Manager) AdminGetClusterInfo(ctx context.Context, authorizer adminactions.Authorizer, clusterID strfmt.UUID) (*adminactions.ClusterInfo, error) {
+	log := logutil.FromContext(ctx, m.log)
+	if err := m.authorizeAdminAction(ctx, authorizer, clusterID, "cluster-info"); err != nil {
+		return nil, err
+	}
+
+	cl, err := managerClusterClientResolver{m: m}.ResolveClient(ctx, clusterID.String())
+	if err != nil {
+		return nil, err
+	}
+	info, err := adminactions.CollectClusterInfo(ctx, cl)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to collect admin cluster info for cluster %s", clusterID)
+		return nil, err
+	}
+	return info, nil
+}
+
+// AdminCancelUpgrade clears clusterID's in-progress ClusterVersion upgrade.
+func (m *Manager) AdminCancelUpgrade(ctx context.Context, authorizer adminactions.Authorizer, clusterID strfmt.UUID) error {
+	if err := m.authorizeAdminAction(ctx, authorizer, clusterID, "cancel-upgrade"); err != nil {
+		return err
+	}
+	cl, err := managerClusterClientResolver{m: m}.ResolveClient(ctx, clusterID.String())
+	if err != nil {
+		return err
+	}
+	return adminactions.CancelUpgrade(ctx, cl)
+}
+
+// AdminReconcileAMS re-runs the AMS UpdateSubscriptionStatusActive call
+// CompleteInstallation performs, for an operator who wants to retry it
+// without re-running the rest of installation completion.
+func (m *Manager) AdminReconcileAMS(ctx context.Context, authorizer adminactions.Authorizer, cluster *common.Cluster) error {
+	if err := m.authorizeAdminAction(ctx, authorizer, *cluster.ID, "reconcile-ams"); err != nil {
+		return err
+	}
+	if m.ocmClient == nil {
+		return errors.New("no OCM client configured")
+	}
+	return m.ocmClient.AccountsMgmt.UpdateSubscriptionStatusActive(ctx, cluster.AmsSubscriptionID)
+}
+
+// AdminRegenerateManifests re-invokes GenerateAdditionalManifests for
+// cluster, so an operator can recover from a one-off manifest-generation
+// failure without re-running the rest of installation.
+func (m *Manager) AdminRegenerateManifests(ctx context.Context, authorizer adminactions.Authorizer, cluster *common.Cluster) error {
+	if err := m.authorizeAdminAction(ctx, authorizer, *cluster.ID, "regenerate-manifests"); err != nil {
+		return err
+	}
+	return m.GenerateAdditionalManifests(ctx, cluster)
+}
+
+// authorizeAdminAction checks authorizer for adminactions.ClusterActionsScope
+// and audit-logs the outcome as a cluster event either way, so every admin
+// action leaves exactly one event behind regardless of whether it was
+// allowed.
+func (m *Manager) authorizeAdminAction(ctx context.Context, authorizer adminactions.Authorizer, clusterID strfmt.UUID, action string) error {
+	log := logutil.FromContext(ctx, m.log)
+	if err := authorizer.Authorize(ctx, adminactions.ClusterActionsScope); err != nil {
+		log.WithError(err).Warnf("Admin action %s denied for cluster %s", action, clusterID)
+		eventgen.SendClusterAdminActionDeniedEvent(ctx, m.eventsHandler, clusterID, action)
+		return errors.Wrapf(err, "admin action %s not authorized for cluster %s", action, clusterID)
+	}
+	eventgen.SendClusterAdminActionPerformedEvent(ctx, m.eventsHandler, clusterID, action)
+	return nil
+}
@@ -15,16 +15,20 @@ import (
 	"github.com/filanov/stateswitch"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
-	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
-	"github.com/kennygrant/sanitize"
+	"github.com/openshift/assisted-service/internal/cluster/clustercache"
+	"github.com/openshift/assisted-service/internal/cluster/controlplane"
+	"github.com/openshift/assisted-service/internal/cluster/healthcheck"
+	"github.com/openshift/assisted-service/internal/cluster/adminactions"
+	"github.com/openshift/assisted-service/internal/cluster/maintenance"
+	"github.com/openshift/assisted-service/internal/cluster/operatorhealth"
+	"github.com/openshift/assisted-service/internal/cluster/monitor"
 	"github.com/openshift/assisted-service/internal/common"
 	eventgen "github.com/openshift/assisted-service/internal/common/events"
 	"github.com/openshift/assisted-service/internal/constants"
 	"github.com/openshift/assisted-service/internal/dns"
 	eventsapi "github.com/openshift/assisted-service/internal/events/api"
 	"github.com/openshift/assisted-service/internal/host"
-	"github.com/openshift/assisted-service/internal/host/hostutil"
 	"github.com/openshift/assisted-service/internal/metrics"
 	"github.com/openshift/assisted-service/internal/network"
 	"github.com/openshift/assisted-service/internal/operators"
@@ -40,6 +44,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thoas/go-funk"
 	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -108,6 +113,11 @@ type API interface {
 	CreateTarredClusterLogs(ctx context.Context, c *common.Cluster, objectHandler s3wrapper.API) (string, error)
 	SetUploadControllerLogsAt(ctx context.Context, c *common.Cluster, db *gorm.DB) error
 	SetConnectivityMajorityGroupsForCluster(clusterID strfmt.UUID, db *gorm.DB) error
+	UpdateConnectivityMajorityGroupsForHost(clusterID, hostID strfmt.UUID, db *gorm.DB) error
+	EnqueueMaintenanceManifest(clusterID strfmt.UUID, taskID string, priority int) (string, error)
+	ListMaintenanceManifests(clusterID strfmt.UUID) ([]maintenance.Manifest, error)
+	CancelMaintenanceManifest(manifestID string) error
+	StartMaintenanceActuator(ctx context.Context)
 	DeleteClusterLogs(ctx context.Context, c *common.Cluster, objectHandler s3wrapper.API) error
 	DeleteClusterFiles(ctx context.Context, c *common.Cluster, objectHandler s3wrapper.API) error
 	UpdateLogsProgress(ctx context.Context, c *common.Cluster, progress string) error
@@ -118,6 +128,14 @@ type API interface {
 	PermanentClustersDeletion(ctx context.Context, olderThan strfmt.DateTime, objectHandler s3wrapper.API) error
 	DeregisterInactiveCluster(ctx context.Context, maxDeregisterPerInterval int, inactiveSince strfmt.DateTime) error
 	TransformClusterToDay2(ctx context.Context, cluster *common.Cluster, db *gorm.DB) error
+	RefreshClusterHealth(ctx context.Context, c *common.Cluster) error
+	CanDownloadKubeconfig(c *common.Cluster) error
+	RemoveControlPlaneHost(ctx context.Context, c *common.Cluster, hostID strfmt.UUID, db *gorm.DB) error
+	AdminGetClusterInfo(ctx context.Context, authorizer adminactions.Authorizer, clusterID strfmt.UUID) (*adminactions.ClusterInfo, error)
+	AdminCancelUpgrade(ctx context.Context, authorizer adminactions.Authorizer, clusterID strfmt.UUID) error
+	AdminReconcileAMS(ctx context.Context, authorizer adminactions.Authorizer, cluster *common.Cluster) error
+	AdminRegenerateManifests(ctx context.Context, authorizer adminactions.Authorizer, cluster *common.Cluster) error
+	StartOperatorHealthMonitor(ctx context.Context)
 }
 
 type LogTimeoutConfig struct {
@@ -130,31 +148,67 @@ type PrepareConfig struct {
 }
 
 type Config struct {
-	PrepareConfig       PrepareConfig
-	InstallationTimeout time.Duration `envconfig:"INSTALLATION_TIMEOUT" default:"24h"`
-	FinalizingTimeout   time.Duration `envconfig:"FINALIZING_TIMEOUT" default:"5h"`
-	MonitorBatchSize    int           `envconfig:"CLUSTER_MONITOR_BATCH_SIZE" default:"100"`
+	PrepareConfig              PrepareConfig
+	InstallationTimeout        time.Duration `envconfig:"INSTALLATION_TIMEOUT" default:"24h"`
+	FinalizingTimeout          time.Duration `envconfig:"FINALIZING_TIMEOUT" default:"5h"`
+	MonitorBatchSize           int           `envconfig:"CLUSTER_MONITOR_BATCH_SIZE" default:"100"`
+	HealthCheckConcurrency     int           `envconfig:"CLUSTER_HEALTH_CHECK_CONCURRENCY" default:"10"`
+	LogsArchiveConcurrency     int           `envconfig:"CLUSTER_LOGS_ARCHIVE_CONCURRENCY" default:"10"`
+	LogsArchiveGzip            bool          `envconfig:"CLUSTER_LOGS_ARCHIVE_GZIP" default:"false"`
+	MaintenancePollInterval    time.Duration `envconfig:"CLUSTER_MAINTENANCE_POLL_INTERVAL" default:"1m"`
+	OperatorHealthPollInterval time.Duration `envconfig:"CLUSTER_OPERATOR_HEALTH_POLL_INTERVAL" default:"5m"`
+	MonitorShardingEnabled     bool          `envconfig:"CLUSTER_MONITOR_SHARDING_ENABLED" default:"false"`
+	MonitorReplicaCount        int           `envconfig:"CLUSTER_MONITOR_REPLICA_COUNT" default:"1"`
+	MonitorReplicaIndex        int           `envconfig:"CLUSTER_MONITOR_REPLICA_INDEX" default:"0"`
+	Sanitize                   SanitizeSpec
 }
 
 type Manager struct {
 	Config
-	log                   logrus.FieldLogger
-	db                    *gorm.DB
-	registrationAPI       RegistrationAPI
-	installationAPI       InstallationAPI
-	eventsHandler         eventsapi.Handler
-	sm                    stateswitch.StateMachine
-	metricAPI             metrics.API
-	manifestsGeneratorAPI network.ManifestsGeneratorAPI
-	hostAPI               host.API
-	rp                    *refreshPreprocessor
-	leaderElector         leader.Leader
-	prevMonitorInvokedAt  time.Time
-	ocmClient             *ocm.Client
-	objectHandler         s3wrapper.API
-	dnsApi                dns.DNSApi
-	monitorQueryGenerator *common.MonitorClusterQueryGenerator
-	authHandler           auth.Authenticator
+	log                    logrus.FieldLogger
+	db                     *gorm.DB
+	registrationAPI        RegistrationAPI
+	installationAPI        InstallationAPI
+	eventsHandler          eventsapi.Handler
+	sm                     stateswitch.StateMachine
+	metricAPI              metrics.API
+	manifestsGeneratorAPI  network.ManifestsGeneratorAPI
+	hostAPI                host.API
+	rp                     *refreshPreprocessor
+	leaderElector          leader.Leader
+	prevMonitorInvokedAt   time.Time
+	ocmClient              *ocm.Client
+	objectHandler          s3wrapper.API
+	dnsApi                 dns.DNSApi
+	monitorQueryGenerators map[string]*common.MonitorClusterQueryGenerator
+	tierLastRun            map[string]time.Time
+	scheduler              monitor.MonitorScheduler
+	authHandler            auth.Authenticator
+	healthProber           *healthcheck.Prober
+	clusterCache           *clustercache.ClusterCache
+	sanitizer              Sanitizer
+	etcdRemover            controlplane.MemberRemover
+	drainer                controlplane.Drainer
+	majorityGroupsCache    *majorityGroupsCache
+	maintenanceRegistry    *maintenance.Registry
+	maintenanceActuator    *maintenance.Actuator
+	operatorHealthMonitor  *operatorhealth.Monitor
+}
+
+// newMonitorScheduler returns a SingleShardScheduler unless sharding is
+// enabled, in which case it returns a ShardedScheduler fixed to this
+// process's CLUSTER_MONITOR_REPLICA_COUNT/CLUSTER_MONITOR_REPLICA_INDEX -
+// set by the deployment from a StatefulSet pod ordinal, the same way other
+// sharded controllers in the ecosystem derive a stable replica identity
+// without needing to inspect leader-election lease membership directly.
+func newMonitorScheduler(cfg Config, leaderElector leader.Leader) monitor.MonitorScheduler {
+	if !cfg.MonitorShardingEnabled {
+		return monitor.NewSingleShardScheduler(leaderElector)
+	}
+	return monitor.NewShardedScheduler(leaderElector,
+		func() int { return cfg.MonitorReplicaCount },
+		func() int { return cfg.MonitorReplicaIndex },
+	)
 }
 
 func NewManager(cfg Config, log logrus.FieldLogger, db *gorm.DB, eventsHandler eventsapi.Handler,
@@ -169,7 +223,12 @@ func NewManager(cfg Config, log logrus.FieldLogger, db *gorm.DB, eventsHandler e
 		finalizingTimeout:   cfg.FinalizingTimeout,
 		eventsHandler:       eventsHandler,
 	}
-	return &Manager{
+	clusterCache := clustercache.NewClusterCache()
+	scheduler := newMonitorScheduler(cfg, leaderElector)
+	maintenanceRegistry := maintenance.NewRegistry()
+	maintenanceRegistry.Register(maintenance.NewVerifyAPIReachableTask())
+	maintenanceRegistry.Register(maintenance.NewRefreshPullSecretTask())
+	m := &Manager{
 		Config:                cfg,
 		log:                   log,
 		db:                    db,
@@ -187,7 +246,19 @@ func NewManager(cfg Config, log logrus.FieldLogger, db *gorm.DB, eventsHandler e
 		objectHandler:         objectHandler,
 		dnsApi:                dnsApi,
 		authHandler:           authHandler,
+		healthProber:          healthcheck.NewProber(cfg.HealthCheckConcurrency),
+		clusterCache:          clusterCache,
+		tierLastRun:           make(map[string]time.Time, len(monitor.Tiers)),
+		scheduler:             scheduler,
+		sanitizer:             newSanitizer(objectHandler, clusterCache, eventsHandler, cfg.Sanitize),
+		etcdRemover:           controlplane.NewEtcdMemberRemover(),
+		drainer:               controlplane.NewNodeDrainer(),
+		majorityGroupsCache:   newMajorityGroupsCache(),
+		maintenanceRegistry:   maintenanceRegistry,
 	}
+	m.maintenanceActuator = maintenance.NewActuator(db, log, maintenanceRegistry, m.scheduler, managerClusterClientResolver{m: m}, cfg.MaintenancePollInterval)
+	m.operatorHealthMonitor = operatorhealth.NewMonitor(managerClusterClientResolver{m: m}, operatorHealthMetricsAdapter{m: m}, operatorHealthConditionRecorder{m: m}, log, cfg.OperatorHealthPollInterval)
+	return m
 }
 
 func (m *Manager) RegisterCluster(ctx context.Context, c *common.Cluster) error {
@@ -225,6 +296,12 @@ func (m *Manager) DeregisterCluster(ctx context.Context, c *common.Cluster) erro
 		return metricsErr
 	}
 
+	// Best-effort secret scrub before the cluster's S3 objects and DB rows
+	// are removed; a failure here must not block deregistration.
+	if err := m.sanitizer.Sanitize(ctx, c); err != nil {
+		m.log.WithError(err).Warnf("Failed to sanitize cluster %s", c.ID)
+	}
+
 	// Delete discovery image for deregistered cluster
 	discoveryImage := fmt.Sprintf("%s.iso", fmt.Sprintf(s3wrapper.DiscoveryImageTemplate, c.ID.String()))
 	exists, err := m.objectHandler.DoesObjectExist(ctx, discoveryImage)
@@ -244,6 +321,7 @@ func (m *Manager) DeregisterCluster(ctx context.Context, c *common.Cluster) erro
 	if err != nil {
 		eventgen.SendClusterDeregisterFailedEvent(ctx, m.eventsHandler, *c.ID, err.Error())
 	} else {
+		m.clusterCache.Evict(c.ID.String())
 		eventgen.SendClusterDeregisteredEvent(ctx, m.eventsHandler, *c.ID)
 	}
 	return err
@@ -275,18 +353,31 @@ func (m *Manager) reportValidationStatusChanged(ctx context.Context, c *common.C
 	for vCategory, vRes := range newValidationRes {
 		for _, v := range vRes {
 			if currentStatus, ok := m.getValidationStatus(currentValidationRes, vCategory, v.ID); ok {
+				subject := conditionEventSubject(vCategory, v.ID)
 				if v.Status == ValidationFailure && currentStatus == ValidationSuccess {
 					m.metricAPI.ClusterValidationChanged(c.OpenshiftVersion, c.EmailDomain, models.ClusterValidationID(v.ID))
-					eventgen.SendClusterValidationFailedEvent(ctx, m.eventsHandler, *c.ID, v.ID.String(), v.Message)
+					eventgen.SendClusterValidationFailedEvent(ctx, m.eventsHandler, *c.ID, subject, v.Message)
 				}
 				if v.Status == ValidationSuccess && currentStatus == ValidationFailure {
-					eventgen.SendClusterValidationFixedEvent(ctx, m.eventsHandler, *c.ID, v.ID.String(), v.Message)
+					eventgen.SendClusterValidationFixedEvent(ctx, m.eventsHandler, *c.ID, subject, v.Message)
 				}
 			}
 		}
 	}
 }
 
+// conditionEventSubject returns the well-known ClusterCondition Type that
+// summarizes id within category, so a validation-status event references
+// the same stable name a kube-based controller already watches on
+// c.Conditions; it falls back to the raw validation ID when no condition
+// claims it.
+func conditionEventSubject(category string, id ValidationID) string {
+	if condType := conditionTypeForValidation(category, id); condType != "" {
+		return condType
+	}
+	return id.String()
+}
+
 func (m *Manager) getValidationStatus(vs ValidationsStatus, category string, vID ValidationID) (ValidationStatus, bool) {
 	for _, v := range vs[category] {
 		if v.ID == vID {
@@ -387,7 +478,17 @@ func (m *Manager) refreshStatusInternal(ctx context.Context, c *common.Cluster,
 	if ret == nil {
 		ret = c
 	}
-	return ret, err
+
+	// Keep the kube-style Conditions slice in lock-step with the Status and
+	// ValidationsInfo this transition just settled on, so a kube-based
+	// consumer watching Conditions never observes a state the flat fields
+	// haven't already committed to the DB.
+	m.updateClusterConditions(ret, newValidationRes)
+	if ret, err = m.updateConditionsInDB(ctx, db, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
 }
 
 func (m *Manager) SetUploadControllerLogsAt(ctx context.Context, c *common.Cluster, db *gorm.DB) error {
@@ -511,38 +612,38 @@ func (m *Manager) SkipMonitoring(c *common.Cluster) bool {
 }
 
 func (m *Manager) initMonitorQueryGenerator() {
-	if m.monitorQueryGenerator == nil {
+	if m.monitorQueryGenerators != nil {
+		return
+	}
+	m.monitorQueryGenerators = make(map[string]*common.MonitorClusterQueryGenerator, len(monitor.Tiers))
+	for _, tier := range monitor.Tiers {
+		statuses := tier.Statuses
 		buildInitialQuery := func(db *gorm.DB) *gorm.DB {
-			noNeedToMonitorInStates := []string{
-				models.ClusterStatusInstalled,
-			}
-
 			dbWithCondition := common.LoadTableFromDB(db, common.HostsTable)
 			dbWithCondition = common.LoadClusterTablesFromDB(dbWithCondition, common.HostsTable)
-			dbWithCondition = dbWithCondition.Where("status NOT IN (?)", noNeedToMonitorInStates)
+			dbWithCondition = dbWithCondition.Where("status IN (?)", statuses)
 			return dbWithCondition
 		}
-		m.monitorQueryGenerator = common.NewMonitorQueryGenerator(m.db, buildInitialQuery, m.MonitorBatchSize)
+		m.monitorQueryGenerators[tier.Name] = common.NewMonitorQueryGenerator(m.db, buildInitialQuery, m.MonitorBatchSize)
 	}
 }
 
+// ClusterMonitoring refreshes each priority tier in monitor.Tiers at its own
+// cadence - fast for installing/finalizing clusters, medium for clusters
+// still gathering validations, a slow health-only tick for installed ones -
+// instead of one leader iterating a single fixed-size batch. m.scheduler
+// decides both when a tier is due and which clusters this replica owns, so
+// the same loop serves the single-leader deployment today and a
+// consistent-hash-sharded one without changing this method.
 func (m *Manager) ClusterMonitoring() {
-	if !m.leaderElector.IsLeader() {
-		m.log.Debugf("Not a leader, exiting ClusterMonitoring")
-		return
-	}
 	m.log.Debugf("Running ClusterMonitoring")
 	defer commonutils.MeasureOperation("ClusterMonitoring", m.log, m.metricAPI)()
+
 	var (
-		offset              int
-		limit               = m.MonitorBatchSize
-		monitored           int64
-		clusters            []*common.Cluster
-		clusterAfterRefresh *common.Cluster
-		requestID           = requestid.NewID()
-		ctx                 = requestid.ToContext(context.Background(), requestID)
-		log                 = requestid.RequestIDLogger(m.log, requestID)
-		err                 error
+		monitored int64
+		requestID = requestid.NewID()
+		ctx       = requestid.ToContext(context.Background(), requestID)
+		log       = requestid.RequestIDLogger(m.log, requestID)
 	)
 
 	curMonitorInvokedAt := time.Now()
@@ -550,38 +651,47 @@ func (m *Manager) ClusterMonitoring() {
 		m.prevMonitorInvokedAt = curMonitorInvokedAt
 	}()
 
-	//no need to refresh cluster status if the cluster is in the following statuses
-	//when cluster is in error. it should be still monitored until all the logs are collected.
-	//Then, SkipMonitoring() stops the logic from running forever
 	m.initMonitorQueryGenerator()
 
-	query := m.monitorQueryGenerator.NewClusterQuery()
-	for {
-		clusters, err = query.Next()
-		if err != nil {
-			log.WithError(err).Errorf("failed to get clusters")
-			return
-		}
-		if len(clusters) == 0 {
-			break
+	for _, tier := range monitor.Tiers {
+		if !m.scheduler.ShouldRunTier(tier, m.tierLastRun[tier.Name]) {
+			continue
 		}
-		m.log.Debugf("We are going to monitor %d, query is: %+v", len(clusters), query)
-		for _, cluster := range clusters {
-			if !m.leaderElector.IsLeader() {
-				m.log.Debugf("Not a leader, exiting ClusterMonitoring")
-				return
+		m.tierLastRun[tier.Name] = curMonitorInvokedAt
+
+		query := m.monitorQueryGenerators[tier.Name].NewClusterQuery()
+		for {
+			clusters, err := query.Next()
+			if err != nil {
+				log.WithError(err).Errorf("failed to get clusters for tier %s", tier.Name)
+				break
+			}
+			if len(clusters) == 0 {
+				break
 			}
-			if !m.SkipMonitoring(cluster) {
+			m.metricAPI.MonitorTierQueueDepth(tier.Name, len(clusters))
+			m.log.Debugf("We are going to monitor %d clusters in tier %s", len(clusters), tier.Name)
+
+			for _, cluster := range clusters {
+				if !m.scheduler.OwnsCluster(cluster.ID.String()) {
+					continue
+				}
+				if m.SkipMonitoring(cluster) {
+					continue
+				}
 				monitored += 1
+				clusterRefreshStartedAt := time.Now()
+
 				_ = m.autoAssignMachineNetworkCidr(cluster)
 				if err = m.setConnectivityMajorityGroupsForClusterInternal(cluster, m.db); err != nil {
 					log.WithError(err).Error("failed to set majority group for clusters")
 				}
-				clusterAfterRefresh, err = m.refreshStatusInternal(ctx, cluster, m.db)
+				clusterAfterRefresh, err := m.refreshStatusInternal(ctx, cluster, m.db)
 				if err != nil {
 					log.WithError(err).Errorf("failed to refresh cluster %s state", cluster.ID)
 					continue
 				}
+				m.metricAPI.ClusterRefreshLatency(tier.Name, cluster.ID.String(), time.Since(clusterRefreshStartedAt))
 
 				if swag.StringValue(clusterAfterRefresh.Status) != swag.StringValue(cluster.Status) {
 					log.Infof("cluster %s updated status from %s to %s via monitor", cluster.ID,
@@ -591,10 +701,16 @@ func (m *Manager) ClusterMonitoring() {
 				if m.shouldTriggerLeaseTimeoutEvent(cluster, curMonitorInvokedAt) {
 					m.triggerLeaseTimeoutEvent(ctx, cluster)
 				}
+
+				if tier.Name == monitor.TierSlow {
+					if err = m.RefreshClusterHealth(ctx, clusterAfterRefresh); err != nil {
+						log.WithError(err).Errorf("failed to refresh health for cluster %s", cluster.ID)
+					}
+				}
 			}
 		}
-		offset += limit
 	}
+
 	m.log.Debugf("Monitored %d clusters", monitored)
 	m.metricAPI.MonitoredClusterCount(monitored)
 }
@@ -657,8 +773,27 @@ func (m *Manager) UploadIngressCert(c *common.Cluster) (err error) {
 	allowedStatuses := []string{models.ClusterStatusFinalizing, models.ClusterStatusInstalled}
 	if !funk.ContainsString(allowedStatuses, clusterStatus) {
 		err = errors.Errorf("Cluster %s is in %s state, upload ingress ca can be done only in %s or %s state", c.ID, clusterStatus, models.ClusterStatusFinalizing, models.ClusterStatusInstalled)
+		SetCondition(c, common.ClusterCondition{Type: IngressCertUploadedCondition, Status: corev1.ConditionFalse, Reason: "InvalidClusterState", Message: err.Error()})
+		return err
 	}
-	return err
+	SetCondition(c, common.ClusterCondition{Type: IngressCertUploadedCondition, Status: corev1.ConditionTrue, Reason: "IngressCertUploaded", Message: "ingress CA was uploaded"})
+	return nil
+}
+
+// CanDownloadKubeconfig reports whether c's kubeconfig is available for
+// download: installation must have reached a status that produces one, and
+// - once RefreshClusterHealth has run at least once - the InstallationHealthy
+// condition must not be reporting False.
+func (m *Manager) CanDownloadKubeconfig(c *common.Cluster) error {
+	clusterStatus := swag.StringValue(c.Status)
+	allowedStatuses := []string{models.ClusterStatusFinalizing, models.ClusterStatusInstalled, models.ClusterStatusAddingHosts}
+	if !funk.ContainsString(allowedStatuses, clusterStatus) {
+		return errors.Errorf("Cluster %s is in %s state, kubeconfig can be downloaded only in one of %s", c.ID, clusterStatus, allowedStatuses)
+	}
+	if cond := findCondition(c, InstallationHealthyCondition); cond != nil && cond.Status == corev1.ConditionFalse {
+		return errors.Errorf("Cluster %s kubeconfig is not available: %s", c.ID, cond.Message)
+	}
+	return nil
 }
 
 func (m *Manager) AcceptRegistration(c *common.Cluster) (err error) {
@@ -863,18 +998,94 @@ func (m *Manager) ResetCluster(ctx context.Context, c *common.Cluster, reason st
 	return nil
 }
 
+// PrepareForInstallation runs preparationPipeline one stage at a time,
+// resuming from c.Progress.PrevStage+1 rather than restarting from scratch -
+// so a pod restart (or any other transient failure) doesn't redo the
+// expensive stages, like manifest rendering, that already completed.
 func (m *Manager) PrepareForInstallation(ctx context.Context, c *common.Cluster, db *gorm.DB) error {
-	err := m.sm.Run(TransitionTypePrepareForInstallation, newStateCluster(c),
-		&TransitionArgsPrepareForInstallation{
-			ctx:                ctx,
-			db:                 db,
-			manifestsGenerator: m.manifestsGeneratorAPI,
-			metricApi:          m.metricAPI,
-		},
-	)
-	return err
+	log := logutil.FromContext(ctx, m.log)
+	if db == nil {
+		db = m.db
+	}
+
+	startIndex := 0
+	if c.Progress != nil && c.Progress.PrevStage != "" {
+		if idx := preparationStageIndex(PreparationStage(c.Progress.PrevStage)); idx >= 0 {
+			startIndex = idx + 1
+		}
+	}
+
+	for _, stage := range preparationPipeline[startIndex:] {
+		started := time.Now()
+		eventgen.SendPreparationStageStartedEvent(ctx, m.eventsHandler, *c.ID, string(stage))
+
+		if err := m.runPreparationStage(ctx, c, db, stage); err != nil {
+			eventgen.SendPreparationStageFailedEvent(ctx, m.eventsHandler, *c.ID, string(stage), err.Error())
+			return errors.Wrapf(err, "preparation stage %s failed", stage)
+		}
+
+		duration := time.Since(started)
+		m.metricAPI.PreparationStageDuration(string(stage), duration)
+		eventgen.SendPreparationStageCompletedEvent(ctx, m.eventsHandler, *c.ID, string(stage), duration.Seconds())
+
+		if err := m.advancePreparationStage(db, c, stage); err != nil {
+			log.WithError(err).Errorf("Failed to persist preparation stage %s for cluster %s", stage, c.ID.String())
+			return err
+		}
+	}
+
+	return m.advancePreparationStage(db, c, StageDone)
+}
+
+// runPreparationStage dispatches a single preparationPipeline stage.
+// StageGeneratingManifests and the legacy state-machine-driven
+// StageUploadingIgnitions do real work; the other two stages have nothing
+// left to check beyond what those two already enforce in this build.
+func (m *Manager) runPreparationStage(ctx context.Context, c *common.Cluster, db *gorm.DB, stage PreparationStage) error {
+	switch stage {
+	case StageGeneratingManifests:
+		return m.GenerateAdditionalManifests(ctx, c)
+	case StageUploadingIgnitions:
+		return m.sm.Run(TransitionTypePrepareForInstallation, newStateCluster(c),
+			&TransitionArgsPrepareForInstallation{
+				ctx:                ctx,
+				db:                 db,
+				manifestsGenerator: m.manifestsGeneratorAPI,
+				metricApi:          m.metricAPI,
+			},
+		)
+	case StageValidatingImages, StageAwaitingSchemaAgreement:
+		return nil
+	default:
+		return errors.Errorf("unknown preparation stage %s", stage)
+	}
+}
+
+// advancePreparationStage persists stage as both the cluster's
+// PreparationStage and its Progress.PrevStage (so the next
+// PrepareForInstallation call resumes after it), and recomputes
+// PreparingForInstallationStagePercentage from the pipeline position -
+// UpdateInstallProgress's existing weighting reads that same field, so this
+// is the only place the fine-grained preparation percentage needs wiring.
+func (m *Manager) advancePreparationStage(db *gorm.DB, c *common.Cluster, stage PreparationStage) error {
+	percentage := preparationStagePercentage(stage)
+	c.PreparationStage = string(stage)
+	if c.Progress != nil {
+		c.Progress.PrevStage = string(stage)
+		c.Progress.PreparingForInstallationStagePercentage = percentage
+	}
+	updates := map[string]interface{}{
+		"preparation_stage":    string(stage),
+		"progress_prev_stage":  string(stage),
+		"progress_preparing_for_installation_stage_percentage": percentage,
+	}
+	return db.Model(&common.Cluster{}).Where("id = ?", c.ID.String()).UpdateColumns(updates).Error
 }
 
+// HandlePreInstallError records the failure both on the legacy
+// InstallationPreparationCompletionStatus column and - so callers reading
+// the richer Conditions array see the same outcome - as a False
+// PreparationSucceeded condition.
 func (m *Manager) HandlePreInstallError(ctx context.Context, c *common.Cluster, installErr error) {
 	log := logutil.FromContext(ctx, m.log)
 	log.WithError(installErr).Warnf("Failed to prepare installation of cluster %s", c.ID.String())
@@ -883,12 +1094,23 @@ func (m *Manager) HandlePreInstallError(ctx context.Context, c *common.Cluster,
 	}).Error
 	if err != nil {
 		log.WithError(err).Errorf("Failed to handle pre installation error for cluster %s", c.ID.String())
-	} else {
-		log.Infof("Successfully handled pre-installation error, cluster %s", c.ID.String())
-		eventgen.SendPrepareInstallationFailedEvent(ctx, m.eventsHandler, *c.ID, installErr.Error())
+		return
+	}
+	log.Infof("Successfully handled pre-installation error, cluster %s", c.ID.String())
+	eventgen.SendPrepareInstallationFailedEvent(ctx, m.eventsHandler, *c.ID, installErr.Error())
+	message := installErr.Error()
+	if c.PreparationStage != "" {
+		message = fmt.Sprintf("stuck at stage %s: %s", c.PreparationStage, message)
+	}
+	SetCondition(c, common.ClusterCondition{Type: PreparationSucceededCondition, Status: corev1.ConditionFalse, Reason: "PreparationFailed", Message: message})
+	if _, condErr := m.updateConditionsInDB(ctx, m.db, c); condErr != nil {
+		log.WithError(condErr).Errorf("Failed to persist PreparationSucceeded condition for cluster %s", c.ID.String())
 	}
 }
 
+// HandlePreInstallSuccess mirrors HandlePreInstallError's compatibility path:
+// it keeps InstallationPreparationCompletionStatus populated while also
+// setting PreparationSucceeded to True.
 func (m *Manager) HandlePreInstallSuccess(ctx context.Context, c *common.Cluster) {
 	log := logutil.FromContext(ctx, m.log)
 	err := m.db.Model(&common.Cluster{}).Where("id = ?", c.ID.String()).Updates(&common.Cluster{
@@ -896,9 +1118,13 @@ func (m *Manager) HandlePreInstallSuccess(ctx context.Context, c *common.Cluster
 	}).Error
 	if err != nil {
 		log.WithError(err).Errorf("Failed to handle pre installation success for cluster %s", c.ID.String())
-	} else {
-		log.Infof("Successfully handled pre-installation success, cluster %s", c.ID.String())
-		eventgen.SendClusterPrepareInstallationStartedEvent(ctx, m.eventsHandler, *c.ID)
+		return
+	}
+	log.Infof("Successfully handled pre-installation success, cluster %s", c.ID.String())
+	eventgen.SendClusterPrepareInstallationStartedEvent(ctx, m.eventsHandler, *c.ID)
+	SetCondition(c, common.ClusterCondition{Type: PreparationSucceededCondition, Status: corev1.ConditionTrue, Reason: "PreparationSucceeded", Message: "installation preparation completed successfully"})
+	if _, condErr := m.updateConditionsInDB(ctx, m.db, c); condErr != nil {
+		log.WithError(condErr).Errorf("Failed to persist PreparationSucceeded condition for cluster %s", c.ID.String())
 	}
 }
 
@@ -952,74 +1178,68 @@ func (m *Manager) SetVipsData(ctx context.Context, c *common.Cluster, apiVip, in
 	return nil
 }
 
+// CreateTarredClusterLogs streams every object under the cluster's logs
+// prefix into a single tar (or, with LogsArchiveGzip, tar.gz) archive and
+// uploads that stream directly to destFileName: keys are walked a page at a
+// time via ListObjectsByPrefixPaginated and read/written by a
+// LogsArchiveConcurrency-sized worker pool, so neither the full key listing
+// nor the full archive content needs to fit in memory at once. ctx is
+// honored throughout, so a download handler can cancel the whole pipeline
+// when its client disconnects.
 func (m *Manager) CreateTarredClusterLogs(ctx context.Context, c *common.Cluster, objectHandler s3wrapper.API) (string, error) {
 	log := logutil.FromContext(ctx, m.log)
-	fileName := fmt.Sprintf("%s/logs/cluster_logs.tar", c.ID)
-	files, err := objectHandler.ListObjectsByPrefix(ctx, fmt.Sprintf("%s/logs/", c.ID))
+	destFileName := tarredLogsFileName(c, m.LogsArchiveGzip)
+
+	var keys []string
+	err := objectHandler.ListObjectsByPrefixPaginated(ctx, fmt.Sprintf("%s/logs/", c.ID), logArchiveListPageSize, func(page []string) error {
+		keys = append(keys, page...)
+		return nil
+	})
 	if err != nil {
 		return "", common.NewApiError(http.StatusNotFound, err)
 	}
-	files = funk.Filter(files, func(x string) bool {
-		return x != fileName
-	}).([]string)
 
-	var tarredFilenames []string
-	var tarredFilename string
-	for _, file := range files {
-		fileNameSplit := strings.Split(file, "/")
-		tarredFilename = file
-		if len(fileNameSplit) > 1 {
-			if _, err = uuid.Parse(fileNameSplit[len(fileNameSplit)-2]); err == nil {
-				hostId := fileNameSplit[len(fileNameSplit)-2]
-				for _, hostObject := range c.Hosts {
-					if hostObject.ID.String() != hostId {
-						continue
-					}
-					role := string(hostObject.Role)
-					if hostObject.Bootstrap {
-						role = string(models.HostRoleBootstrap)
-					}
-					tarredFilename = fmt.Sprintf("%s_%s_%s.tar.gz", sanitize.Name(c.Name), role, sanitize.Name(hostutil.GetHostnameForMsg(hostObject)))
-				}
-			} else {
-				tarredFilename = fmt.Sprintf("%s_%s", fileNameSplit[len(fileNameSplit)-2], fileNameSplit[len(fileNameSplit)-1])
-			}
-		}
-		tarredFilenames = append(tarredFilenames, tarredFilename)
-	}
-
-	if len(files) < 1 {
+	entries := buildLogTarEntries(c, destFileName, keys)
+	if len(entries) < 1 {
 		return "", common.NewApiError(http.StatusNotFound,
 			errors.Errorf("No log files were found"))
 	}
 
-	log.Debugf("List of files to include into %s is %s", fileName, files)
-	err = s3wrapper.TarAwsFiles(ctx, fileName, files, tarredFilenames, objectHandler, log)
-	if err != nil {
-		log.WithError(err).Errorf("failed to download file %s", fileName)
+	log.Debugf("Streaming %d files into %s", len(entries), destFileName)
+	if err = m.streamTarredClusterLogs(ctx, c, objectHandler, destFileName, entries); err != nil {
+		log.WithError(err).Errorf("failed to build tarred logs %s", destFileName)
 		return "", common.NewApiError(http.StatusInternalServerError, err)
 	}
-	return fileName, nil
+	return destFileName, nil
 }
 
+// IsReadyForInstallation reports whether c's Status is Ready, falling back -
+// when it isn't - to the Ready condition's Message if updateClusterConditions
+// has already computed one, and to the flat StatusInfo otherwise so callers
+// that predate the Conditions array keep working unchanged.
 func (m *Manager) IsReadyForInstallation(c *common.Cluster) (bool, string) {
 	if swag.StringValue(c.Status) != models.ClusterStatusReady {
+		if cond := findCondition(c, ReadyCondition); cond != nil && cond.Message != "" {
+			return false, cond.Message
+		}
 		return false, swag.StringValue(c.StatusInfo)
 	}
 	return true, ""
 }
 
+// setConnectivityMajorityGroupsForClusterInternal recomputes every CIDR and
+// address family's majority group in parallel (computeMajorityGroupsParallel,
+// bounded by GOMAXPROCS) and skips the work entirely when cluster's host set
+// and their connectivity reports match the signature the last successful run
+// recorded in m.majorityGroupsCache. UpdateConnectivityMajorityGroupsForHost
+// is the incremental counterpart this short-circuits in favor of when only
+// one host's report actually changed.
 func (m *Manager) setConnectivityMajorityGroupsForClusterInternal(cluster *common.Cluster, db *gorm.DB) error {
 	if db == nil {
 		db = m.db
 	}
 	// We want to calculate majority groups only when in pre-install states since it is needed for pre-install validations
-	allowedStates := []string{
-		models.ClusterStatusPendingForInput,
-		models.ClusterStatusInsufficient,
-		models.ClusterStatusReady,
-	}
-	if !funk.ContainsString(allowedStates, swag.StringValue(cluster.Status)) {
+	if !funk.ContainsString(majorityGroupsAllowedStates, swag.StringValue(cluster.Status)) {
 		return nil
 	}
 
@@ -1031,40 +1251,21 @@ func (m *Manager) setConnectivityMajorityGroupsForClusterInternal(cluster *commo
 	sort.Slice(hosts, func(i, j int) bool {
 		return hosts[i].ID.String() < hosts[j].ID.String()
 	})
-	majorityGroups := make(map[string][]strfmt.UUID)
-	for _, cidr := range network.GetClusterNetworks(hosts, m.log) {
-		majorityGroup, err := network.CreateL2MajorityGroup(cidr, hosts)
-		if err != nil {
-			m.log.WithError(err).Warnf("Create majority group for %s", cidr)
-			continue
-		}
-		majorityGroups[cidr] = majorityGroup
-	}
 
-	for _, family := range []network.AddressFamily{network.IPv4, network.IPv6} {
-		majorityGroup, err := network.CreateL3MajorityGroup(hosts, family)
-		if err != nil {
-			m.log.WithError(err).Warnf("Create L3 majority group for cluster %s failed", cluster.ID.String())
-		} else {
-			majorityGroups[family.String()] = majorityGroup
-		}
-	}
-	b, err := json.Marshal(&majorityGroups)
-	if err != nil {
-		return common.NewApiError(http.StatusInternalServerError, err)
+	sig := connectivitySignature(hosts)
+	if m.majorityGroupsCache.signatureUnchanged(cluster.ID.String(), sig) {
+		m.metricAPI.ConnectivityMajorityGroupsCacheHit()
+		return nil
 	}
 
-	marshalledMajorityGroups := string(b)
-	if marshalledMajorityGroups != cluster.ConnectivityMajorityGroups {
-		err = db.Model(&common.Cluster{}).Where("id = ?", cluster.ID.String()).Updates(&common.Cluster{
-			Cluster: models.Cluster{
-				ConnectivityMajorityGroups: marshalledMajorityGroups,
-			},
-		}).Error
-		if err != nil {
-			return common.NewApiError(http.StatusInternalServerError, err)
-		}
+	start := time.Now()
+	majorityGroups := m.computeMajorityGroupsParallel(majorityGroupJobsForHosts(hosts, hosts, m.log))
+	m.metricAPI.ConnectivityMajorityGroupsComputeDuration(time.Since(start))
+
+	if err := m.persistMajorityGroups(db, cluster, majorityGroups); err != nil {
+		return err
 	}
+	m.majorityGroupsCache.record(cluster.ID.String(), sig)
 	return nil
 }
 
@@ -1158,6 +1359,10 @@ func (m Manager) PermanentClustersDeletion(ctx context.Context, olderThan strfmt
 		c := clusters[i]
 		m.log.Infof("Permanently deleting cluster %s that was de-registered before %s", c.ID.String(), olderThan)
 
+		if err := m.sanitizer.Sanitize(ctx, c); err != nil {
+			m.log.WithError(err).Warnf("Failed to sanitize cluster %s", c.ID.String())
+		}
+
 		deleteFromDB := true
 		if err := m.DeleteClusterFiles(ctx, c, objectHandler); err != nil {
 			deleteFromDB = false
@@ -1208,41 +1413,68 @@ func (m *Manager) GetClusterByKubeKey(key types.NamespacedName) (*common.Cluster
 	return c, nil
 }
 
+// GenerateAdditionalManifests renders every day-1 manifest this cluster
+// needs and records the outcome on ManifestsGeneratedCondition and (for the
+// disk encryption manifest specifically, since UIs want to show it
+// separately from the rest) DiskEncryptionConfiguredCondition.
 func (m *Manager) GenerateAdditionalManifests(ctx context.Context, cluster *common.Cluster) error {
 	log := logutil.FromContext(ctx, m.log)
 	if err := m.manifestsGeneratorAPI.AddChronyManifest(ctx, log, cluster); err != nil {
-		return errors.Wrap(err, "failed to add chrony manifest")
+		return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add chrony manifest"))
 	}
 
 	if common.IsSingleNodeCluster(cluster) && m.manifestsGeneratorAPI.IsSNODNSMasqEnabled() {
 		if err := m.manifestsGeneratorAPI.AddDnsmasqForSingleNode(ctx, log, cluster); err != nil {
-			return errors.Wrap(err, "failed to add dnsmasq manifest")
+			return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add dnsmasq manifest"))
 		}
 		if err := m.manifestsGeneratorAPI.AddNodeIpHint(ctx, log, cluster); err != nil {
-			return errors.Wrap(err, "failed to add node ip hint manifest")
+			return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add node ip hint manifest"))
 		}
 	}
 
 	if err := m.rp.operatorsAPI.GenerateManifests(ctx, cluster); err != nil {
-		return errors.Wrap(err, "failed to add operator manifests")
+		return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add operator manifests"))
 	}
 	if err := m.manifestsGeneratorAPI.AddTelemeterManifest(ctx, log, cluster); err != nil {
-		return errors.Wrap(err, "failed to add telemeter manifest")
+		return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add telemeter manifest"))
 	}
 
-	if common.AreMastersSchedulable(cluster) {
+	if common.AreMastersSchedulable(cluster) && masterFailureDomainCount(cluster) < minSpreadFailureDomains {
 		if err := m.manifestsGeneratorAPI.AddSchedulableMastersManifest(ctx, log, cluster); err != nil {
-			return errors.Wrap(err, "failed to add schedulable masters manifest")
+			return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add schedulable masters manifest"))
+		}
+	}
+
+	if masterFailureDomainCount(cluster) >= minSpreadFailureDomains {
+		if err := m.manifestsGeneratorAPI.AddTopologySpreadManifest(ctx, log, cluster); err != nil {
+			return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add topology spread manifest"))
 		}
 	}
 
 	if err := m.manifestsGeneratorAPI.AddDiskEncryptionManifest(ctx, log, cluster); err != nil {
-		return errors.Wrap(err, "failed to add disk encryption manifest")
+		SetCondition(cluster, common.ClusterCondition{Type: DiskEncryptionConfiguredCondition, Status: corev1.ConditionFalse, Reason: "DiskEncryptionManifestFailed", Message: err.Error()})
+		return m.failManifestsGenerated(ctx, cluster, errors.Wrap(err, "failed to add disk encryption manifest"))
 	}
+	SetCondition(cluster, common.ClusterCondition{Type: DiskEncryptionConfiguredCondition, Status: corev1.ConditionTrue, Reason: "DiskEncryptionManifestAdded", Message: "disk encryption manifest was generated"})
 
+	SetCondition(cluster, common.ClusterCondition{Type: ManifestsGeneratedCondition, Status: corev1.ConditionTrue, Reason: "ManifestsGenerated", Message: "all additional manifests were generated"})
+	if _, err := m.updateConditionsInDB(ctx, m.db, cluster); err != nil {
+		m.log.WithError(err).Warnf("Failed to persist ManifestsGenerated condition for cluster %s", cluster.ID.String())
+	}
 	return nil
 }
 
+// failManifestsGenerated records manifestErr on ManifestsGeneratedCondition
+// before returning it unchanged, so a caller can still treat the returned
+// error exactly as before while the condition reflects the failure.
+func (m *Manager) failManifestsGenerated(ctx context.Context, cluster *common.Cluster, manifestErr error) error {
+	SetCondition(cluster, common.ClusterCondition{Type: ManifestsGeneratedCondition, Status: corev1.ConditionFalse, Reason: "ManifestGenerationFailed", Message: manifestErr.Error()})
+	if _, err := m.updateConditionsInDB(ctx, m.db, cluster); err != nil {
+		m.log.WithError(err).Warnf("Failed to persist ManifestsGenerated condition for cluster %s", cluster.ID.String())
+	}
+	return manifestErr
+}
+
 func (m *Manager) CompleteInstallation(ctx context.Context, db *gorm.DB,
 	cluster *common.Cluster, successfullyFinished bool, reason string) (*common.Cluster, error) {
 	log := logutil.FromContext(ctx, m.log)
@@ -1261,10 +1493,15 @@ func (m *Manager) CompleteInstallation(ctx context.Context, db *gorm.DB,
 		// Update AMS subscription only if configured and installation succeeded
 		if m.ocmClient != nil {
 			if err := m.ocmClient.AccountsMgmt.UpdateSubscriptionStatusActive(ctx, cluster.AmsSubscriptionID); err != nil {
+				SetCondition(cluster, common.ClusterCondition{Type: AMSSubscriptionActiveCondition, Status: corev1.ConditionFalse, Reason: "AMSSubscriptionUpdateFailed", Message: err.Error()})
+				if _, condErr := m.updateConditionsInDB(ctx, db, cluster); condErr != nil {
+					log.WithError(condErr).Warnf("Failed to persist AMSSubscriptionActive condition for cluster %s", *cluster.ID)
+				}
 				err = errors.Wrapf(err, "Failed to update AMS subscription for cluster %s with status 'Active'", *cluster.ID)
 				log.Error(err)
 				return nil, err
 			}
+			SetCondition(cluster, common.ClusterCondition{Type: AMSSubscriptionActiveCondition, Status: corev1.ConditionTrue, Reason: "AMSSubscriptionActive", Message: "AMS subscription status set to Active"})
 		}
 	}
 
@@ -1279,9 +1516,15 @@ func (m *Manager) CompleteInstallation(ctx context.Context, db *gorm.DB,
 
 	if !successfullyFinished {
 		result = models.ClusterStatusError
+		SetCondition(cluster, common.ClusterCondition{Type: InstallationCompleteCondition, Status: corev1.ConditionFalse, Reason: "InstallationFailed", Message: reason})
 		eventgen.SendClusterInstallationFailedEvent(ctx, m.eventsHandler, *cluster.ID, reason)
 	} else {
+		SetCondition(cluster, common.ClusterCondition{Type: InstallationCompleteCondition, Status: corev1.ConditionTrue, Reason: "InstallationComplete", Message: "installation completed successfully"})
 		eventgen.SendClusterInstallationCompletedEvent(ctx, m.eventsHandler, *cluster.ID)
+		m.operatorHealthMonitor.Register(cluster.ID.String(), operatorhealth.KindCluster)
+	}
+	if _, err = m.updateConditionsInDB(ctx, db, cluster); err != nil {
+		log.WithError(err).Warnf("Failed to persist InstallationComplete condition for cluster %s", *cluster.ID)
 	}
 
 	return clusterAfterUpdate, nil
@@ -1316,5 +1559,17 @@ func (m *Manager) TransformClusterToDay2(ctx context.Context, cluster *common.Cl
 		log.Error(err)
 		return common.NewApiError(http.StatusInternalServerError, err)
 	}
+
+	// Force GetClient to rebuild the cached accessor against the cluster's
+	// new AddingHosts state rather than serving a client warmed while it was
+	// still a single-node Installed cluster.
+	m.clusterCache.Evict(cluster.ID.String())
+	m.enqueueDefaultDay2MaintenanceManifests(*cluster.ID)
+	m.operatorHealthMonitor.Register(cluster.ID.String(), operatorhealth.KindDay2)
+
+	SetCondition(cluster, common.ClusterCondition{Type: Day2TransitionedCondition, Status: corev1.ConditionTrue, Reason: "Day2Transitioned", Message: "cluster was transformed into a day-2, add-hosts cluster"})
+	if _, err := m.updateConditionsInDB(ctx, db, cluster); err != nil {
+		log.WithError(err).Warnf("Failed to persist Day2Transitioned condition for cluster %s", cluster.ID.String())
+	}
 	return nil
 }
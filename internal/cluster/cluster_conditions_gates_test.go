@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"github.com/go-openapi/swag"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("UploadIngressCert", func() {
+	m := &Manager{}
+
+	It("sets IngressCertUploadedCondition True when the cluster is Finalizing", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusFinalizing)
+
+		Expect(m.UploadIngressCert(c)).To(Succeed())
+		cond := findCondition(c, IngressCertUploadedCondition)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("sets IngressCertUploadedCondition False and returns an error outside the allowed states", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInsufficient)
+
+		Expect(m.UploadIngressCert(c)).To(HaveOccurred())
+		cond := findCondition(c, IngressCertUploadedCondition)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	})
+})
+
+var _ = Describe("CanDownloadKubeconfig", func() {
+	m := &Manager{}
+
+	It("rejects a cluster that has not reached a kubeconfig-producing state", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInsufficient)
+		Expect(m.CanDownloadKubeconfig(c)).To(HaveOccurred())
+	})
+
+	It("allows an Installed cluster with no InstallationHealthy condition yet", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInstalled)
+		Expect(m.CanDownloadKubeconfig(c)).To(Succeed())
+	})
+
+	It("rejects an Installed cluster whose InstallationHealthy condition is False", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInstalled)
+		SetCondition(c, common.ClusterCondition{Type: InstallationHealthyCondition, Status: corev1.ConditionFalse, Reason: "InstallationFailed", Message: "boom"})
+
+		Expect(m.CanDownloadKubeconfig(c)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("IsReadyForInstallation", func() {
+	m := &Manager{}
+
+	It("falls back to the Ready condition's message when set", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInsufficient)
+		c.StatusInfo = swag.String("stale status info")
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionFalse, Reason: "NotReady", Message: "hosts not ready"})
+
+		ready, reason := m.IsReadyForInstallation(c)
+		Expect(ready).To(BeFalse())
+		Expect(reason).To(Equal("hosts not ready"))
+	})
+
+	It("falls back to StatusInfo when no Ready condition has been computed yet", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusInsufficient)
+		c.StatusInfo = swag.String("stale status info")
+
+		ready, reason := m.IsReadyForInstallation(c)
+		Expect(ready).To(BeFalse())
+		Expect(reason).To(Equal("stale status info"))
+	})
+})
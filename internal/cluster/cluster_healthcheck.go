@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/common"
+	eventgen "github.com/openshift/assisted-service/internal/common/events"
+	"github.com/openshift/assisted-service/internal/constants"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HealthCheckCondition reports whether a post-install cluster's
+// kube-apiserver answered the most recent healthcheck.Prober probe.
+const HealthCheckCondition = "HealthCheck"
+
+// healthCheckableStatuses are the cluster Statuses RefreshClusterHealth
+// probes; an installing or not-yet-installed cluster has no kube-apiserver
+// worth dialing yet.
+var healthCheckableStatuses = map[string]bool{
+	models.ClusterStatusInstalled:   true,
+	models.ClusterStatusAddingHosts: true,
+}
+
+// RefreshClusterHealth probes c's kube-apiserver via m.healthProber - once
+// c is Installed or AddingHosts and its per-cluster backoff window has
+// elapsed - and records the outcome as the HealthCheck condition, emitting
+// ClusterDegraded or ClusterRecovered when that outcome flips from the
+// previous probe's.
+func (m *Manager) RefreshClusterHealth(ctx context.Context, c *common.Cluster) error {
+	if !healthCheckableStatuses[swag.StringValue(c.Status)] {
+		return nil
+	}
+	if !m.healthProber.ShouldProbe(c.ID.String()) {
+		return nil
+	}
+
+	previous := findCondition(c, HealthCheckCondition)
+	wasHealthy := previous == nil || previous.Status == corev1.ConditionTrue
+
+	reader, _, err := m.objectHandler.Download(ctx, fmt.Sprintf("%s/%s", c.ID.String(), constants.Kubeconfig))
+	if err != nil {
+		return errors.Wrapf(err, "failed to download kubeconfig for cluster %s", c.ID)
+	}
+	defer reader.Close()
+	kubeconfig, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read kubeconfig for cluster %s", c.ID)
+	}
+
+	result := m.healthProber.Probe(ctx, c.ID.String(), kubeconfig)
+
+	if _, cacheErr := m.clusterCache.GetClient(ctx, c.ID.String(), kubeconfig); cacheErr != nil {
+		m.log.WithError(cacheErr).Warnf("failed to refresh cached client for cluster %s", c.ID)
+	} else if cacheErr = m.clusterCache.HealthProbe(ctx, c.ID.String()); cacheErr != nil {
+		m.log.WithError(cacheErr).Debugf("cluster cache health probe failed for cluster %s", c.ID)
+	}
+
+	status := corev1.ConditionFalse
+	if result.Healthy {
+		status = corev1.ConditionTrue
+	}
+	SetCondition(c, common.ClusterCondition{Type: HealthCheckCondition, Status: status, Reason: result.Reason, Message: result.Message})
+	if _, err = m.updateConditionsInDB(ctx, m.db, c); err != nil {
+		return err
+	}
+
+	if wasHealthy && !result.Healthy {
+		eventgen.SendClusterDegradedEvent(ctx, m.eventsHandler, *c.ID, result.Message)
+	} else if !wasHealthy && result.Healthy {
+		eventgen.SendClusterRecoveredEvent(ctx, m.eventsHandler, *c.ID)
+	}
+
+	return nil
+}
+
+// findCondition returns the condition of type condType on c, or nil if none
+// has been set.
+func findCondition(c *common.Cluster, condType string) *common.ClusterCondition {
+	for i := range c.Conditions {
+		if c.Conditions[i].Type == condType {
+			return &c.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Package clustercache lazily builds and caches a controller-runtime
+// cluster.Cluster - informer-backed client included - per installed
+// cluster, keyed by cluster ID and built from that cluster's stored admin
+// kubeconfig. It replaces the pattern of constructing a one-shot kube
+// client on every monitor tick with a single choke-point for kubeconfig
+// rotation and connection reuse, the same role the cluster-api project's
+// ClusterCache plays for day-2 cluster access.
+package clustercache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/openshift/assisted-service/internal/controller/scheme"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// maxConsecutiveErrors is how many consecutive HealthProbe failures an
+// accessor tolerates before ClusterCache tears it down and forces the next
+// GetClient call to rebuild it from a fresh kubeconfig.
+const maxConsecutiveErrors = 3
+
+// watchedObjects are the kinds every accessor's informers are warmed for on
+// creation, covering the day-2 features (add-host readiness, operator
+// status, upgrade progress) that poll them on every monitor tick.
+var watchedObjects = []client.Object{
+	&corev1.Node{},
+	&configv1.ClusterOperator{},
+	&mcfgv1.MachineConfigPool{},
+}
+
+type accessor struct {
+	cl         cluster.Cluster
+	cancel     context.CancelFunc
+	errorCount int
+}
+
+// ClusterCache caches one accessor per cluster ID.
+type ClusterCache struct {
+	mu      sync.Mutex
+	entries map[string]*accessor
+}
+
+// NewClusterCache returns an empty ClusterCache.
+func NewClusterCache() *ClusterCache {
+	return &ClusterCache{entries: make(map[string]*accessor)}
+}
+
+// GetClient returns the cached controller-runtime client.Client for
+// clusterID, building one from kubeconfig - and starting its background
+// Node/ClusterOperator/MachineConfigPool informers - on first use.
+func (c *ClusterCache) GetClient(ctx context.Context, clusterID string, kubeconfig []byte) (client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[clusterID]; ok {
+		return e.cl.GetClient(), nil
+	}
+
+	e, err := newAccessor(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[clusterID] = e
+	return e.cl.GetClient(), nil
+}
+
+// GetClientIfPresent returns clusterID's already-cached client without
+// building one, so callers that only want to piggyback on a connection
+// GetClient already warmed - rather than pay for a fresh dial - can treat an
+// absent entry as "nothing to do" instead of an error.
+func (c *ClusterCache) GetClientIfPresent(clusterID string) (client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[clusterID]
+	if !ok {
+		return nil, nil
+	}
+	return e.cl.GetClient(), nil
+}
+
+func newAccessor(kubeconfig []byte) (*accessor, error) {
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	cl, err := cluster.New(cfg, func(o *cluster.Options) { o.Scheme = scheme.Scheme })
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cluster client: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = cl.Start(ctx)
+	}()
+
+	for _, obj := range watchedObjects {
+		if _, err = cl.GetCache().GetInformer(ctx, obj); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to start informer for %T: %w", obj, err)
+		}
+	}
+
+	return &accessor{cl: cl, cancel: cancel}, nil
+}
+
+// Watch registers handler on the informer clusterID's GetClient already
+// warmed for obj's kind.
+func (c *ClusterCache) Watch(ctx context.Context, clusterID string, obj client.Object, handler toolscache.ResourceEventHandler) error {
+	c.mu.Lock()
+	e, ok := c.entries[clusterID]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no cached client for cluster %s", clusterID)
+	}
+
+	informer, err := e.cl.GetCache().GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	_, err = informer.AddEventHandler(handler)
+	return err
+}
+
+// HealthProbe lists Nodes through clusterID's cached client to confirm it
+// can still reach the target API server, evicting the accessor once
+// maxConsecutiveErrors is crossed so the next GetClient rebuilds it from a
+// fresh kubeconfig.
+func (c *ClusterCache) HealthProbe(ctx context.Context, clusterID string) error {
+	c.mu.Lock()
+	e, ok := c.entries[clusterID]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no cached client for cluster %s", clusterID)
+	}
+
+	var nodes corev1.NodeList
+	err := e.cl.GetClient().List(ctx, &nodes, client.Limit(1))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		e.errorCount++
+		if e.errorCount >= maxConsecutiveErrors {
+			c.evictLocked(clusterID)
+		}
+		return err
+	}
+	e.errorCount = 0
+	return nil
+}
+
+// Evict tears down and removes clusterID's cached accessor, e.g. on cluster
+// deregistration.
+func (c *ClusterCache) Evict(clusterID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(clusterID)
+}
+
+func (c *ClusterCache) evictLocked(clusterID string) {
+	if e, ok := c.entries[clusterID]; ok {
+		e.cancel()
+		delete(c.entries, clusterID)
+	}
+}
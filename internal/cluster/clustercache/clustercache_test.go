@@ -0,0 +1,40 @@
+package clustercache
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClusterCache eviction", func() {
+	It("removes the accessor and runs its cancel func", func() {
+		c := NewClusterCache()
+		cancelled := false
+		c.entries["cluster-a"] = &accessor{cancel: func() { cancelled = true }}
+
+		c.Evict("cluster-a")
+
+		Expect(c.entries).ToNot(HaveKey("cluster-a"))
+		Expect(cancelled).To(BeTrue())
+	})
+
+	It("is a no-op for a cluster with no cached accessor", func() {
+		c := NewClusterCache()
+		Expect(func() { c.Evict("missing") }).ToNot(Panic())
+	})
+})
+
+var _ = Describe("GetClient", func() {
+	It("returns an error for an unparseable kubeconfig", func() {
+		c := NewClusterCache()
+		_, err := c.GetClient(nil, "cluster-a", []byte("not a kubeconfig"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("HealthProbe and Watch", func() {
+	It("error when the cluster has no cached accessor", func() {
+		c := NewClusterCache()
+		Expect(c.HealthProbe(nil, "missing")).To(HaveOccurred())
+		Expect(c.Watch(nil, "missing", nil, nil)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,197 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	logutil "github.com/openshift/assisted-service/pkg/log"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Well-known ClusterCondition Types. Each summarizes a subset of
+// refreshPreprocessor's validation results (or, for Ready and
+// InstallationHealthy, the cluster's own Status) into a stable,
+// Kubernetes-style condition a kube-based controller can watch instead of
+// parsing the flat ValidationsInfo blob itself.
+const (
+	ReadyCondition               = "Ready"
+	NetworkConfiguredCondition   = "NetworkConfigured"
+	HostsReadyCondition          = "HostsReady"
+	DNSConfiguredCondition       = "DNSConfigured"
+	MachineCIDRAssignedCondition = "MachineCIDRAssigned"
+	VipsResolvedCondition        = "VipsResolved"
+	OperatorsReadyCondition      = "OperatorsReady"
+	InstallationHealthyCondition = "InstallationHealthy"
+
+	// IngressCertUploadedCondition, PreparationSucceededCondition,
+	// MajorityGroupsComputedCondition, InstallationCompleteCondition,
+	// AMSSubscriptionActiveCondition, ManifestsGeneratedCondition,
+	// DiskEncryptionConfiguredCondition, Day2TransitionedCondition and
+	// OperatorsHealthyCondition are all set directly by the gates/steps
+	// they name (UploadIngressCert, HandlePreInstallError/Success,
+	// setConnectivityMajorityGroupsForClusterInternal, CompleteInstallation,
+	// GenerateAdditionalManifests, TransformClusterToDay2, and
+	// operatorHealthConditionRecorder on behalf of the post-install
+	// operatorhealth.Monitor, respectively) rather than derived from
+	// ValidationsStatus, so - unlike the conditions above - they have no entry
+	// in clusterConditionSources.
+	IngressCertUploadedCondition      = "IngressCertUploaded"
+	PreparationSucceededCondition     = "PreparationSucceeded"
+	MajorityGroupsComputedCondition   = "MajorityGroupsComputed"
+	InstallationCompleteCondition     = "InstallationComplete"
+	AMSSubscriptionActiveCondition    = "AMSSubscriptionActive"
+	ManifestsGeneratedCondition       = "ManifestsGenerated"
+	DiskEncryptionConfiguredCondition = "DiskEncryptionConfigured"
+	Day2TransitionedCondition         = "Day2Transitioned"
+	OperatorsHealthyCondition         = "OperatorsHealthy"
+)
+
+// conditionSource describes which validation(s) a well-known ClusterCondition
+// summarizes: every validation in category when id is empty, or just the one
+// validation identified by id within that category.
+type conditionSource struct {
+	category string
+	id       string
+}
+
+var clusterConditionSources = map[string]conditionSource{
+	NetworkConfiguredCondition:   {category: "network"},
+	HostsReadyCondition:          {category: "hosts-data"},
+	OperatorsReadyCondition:      {category: "operators"},
+	DNSConfiguredCondition:       {category: "network", id: "dns-domain-defined"},
+	MachineCIDRAssignedCondition: {category: "network", id: "machine-cidr-defined"},
+	VipsResolvedCondition:        {category: "network", id: "api-vips-defined"},
+}
+
+// SetCondition sets newCond on c.Conditions, de-duplicating by Type. If an
+// existing condition of the same Type already has the same Status, only
+// Reason, Message and LastProbeTime are refreshed - LastTransitionTime only
+// moves when Status actually flips, the same contract
+// conditionsv1.SetStatusCondition gives the Agent CRD's conditions. The most
+// recently set False condition also mirrors its Reason/Message onto
+// c.StatusReason/c.StatusMessage, so those flat fields stay a readable
+// one-line summary of why the cluster isn't ready without a caller having to
+// pick a condition themselves.
+func SetCondition(c *common.Cluster, newCond common.ClusterCondition) {
+	now := strfmt.DateTime(time.Now())
+	newCond.LastProbeTime = now
+	newCond.LastTransitionTime = now
+
+	for i, existing := range c.Conditions {
+		if existing.Type != newCond.Type {
+			continue
+		}
+		if existing.Status == newCond.Status {
+			newCond.LastTransitionTime = existing.LastTransitionTime
+		}
+		c.Conditions[i] = newCond
+		if newCond.Status == corev1.ConditionFalse {
+			c.StatusReason = newCond.Reason
+			c.StatusMessage = newCond.Message
+		}
+		return
+	}
+
+	c.Conditions = append(c.Conditions, newCond)
+	if newCond.Status == corev1.ConditionFalse {
+		c.StatusReason = newCond.Reason
+		c.StatusMessage = newCond.Message
+	}
+}
+
+// clusterConditionStatus aggregates validationRes for source into a
+// condition Status/Reason/Message: Unknown with a "pending" reason until the
+// category has reported at all, True once every validation it covers
+// passes, and False - carrying the failing validations' messages - as soon
+// as one doesn't.
+func clusterConditionStatus(source conditionSource, validationRes ValidationsStatus) (corev1.ConditionStatus, string, string) {
+	results, ok := validationRes[source.category]
+	if !ok || len(results) == 0 {
+		return corev1.ConditionUnknown, "ValidationsPending", "validation results not yet available"
+	}
+
+	if source.id != "" {
+		for _, r := range results {
+			if r.ID.String() == source.id {
+				return validationResultCondition(r)
+			}
+		}
+		return corev1.ConditionUnknown, "ValidationsPending", "validation results not yet available"
+	}
+
+	var failing []string
+	for _, r := range results {
+		if r.Status == ValidationFailure {
+			failing = append(failing, r.Message)
+		}
+	}
+	if len(failing) > 0 {
+		return corev1.ConditionFalse, "ValidationsFailing", strings.Join(failing, ", ")
+	}
+	return corev1.ConditionTrue, "ValidationsPassing", "all validations in this category are passing"
+}
+
+func validationResultCondition(r ValidationResult) (corev1.ConditionStatus, string, string) {
+	switch r.Status {
+	case ValidationSuccess:
+		return corev1.ConditionTrue, "ValidationPassing", r.Message
+	case ValidationFailure:
+		return corev1.ConditionFalse, "ValidationFailing", r.Message
+	default:
+		return corev1.ConditionUnknown, "ValidationPending", r.Message
+	}
+}
+
+// conditionTypeForValidation returns the well-known ClusterCondition Type
+// that summarizes id within category, or "" if no condition claims it.
+func conditionTypeForValidation(category string, id ValidationID) string {
+	for condType, source := range clusterConditionSources {
+		if source.category != category {
+			continue
+		}
+		if source.id == "" || source.id == string(id) {
+			return condType
+		}
+	}
+	return ""
+}
+
+// updateClusterConditions recomputes every well-known ClusterCondition on c
+// from newValidationRes plus c's own Status, so refreshStatusInternal keeps
+// the kube-style Conditions slice in lock-step with the flat Status and
+// ValidationsInfo fields it has always maintained.
+func (m *Manager) updateClusterConditions(c *common.Cluster, newValidationRes ValidationsStatus) {
+	for condType, source := range clusterConditionSources {
+		status, reason, message := clusterConditionStatus(source, newValidationRes)
+		SetCondition(c, common.ClusterCondition{Type: condType, Status: status, Reason: reason, Message: message})
+	}
+
+	readyStatus, readyReason, readyMessage := corev1.ConditionFalse, "NotReady", swag.StringValue(c.StatusInfo)
+	if swag.StringValue(c.Status) == models.ClusterStatusReady {
+		readyStatus, readyReason, readyMessage = corev1.ConditionTrue, "Ready", "cluster is ready for installation"
+	}
+	SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: readyStatus, Reason: readyReason, Message: readyMessage})
+
+	healthyStatus, healthyReason, healthyMessage := corev1.ConditionTrue, "InstallationHealthy", "no installation errors detected"
+	if swag.StringValue(c.Status) == models.ClusterStatusError {
+		healthyStatus, healthyReason, healthyMessage = corev1.ConditionFalse, "InstallationFailed", swag.StringValue(c.StatusInfo)
+	}
+	SetCondition(c, common.ClusterCondition{Type: InstallationHealthyCondition, Status: healthyStatus, Reason: healthyReason, Message: healthyMessage})
+}
+
+// updateConditionsInDB persists c.Conditions, mirroring updateValidationsInDB's
+// pattern for ValidationsInfo.
+func (m *Manager) updateConditionsInDB(ctx context.Context, db *gorm.DB, c *common.Cluster) (*common.Cluster, error) {
+	b, err := json.Marshal(c.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	return UpdateCluster(logutil.FromContext(ctx, m.log), db, *c.ID, *c.Status, "conditions", string(b))
+}
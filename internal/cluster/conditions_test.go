@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"github.com/go-openapi/swag"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("SetCondition", func() {
+	It("appends a new condition and mirrors a False status onto StatusReason/StatusMessage", func() {
+		c := &common.Cluster{}
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionFalse, Reason: "NotReady", Message: "not ready yet"})
+
+		Expect(c.Conditions).To(HaveLen(1))
+		Expect(c.Conditions[0].Type).To(Equal(ReadyCondition))
+		Expect(c.StatusReason).To(Equal("NotReady"))
+		Expect(c.StatusMessage).To(Equal("not ready yet"))
+	})
+
+	It("does not bump LastTransitionTime when Status is unchanged", func() {
+		c := &common.Cluster{}
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionFalse, Reason: "NotReady", Message: "first"})
+		firstTransition := c.Conditions[0].LastTransitionTime
+
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionFalse, Reason: "NotReady", Message: "second"})
+
+		Expect(c.Conditions).To(HaveLen(1))
+		Expect(c.Conditions[0].Message).To(Equal("second"))
+		Expect(c.Conditions[0].LastTransitionTime).To(Equal(firstTransition))
+	})
+
+	It("bumps LastTransitionTime when Status flips", func() {
+		c := &common.Cluster{}
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionFalse, Reason: "NotReady"})
+		firstTransition := c.Conditions[0].LastTransitionTime
+
+		SetCondition(c, common.ClusterCondition{Type: ReadyCondition, Status: corev1.ConditionTrue, Reason: "Ready"})
+
+		Expect(c.Conditions).To(HaveLen(1))
+		Expect(c.Conditions[0].LastTransitionTime).ToNot(Equal(firstTransition))
+	})
+
+	It("leaves StatusReason/StatusMessage alone once a later condition turns True", func() {
+		c := &common.Cluster{}
+		SetCondition(c, common.ClusterCondition{Type: HostsReadyCondition, Status: corev1.ConditionFalse, Reason: "ValidationsFailing", Message: "hosts not ready"})
+		SetCondition(c, common.ClusterCondition{Type: HostsReadyCondition, Status: corev1.ConditionTrue, Reason: "ValidationsPassing", Message: "hosts ready"})
+
+		Expect(c.StatusReason).To(Equal("ValidationsFailing"))
+		Expect(c.StatusMessage).To(Equal("hosts not ready"))
+	})
+})
+
+var _ = Describe("clusterConditionStatus", func() {
+	It("reports Unknown when the category hasn't reported yet", func() {
+		status, reason, _ := clusterConditionStatus(conditionSource{category: "network"}, ValidationsStatus{})
+		Expect(status).To(Equal(corev1.ConditionUnknown))
+		Expect(reason).To(Equal("ValidationsPending"))
+	})
+
+	It("reports False with the failing messages when any validation in the category fails", func() {
+		vs := ValidationsStatus{
+			"network": {
+				{ID: ValidationID("machine-cidr-defined"), Status: ValidationSuccess, Message: "cidr ok"},
+				{ID: ValidationID("api-vips-defined"), Status: ValidationFailure, Message: "vips missing"},
+			},
+		}
+		status, reason, message := clusterConditionStatus(conditionSource{category: "network"}, vs)
+		Expect(status).To(Equal(corev1.ConditionFalse))
+		Expect(reason).To(Equal("ValidationsFailing"))
+		Expect(message).To(Equal("vips missing"))
+	})
+
+	It("reports True once every validation in the category passes", func() {
+		vs := ValidationsStatus{
+			"network": {
+				{ID: ValidationID("machine-cidr-defined"), Status: ValidationSuccess, Message: "cidr ok"},
+			},
+		}
+		status, _, _ := clusterConditionStatus(conditionSource{category: "network"}, vs)
+		Expect(status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("resolves a single validation ID within the category when one is specified", func() {
+		vs := ValidationsStatus{
+			"network": {
+				{ID: ValidationID("machine-cidr-defined"), Status: ValidationFailure, Message: "cidr missing"},
+				{ID: ValidationID("api-vips-defined"), Status: ValidationSuccess, Message: "vips ok"},
+			},
+		}
+		status, _, message := clusterConditionStatus(conditionSource{category: "network", id: "api-vips-defined"}, vs)
+		Expect(status).To(Equal(corev1.ConditionTrue))
+		Expect(message).To(Equal("vips ok"))
+	})
+})
+
+var _ = Describe("updateClusterConditions", func() {
+	m := &Manager{}
+
+	It("sets ReadyCondition True only when the cluster Status is Ready", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusReady)
+		m.updateClusterConditions(c, ValidationsStatus{})
+
+		cond := findCondition(c, ReadyCondition)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("sets InstallationHealthyCondition False when the cluster Status is Error", func() {
+		c := &common.Cluster{}
+		c.Status = swag.String(models.ClusterStatusError)
+		c.StatusInfo = swag.String("installation failed")
+		m.updateClusterConditions(c, ValidationsStatus{})
+
+		cond := findCondition(c, InstallationHealthyCondition)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+		Expect(cond.Message).To(Equal("installation failed"))
+	})
+})
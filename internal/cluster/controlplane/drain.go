@@ -0,0 +1,63 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Drainer cordons a Node and evicts the non-DaemonSet pods running on it.
+type Drainer interface {
+	Drain(ctx context.Context, cl client.Client, nodeName string) error
+}
+
+type nodeDrainer struct{}
+
+// NewNodeDrainer returns the default, controller-runtime-client-backed
+// Drainer.
+func NewNodeDrainer() Drainer {
+	return &nodeDrainer{}
+}
+
+// Drain marks nodeName unschedulable and deletes every pod running on it
+// that isn't owned by a DaemonSet, mirroring `oc adm drain --ignore-daemonsets`.
+func (d *nodeDrainer) Drain(ctx context.Context, cl client.Client, nodeName string) error {
+	var node corev1.Node
+	if err := cl.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	if !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		if err := cl.Update(ctx, &node); err != nil {
+			return fmt.Errorf("failed to cordon node %s: %w", nodeName, err)
+		}
+	}
+
+	var pods corev1.PodList
+	if err := cl.List(ctx, &pods); err != nil {
+		return fmt.Errorf("failed to list pods to evict from node %s: %w", nodeName, err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeName || isDaemonSetPod(pod) {
+			continue
+		}
+		if err := cl.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
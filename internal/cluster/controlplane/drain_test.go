@@ -0,0 +1,24 @@
+package controlplane
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("isDaemonSetPod", func() {
+	It("recognizes a pod owned by a DaemonSet", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}}}}
+		Expect(isDaemonSetPod(pod)).To(BeTrue())
+	})
+
+	It("does not flag a pod owned by a ReplicaSet", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-abc123"}}}}
+		Expect(isDaemonSetPod(pod)).To(BeFalse())
+	})
+
+	It("does not flag a pod with no owner references", func() {
+		Expect(isDaemonSetPod(&corev1.Pod{})).To(BeFalse())
+	})
+})
@@ -0,0 +1,69 @@
+// Package controlplane implements the two building blocks
+// RemoveControlPlaneHost needs to retire a day-2 master: draining its Node
+// and removing its etcd member, each kept behind a narrow interface so the
+// orchestration in cluster.Manager can be unit tested without a live
+// target cluster or etcd endpoint.
+package controlplane
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// MemberRemover removes the etcd member named memberName from the cluster
+// reachable at endpoints, authenticating with the given client certificate
+// and CA.
+type MemberRemover interface {
+	RemoveMember(ctx context.Context, endpoints []string, tlsCert, tlsKey, caCert []byte, memberName string) error
+}
+
+type etcdMemberRemover struct {
+	dialTimeout time.Duration
+}
+
+// NewEtcdMemberRemover returns the real, clientv3-backed MemberRemover.
+func NewEtcdMemberRemover() MemberRemover {
+	return &etcdMemberRemover{dialTimeout: 10 * time.Second}
+}
+
+func (r *etcdMemberRemover) RemoveMember(ctx context.Context, endpoints []string, tlsCert, tlsKey, caCert []byte, memberName string) error {
+	cert, err := tls.X509KeyPair(tlsCert, tlsKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse etcd client certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return fmt.Errorf("failed to parse etcd CA certificate")
+	}
+
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: r.dialTimeout,
+		TLS:         &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	defer cli.Close()
+
+	members, err := cli.MemberList(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list etcd members: %w", err)
+	}
+
+	for _, member := range members.Members {
+		if member.Name != memberName {
+			continue
+		}
+		if _, err = cli.MemberRemove(ctx, member.ID); err != nil {
+			return fmt.Errorf("failed to remove etcd member %s: %w", memberName, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no etcd member named %s found", memberName)
+}
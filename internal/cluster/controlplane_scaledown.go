@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/common"
+	eventgen "github.com/openshift/assisted-service/internal/common/events"
+	"github.com/openshift/assisted-service/internal/constants"
+	logutil "github.com/openshift/assisted-service/pkg/log"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// etcd object names mirror the Secret/ConfigMap a real OpenShift control
+// plane exposes for etcdctl-style access: the etcd-client Secret carries the
+// client certificate/key pair, and the etcd-serving-ca ConfigMap carries the
+// CA bundle those certificates chain to.
+const (
+	etcdNamespace        = "openshift-etcd"
+	etcdClientSecretName = "etcd-client"
+	etcdCAConfigMapName  = "etcd-serving-ca"
+
+	// minRemainingMasters is the fewest control plane hosts
+	// RemoveControlPlaneHost will leave behind, so a scale-down can never
+	// itself take the etcd cluster below quorum.
+	minRemainingMasters = 3
+)
+
+// RemoveControlPlaneHost retires a master from a day-2 AddingHosts/Installed
+// cluster: it cordons and drains the Node via the target cluster's
+// kubeconfig, removes its etcd member, deletes the Node object, and
+// recomputes install progress. Unlike AcceptRegistration - which only ever
+// grows the control plane with workers - this is the one path that shrinks
+// it, so it's guarded by the quorum check below.
+func (m *Manager) RemoveControlPlaneHost(ctx context.Context, c *common.Cluster, hostID strfmt.UUID, db *gorm.DB) error {
+	if db == nil {
+		db = m.db
+	}
+	log := logutil.FromContext(ctx, m.log)
+
+	if swag.StringValue(c.HighAvailabilityMode) == models.ClusterHighAvailabilityModeNone {
+		return errors.Errorf("cannot remove a control plane host from single-node cluster %s", c.ID)
+	}
+
+	host := findHostByID(c, hostID)
+	if host == nil {
+		return errors.Errorf("host %s not found in cluster %s", hostID, c.ID)
+	}
+	if host.Role != models.HostRoleMaster {
+		return errors.Errorf("host %s is not a control plane host", hostID)
+	}
+
+	remainingMasters := 0
+	for _, h := range c.Hosts {
+		if h.Role == models.HostRoleMaster && h.ID.String() != hostID.String() {
+			remainingMasters++
+		}
+	}
+	if remainingMasters < minRemainingMasters {
+		return errors.Errorf("removing host %s would leave only %d control plane hosts, below the %d required for etcd quorum",
+			hostID, remainingMasters, minRemainingMasters)
+	}
+
+	if err := m.CanDownloadKubeconfig(c); err != nil {
+		return errors.Wrapf(err, "cannot remove control plane host %s", hostID)
+	}
+
+	cl, nodeName, err := m.targetClusterClientForHost(ctx, c, host)
+	if err != nil {
+		return err
+	}
+
+	eventgen.SendControlPlaneHostRemovalStartedEvent(ctx, m.eventsHandler, *c.ID, hostID)
+
+	if err = m.drainer.Drain(ctx, cl, nodeName); err != nil {
+		eventgen.SendControlPlaneHostRemovalFailedEvent(ctx, m.eventsHandler, *c.ID, hostID, err.Error())
+		return errors.Wrapf(err, "failed to drain node %s", nodeName)
+	}
+
+	if err = m.removeEtcdMember(ctx, cl, c, hostID, nodeName); err != nil {
+		eventgen.SendControlPlaneHostRemovalFailedEvent(ctx, m.eventsHandler, *c.ID, hostID, err.Error())
+		return errors.Wrapf(err, "failed to remove etcd member for node %s", nodeName)
+	}
+
+	node := &corev1.Node{}
+	node.Name = nodeName
+	if err = cl.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+		eventgen.SendControlPlaneHostRemovalFailedEvent(ctx, m.eventsHandler, *c.ID, hostID, err.Error())
+		return errors.Wrapf(err, "failed to delete node %s", nodeName)
+	}
+
+	if err = db.Where("id = ? AND cluster_id = ?", hostID.String(), c.ID.String()).Delete(&models.Host{}).Error; err != nil {
+		log.WithError(err).Warnf("Failed to delete DB record for removed control plane host %s", hostID)
+	}
+
+	if err = m.UpdateInstallProgress(ctx, *c.ID); err != nil {
+		log.WithError(err).Warnf("Failed to refresh install progress after removing control plane host %s", hostID)
+	}
+
+	eventgen.SendControlPlaneHostRemovedEvent(ctx, m.eventsHandler, *c.ID, hostID)
+	return nil
+}
+
+// targetClusterClientForHost downloads c's kubeconfig, resolves it to a
+// cached client via m.clusterCache, and returns the Node name host is known
+// as on the target cluster.
+func (m *Manager) targetClusterClientForHost(ctx context.Context, c *common.Cluster, host *models.Host) (client.Client, string, error) {
+	reader, _, err := m.objectHandler.Download(ctx, fmt.Sprintf("%s/%s", c.ID.String(), constants.Kubeconfig))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to download kubeconfig for cluster %s", c.ID)
+	}
+	defer reader.Close()
+	kubeconfig, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to read kubeconfig for cluster %s", c.ID)
+	}
+
+	cl, err := m.clusterCache.GetClient(ctx, c.ID.String(), kubeconfig)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to build client for cluster %s", c.ID)
+	}
+
+	nodeName := swag.StringValue(host.RequestedHostname)
+	if nodeName == "" {
+		nodeName = host.ID.String()
+	}
+	return cl, nodeName, nil
+}
+
+// removeEtcdMember dials a surviving control plane host's etcd endpoint -
+// never nodeName's own, since a scale-down is frequently performed exactly
+// because nodeName is unhealthy or unreachable - and removes nodeName's
+// member through it.
+func (m *Manager) removeEtcdMember(ctx context.Context, cl client.Client, c *common.Cluster, hostID strfmt.UUID, nodeName string) error {
+	var secret corev1.Secret
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: etcdNamespace, Name: etcdClientSecretName}, &secret); err != nil {
+		return errors.Wrapf(err, "failed to fetch etcd client secret")
+	}
+	var ca corev1.ConfigMap
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: etcdNamespace, Name: etcdCAConfigMapName}, &ca); err != nil {
+		return errors.Wrapf(err, "failed to fetch etcd CA configmap")
+	}
+
+	survivor, err := survivingMasterNodeName(c, hostID)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://%s:2379", survivor)
+	return m.etcdRemover.RemoveMember(ctx, []string{endpoint}, secret.Data["tls.crt"], secret.Data["tls.key"], []byte(ca.Data["ca-bundle.crt"]), nodeName)
+}
+
+// survivingMasterNodeName returns the Node name of a control plane host in c
+// other than hostID, so removeEtcdMember has a peer to dial that will still
+// be reachable once hostID's own etcd member is removed.
+func survivingMasterNodeName(c *common.Cluster, hostID strfmt.UUID) (string, error) {
+	for _, h := range c.Hosts {
+		if h.Role != models.HostRoleMaster || h.ID.String() == hostID.String() {
+			continue
+		}
+		if name := swag.StringValue(h.RequestedHostname); name != "" {
+			return name, nil
+		}
+		return h.ID.String(), nil
+	}
+	return "", errors.Errorf("no surviving control plane host found to resolve an etcd endpoint")
+}
+
+func findHostByID(c *common.Cluster, hostID strfmt.UUID) *models.Host {
+	for _, h := range c.Hosts {
+		if h.ID != nil && h.ID.String() == hostID.String() {
+			return h
+		}
+	}
+	return nil
+}
+
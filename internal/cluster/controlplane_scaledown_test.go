@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+)
+
+var _ = Describe("RemoveControlPlaneHost guards", func() {
+	m := &Manager{}
+
+	newMaster := func(id strfmt.UUID) *models.Host {
+		return &models.Host{ID: &id, Role: models.HostRoleMaster}
+	}
+
+	It("rejects single-node clusters outright", func() {
+		hostID := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		c := &common.Cluster{}
+		c.HighAvailabilityMode = swag.String(models.ClusterHighAvailabilityModeNone)
+		c.Hosts = []*models.Host{newMaster(hostID)}
+
+		err := m.RemoveControlPlaneHost(context.Background(), c, hostID, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("single-node"))
+	})
+
+	It("rejects a host ID that isn't part of the cluster", func() {
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{newMaster(strfmt.UUID("11111111-1111-1111-1111-111111111111"))}
+
+		err := m.RemoveControlPlaneHost(context.Background(), c, strfmt.UUID("22222222-2222-2222-2222-222222222222"), nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not found"))
+	})
+
+	It("rejects a worker host", func() {
+		hostID := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{{ID: &hostID, Role: models.HostRoleWorker}}
+
+		err := m.RemoveControlPlaneHost(context.Background(), c, hostID, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not a control plane host"))
+	})
+
+	It("rejects removal when fewer than the minimum masters would remain", func() {
+		target := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{
+			newMaster(target),
+			newMaster(strfmt.UUID("22222222-2222-2222-2222-222222222222")),
+			newMaster(strfmt.UUID("33333333-3333-3333-3333-333333333333")),
+		}
+
+		err := m.RemoveControlPlaneHost(context.Background(), c, target, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("quorum"))
+	})
+})
+
+var _ = Describe("survivingMasterNodeName", func() {
+	It("picks a master other than the one being removed, not the one being removed", func() {
+		target := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		survivorID := strfmt.UUID("22222222-2222-2222-2222-222222222222")
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{
+			{ID: &target, Role: models.HostRoleMaster, RequestedHostname: swag.String("removed-master")},
+			{ID: &survivorID, Role: models.HostRoleMaster, RequestedHostname: swag.String("surviving-master")},
+		}
+
+		name, err := survivingMasterNodeName(c, target)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("surviving-master"))
+	})
+
+	It("falls back to the host ID when RequestedHostname is unset", func() {
+		target := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		survivorID := strfmt.UUID("22222222-2222-2222-2222-222222222222")
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{
+			{ID: &target, Role: models.HostRoleMaster},
+			{ID: &survivorID, Role: models.HostRoleMaster},
+		}
+
+		name, err := survivingMasterNodeName(c, target)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal(survivorID.String()))
+	})
+
+	It("errors when no other control plane host is present", func() {
+		target := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		c := &common.Cluster{}
+		c.Hosts = []*models.Host{{ID: &target, Role: models.HostRoleMaster}}
+
+		_, err := survivingMasterNodeName(c, target)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"github.com/go-openapi/strfmt"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+)
+
+// minSpreadFailureDomains is the failure-domain count at which masters stop
+// being treated as a single, co-located group: below it,
+// AddSchedulableMastersManifest still runs the way a single-AZ cluster
+// always has; at or above it, AddTopologySpreadManifest takes over spreading
+// control-plane and workload pods across the domains masters actually span.
+const minSpreadFailureDomains = 3
+
+// masterFailureDomainCount returns how many of cluster.FailureDomains
+// contain at least one of its current master hosts, following the OCI
+// provider's AvailabilityDomains map in keying each domain by name with its
+// own member host list, so GenerateAdditionalManifests can gate both the
+// schedulable-masters and topology-spread manifests off the same count.
+func masterFailureDomainCount(cluster *common.Cluster) int {
+	masterIDs := make(map[strfmt.UUID]bool)
+	for _, h := range cluster.Hosts {
+		if h.Role == models.HostRoleMaster {
+			masterIDs[*h.ID] = true
+		}
+	}
+
+	count := 0
+	for _, domain := range cluster.FailureDomains {
+		for _, hostID := range domain.HostIDs {
+			if masterIDs[hostID] {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"github.com/go-openapi/strfmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+)
+
+var _ = Describe("masterFailureDomainCount", func() {
+	master := func(id strfmt.UUID) *models.Host {
+		return &models.Host{ID: &id, Role: models.HostRoleMaster}
+	}
+	worker := func(id strfmt.UUID) *models.Host {
+		return &models.Host{ID: &id, Role: models.HostRoleWorker}
+	}
+
+	It("counts only domains that contain at least one master", func() {
+		m1, m2, m3 := strfmt.UUID("m1"), strfmt.UUID("m2"), strfmt.UUID("m3")
+		w1 := strfmt.UUID("w1")
+		cluster := &common.Cluster{}
+		cluster.Hosts = []*models.Host{master(m1), master(m2), master(m3), worker(w1)}
+		cluster.FailureDomains = map[string]common.FailureDomain{
+			"zone-a": {Name: "zone-a", HostIDs: []strfmt.UUID{m1}},
+			"zone-b": {Name: "zone-b", HostIDs: []strfmt.UUID{m2, w1}},
+			"zone-c": {Name: "zone-c", HostIDs: []strfmt.UUID{w1}},
+		}
+
+		Expect(masterFailureDomainCount(cluster)).To(Equal(2))
+	})
+
+	It("returns 0 when no failure domains have been recorded", func() {
+		m1 := strfmt.UUID("m1")
+		cluster := &common.Cluster{}
+		cluster.Hosts = []*models.Host{master(m1)}
+
+		Expect(masterFailureDomainCount(cluster)).To(Equal(0))
+	})
+})
@@ -0,0 +1,144 @@
+// Package healthcheck probes an installed cluster's kube-apiserver on a
+// per-cluster exponential backoff schedule, decoupled from the main
+// cluster-monitor loop so a large fleet of installed clusters probing at
+// once can't self-throttle into multi-second latencies: each Prober caps
+// its own concurrent dials and every cluster gets its own client-side
+// rate-limited REST client.
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	minBackoff = 30 * time.Second
+	maxBackoff = 5 * time.Minute
+
+	probeQPS   = 5
+	probeBurst = 10
+
+	// HealthCheckOKReason and HealthCheckFailReason are the Reasons recorded
+	// on the HealthCheck condition for a successful and failed probe,
+	// respectively.
+	HealthCheckOKReason   = "HealthCheckOK"
+	HealthCheckFailReason = "HealthCheckFail"
+)
+
+// Result is the outcome of a single probe.
+type Result struct {
+	Healthy bool
+	Reason  string
+	Message string
+}
+
+// clusterBackoff tracks one cluster's consecutive-failure backoff state.
+type clusterBackoff struct {
+	nextAttempt time.Time
+	interval    time.Duration
+}
+
+// Prober probes installed clusters' kube-apiservers, bounded by a global
+// worker pool so the number of concurrent dials is independent of the main
+// monitor loop's batch size.
+type Prober struct {
+	mu      sync.Mutex
+	backoff map[string]*clusterBackoff
+	sem     chan struct{}
+}
+
+// NewProber returns a Prober that runs at most maxConcurrent probes at a
+// time.
+func NewProber(maxConcurrent int) *Prober {
+	return &Prober{
+		backoff: make(map[string]*clusterBackoff),
+		sem:     make(chan struct{}, maxConcurrent),
+	}
+}
+
+// ShouldProbe reports whether clusterID's backoff window has elapsed.
+func (p *Prober) ShouldProbe(clusterID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, ok := p.backoff[clusterID]
+	return !ok || !time.Now().Before(b.nextAttempt)
+}
+
+// Probe dials kubeconfig's kube-apiserver (GET /readyz then
+// GET /api/v1/nodes?limit=1) through a per-cluster REST client with
+// explicit QPS/Burst, and records the outcome against clusterID's backoff
+// schedule: failures double the backoff interval up to maxBackoff, and a
+// success resets it.
+func (p *Prober) Probe(ctx context.Context, clusterID string, kubeconfig []byte) Result {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return p.recordFailure(clusterID, fmt.Sprintf("failed to parse kubeconfig: %s", err.Error()))
+	}
+	cfg.QPS = probeQPS
+	cfg.Burst = probeBurst
+	cfg.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(probeQPS, probeBurst)
+
+	httpClient, err := rest.HTTPClientFor(cfg)
+	if err != nil {
+		return p.recordFailure(clusterID, fmt.Sprintf("failed to build http client: %s", err.Error()))
+	}
+
+	if err := probeEndpoint(ctx, httpClient, cfg.Host+"/readyz"); err != nil {
+		return p.recordFailure(clusterID, err.Error())
+	}
+	if err := probeEndpoint(ctx, httpClient, cfg.Host+"/api/v1/nodes?limit=1"); err != nil {
+		return p.recordFailure(clusterID, err.Error())
+	}
+
+	p.recordSuccess(clusterID)
+	return Result{Healthy: true, Reason: HealthCheckOKReason, Message: "kube-apiserver responded to readyz and nodes probes"}
+}
+
+func probeEndpoint(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Prober) recordFailure(clusterID, message string) Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, ok := p.backoff[clusterID]
+	if !ok {
+		b = &clusterBackoff{interval: minBackoff}
+	} else {
+		b.interval *= 2
+		if b.interval > maxBackoff {
+			b.interval = maxBackoff
+		}
+	}
+	b.nextAttempt = time.Now().Add(b.interval)
+	p.backoff[clusterID] = b
+	return Result{Healthy: false, Reason: HealthCheckFailReason, Message: message}
+}
+
+func (p *Prober) recordSuccess(clusterID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.backoff, clusterID)
+}
@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prober backoff", func() {
+	It("allows an immediate probe for a cluster it has never seen", func() {
+		p := NewProber(1)
+		Expect(p.ShouldProbe("cluster-a")).To(BeTrue())
+	})
+
+	It("blocks further probes until the backoff window elapses after a failure", func() {
+		p := NewProber(1)
+		p.recordFailure("cluster-a", "dial timeout")
+		Expect(p.ShouldProbe("cluster-a")).To(BeFalse())
+	})
+
+	It("doubles the backoff interval on consecutive failures up to the cap", func() {
+		p := NewProber(1)
+		p.recordFailure("cluster-a", "dial timeout")
+		first := p.backoff["cluster-a"].interval
+		Expect(first).To(Equal(minBackoff))
+
+		p.recordFailure("cluster-a", "dial timeout")
+		Expect(p.backoff["cluster-a"].interval).To(Equal(minBackoff * 2))
+
+		for i := 0; i < 10; i++ {
+			p.recordFailure("cluster-a", "dial timeout")
+		}
+		Expect(p.backoff["cluster-a"].interval).To(Equal(maxBackoff))
+	})
+
+	It("clears the backoff state on success", func() {
+		p := NewProber(1)
+		p.recordFailure("cluster-a", "dial timeout")
+		p.recordSuccess("cluster-a")
+		Expect(p.ShouldProbe("cluster-a")).To(BeTrue())
+		Expect(p.backoff).ToNot(HaveKey("cluster-a"))
+	})
+})
@@ -0,0 +1,208 @@
+package cluster
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kennygrant/sanitize"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/internal/host/hostutil"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/pkg/s3wrapper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// logArchiveListPageSize bounds how many keys CreateTarredClusterLogs pulls
+// from ListObjectsByPrefixPaginated per page, so a cluster with thousands of
+// host log objects is walked incrementally instead of materializing the
+// whole listing in one S3 response.
+const logArchiveListPageSize = 500
+
+// logTarEntry pairs a source S3 key with the name it should carry inside
+// the tar archive, so the worker pool in logArchiveWriter can build entries
+// independently of each other without re-deriving the name per read.
+type logTarEntry struct {
+	key     string
+	tarName string
+}
+
+// tarredLogsFileName returns the destination S3 key for c's log archive,
+// honoring the gzip suffix the caller was configured with.
+func tarredLogsFileName(c *common.Cluster, gzipOutput bool) string {
+	ext := "tar"
+	if gzipOutput {
+		ext = "tar.gz"
+	}
+	return fmt.Sprintf("%s/logs/cluster_logs.%s", c.ID, ext)
+}
+
+// buildLogTarEntries applies CreateTarredClusterLogs' per-host filename
+// sanitization to every key under the cluster's logs prefix, skipping the
+// archive's own destination key if a previous run left one behind.
+func buildLogTarEntries(c *common.Cluster, destFileName string, keys []string) []logTarEntry {
+	entries := make([]logTarEntry, 0, len(keys))
+	for _, key := range keys {
+		if key == destFileName {
+			continue
+		}
+		entries = append(entries, logTarEntry{key: key, tarName: tarNameForKey(c, key)})
+	}
+	return entries
+}
+
+// tarNameForKey reproduces CreateTarredClusterLogs' long-standing naming
+// rule unchanged: a key whose parent directory is a host ID is renamed to
+// "<cluster>_<role>_<hostname>.tar.gz", anything else is renamed to
+// "<parent>_<basename>".
+func tarNameForKey(c *common.Cluster, key string) string {
+	parts := strings.Split(key, "/")
+	if len(parts) <= 1 {
+		return key
+	}
+
+	hostID := parts[len(parts)-2]
+	if _, err := uuid.Parse(hostID); err != nil {
+		return fmt.Sprintf("%s_%s", parts[len(parts)-2], parts[len(parts)-1])
+	}
+
+	for _, host := range c.Hosts {
+		if host.ID.String() != hostID {
+			continue
+		}
+		role := string(host.Role)
+		if host.Bootstrap {
+			role = string(models.HostRoleBootstrap)
+		}
+		return fmt.Sprintf("%s_%s_%s.tar.gz", sanitize.Name(c.Name), role, sanitize.Name(hostutil.GetHostnameForMsg(host)))
+	}
+	return key
+}
+
+// logArchiveWriter downloads each logTarEntry's source object and writes it
+// into a shared tar.Writer, bounded by a worker pool of size concurrency -
+// so the service never holds more than concurrency source objects in memory
+// at once, unlike the single TarAwsFiles call this replaces.
+type logArchiveWriter struct {
+	objectHandler s3wrapper.API
+	concurrency   int
+	log           logrus.FieldLogger
+}
+
+// writeAll fans entries out across the worker pool and serializes their tar
+// header+body writes through mu, since tar.Writer isn't safe for concurrent
+// use. It stops issuing new downloads as soon as ctx is canceled - e.g. the
+// download handler's client disconnected - and returns the first error any
+// worker hit.
+func (w *logArchiveWriter) writeAll(ctx context.Context, tw *tar.Writer, entries []logTarEntry) error {
+	var mu sync.Mutex
+	sem := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(entries))
+
+	for _, entry := range entries {
+		entry := entry
+		select {
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := w.writeEntry(ctx, tw, &mu, entry); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *logArchiveWriter) writeEntry(ctx context.Context, tw *tar.Writer, mu *sync.Mutex, entry logTarEntry) error {
+	reader, contentLength, err := w.objectHandler.Download(ctx, entry.key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download %s", entry.key)
+	}
+	defer reader.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := tw.WriteHeader(&tar.Header{Name: entry.tarName, Size: contentLength, Mode: 0644}); err != nil {
+		return errors.Wrapf(err, "failed to write tar header for %s", entry.tarName)
+	}
+	if _, err := io.Copy(tw, reader); err != nil {
+		return errors.Wrapf(err, "failed to write tar body for %s", entry.tarName)
+	}
+	return nil
+}
+
+// streamTarredClusterLogs drives the whole pipeline: it lists entries,
+// streams them into a tar (optionally gzip-compressed) writer, and uploads
+// that stream to destFileName as it's produced, so the full archive is
+// never buffered on this service's disk or memory. ctx is honored
+// end-to-end, so canceling it - e.g. because the requesting client
+// disconnected - stops in-flight downloads and the upload together.
+func (m *Manager) streamTarredClusterLogs(ctx context.Context, c *common.Cluster, objectHandler s3wrapper.API, destFileName string, entries []logTarEntry) error {
+	pr, pw := io.Pipe()
+	uploadCtx, cancelUpload := context.WithCancel(ctx)
+	defer cancelUpload()
+
+	uploadErrCh := make(chan error, 1)
+	go func() {
+		_, uploadErr := objectHandler.UploadStream(uploadCtx, pr, destFileName)
+		uploadErrCh <- uploadErr
+		pr.CloseWithError(uploadErr)
+	}()
+
+	writeErr := func() error {
+		var out io.Writer = pw
+		var gzw *gzip.Writer
+		if m.LogsArchiveGzip {
+			gzw = gzip.NewWriter(pw)
+			out = gzw
+		}
+		tw := tar.NewWriter(out)
+
+		archiver := &logArchiveWriter{objectHandler: objectHandler, concurrency: m.LogsArchiveConcurrency, log: m.log}
+		if err := archiver.writeAll(ctx, tw, entries); err != nil {
+			return err
+		}
+		if err := tw.Close(); err != nil {
+			return errors.Wrap(err, "failed to close tar writer")
+		}
+		if gzw != nil {
+			if err := gzw.Close(); err != nil {
+				return errors.Wrap(err, "failed to close gzip writer")
+			}
+		}
+		return nil
+	}()
+
+	if writeErr != nil {
+		pw.CloseWithError(writeErr)
+		<-uploadErrCh
+		return writeErr
+	}
+	if err := pw.Close(); err != nil {
+		return err
+	}
+	return <-uploadErrCh
+}
@@ -0,0 +1,181 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// initialBackoff and maxBackoff bound the exponential backoff applied
+	// to a retryable task failure's next RunAfter.
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 30 * time.Minute
+
+	// leaseBatchSize caps how many due manifests reconcileOnce pulls per
+	// poll, so one Actuator tick can't monopolize the DB on a backlog.
+	leaseBatchSize = 50
+)
+
+// ShardScheduler decides whether this Actuator replica owns clusterID,
+// letting multiple actuator replicas divide a fleet deterministically.
+// monitor.MonitorScheduler already satisfies this interface, so the
+// cluster package wires the same scheduler ClusterMonitoring uses rather
+// than standing up a second, parallel sharding scheme.
+type ShardScheduler interface {
+	OwnsCluster(clusterID string) bool
+}
+
+// ClusterClientResolver resolves clusterID's live controller-runtime
+// client, the same capability controlplane_scaledown.go's
+// targetClusterClientForHost provides for control-plane scale-down.
+type ClusterClientResolver interface {
+	ResolveClient(ctx context.Context, clusterID string) (client.Client, error)
+}
+
+// Actuator leases due Manifest rows this replica owns, runs each through
+// its registered task, and persists the resulting state transition -
+// succeeding, retrying with backoff, or failing terminally - the reconciler
+// ARO's MIMO names its own actuator after.
+type Actuator struct {
+	db           *gorm.DB
+	log          logrus.FieldLogger
+	registry     *Registry
+	scheduler    ShardScheduler
+	resolver     ClusterClientResolver
+	pollInterval time.Duration
+}
+
+// NewActuator returns an Actuator that polls for due manifests every
+// pollInterval.
+func NewActuator(db *gorm.DB, log logrus.FieldLogger, registry *Registry, scheduler ShardScheduler, resolver ClusterClientResolver, pollInterval time.Duration) *Actuator {
+	return &Actuator{db: db, log: log, registry: registry, scheduler: scheduler, resolver: resolver, pollInterval: pollInterval}
+}
+
+// Run polls for and executes due manifests every pollInterval until ctx is
+// canceled.
+func (a *Actuator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.reconcileOnce(ctx); err != nil {
+				a.log.WithError(err).Warn("maintenance actuator reconcile failed")
+			}
+		}
+	}
+}
+
+// reconcileOnce leases this replica's share of due, Pending manifests and
+// runs each to completion.
+func (a *Actuator) reconcileOnce(ctx context.Context) error {
+	var due []Manifest
+	err := a.db.Where("state = ? AND run_after <= ?", ManifestStatePending, time.Now()).
+		Order("priority desc, run_after asc").
+		Limit(leaseBatchSize).
+		Find(&due).Error
+	if err != nil {
+		return errors.Wrap(err, "failed to list due maintenance manifests")
+	}
+
+	for _, m := range due {
+		if !a.scheduler.OwnsCluster(m.ClusterID) {
+			continue
+		}
+		a.runOne(ctx, m)
+	}
+	return nil
+}
+
+func (a *Actuator) runOne(ctx context.Context, m Manifest) {
+	if err := a.lease(m); err != nil {
+		a.log.WithError(err).Warnf("failed to lease maintenance manifest %s", m.ID)
+		return
+	}
+
+	task, ok := a.registry.Get(m.TaskID)
+	if !ok {
+		a.fail(m, errors.Errorf("no task registered for %s", m.TaskID), false)
+		return
+	}
+
+	cl, err := a.resolver.ResolveClient(ctx, m.ClusterID)
+	if err != nil {
+		a.fail(m, err, true)
+		return
+	}
+
+	msg, retry, err := task.Run(ctx, ClusterDoc{ClusterID: m.ClusterID, Client: cl})
+	if err != nil {
+		a.fail(m, err, retry)
+		return
+	}
+	a.succeed(m, msg)
+}
+
+// errLeaseLost means the WHERE id = ? AND state = ? update touched zero
+// rows - some other replica already leased or otherwise transitioned m,
+// most likely during the brief dual-leader window a leader-election
+// handoff can open. runOne treats it the same as any other lease failure:
+// log and skip, leaving m for whoever actually holds it.
+var errLeaseLost = errors.New("maintenance manifest lease lost to another replica")
+
+func (a *Actuator) lease(m Manifest) error {
+	result := a.db.Model(&Manifest{}).Where("id = ? AND state = ?", m.ID, ManifestStatePending).
+		Updates(map[string]interface{}{"state": ManifestStateLeased})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (a *Actuator) succeed(m Manifest, msg string) {
+	if err := a.db.Model(&Manifest{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"state":          ManifestStateSucceeded,
+		"result_payload": msg,
+	}).Error; err != nil {
+		a.log.WithError(err).Warnf("failed to persist success for maintenance manifest %s", m.ID)
+	}
+}
+
+// fail records taskErr against m: a retryable failure goes back to Pending
+// with its RunAfter pushed out by backoffFor(m.RetryCount), a terminal one
+// moves straight to Failed.
+func (a *Actuator) fail(m Manifest, taskErr error, retry bool) {
+	state := ManifestStateFailed
+	runAfter := time.Now()
+	if retry {
+		state = ManifestStatePending
+		runAfter = time.Now().Add(backoffFor(m.RetryCount))
+	}
+	if err := a.db.Model(&Manifest{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"state":       state,
+		"retry_count": m.RetryCount + 1,
+		"last_error":  taskErr.Error(),
+		"run_after":   runAfter,
+	}).Error; err != nil {
+		a.log.WithError(err).Warnf("failed to persist failure for maintenance manifest %s", m.ID)
+	}
+}
+
+// backoffFor doubles initialBackoff per retry, capped at maxBackoff.
+func backoffFor(retryCount int) time.Duration {
+	d := initialBackoff
+	for i := 0; i < retryCount && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
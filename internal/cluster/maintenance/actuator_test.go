@@ -0,0 +1,36 @@
+package maintenance
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("backoffFor", func() {
+	It("doubles with each retry", func() {
+		Expect(backoffFor(0)).To(Equal(initialBackoff))
+		Expect(backoffFor(1)).To(Equal(2 * initialBackoff))
+		Expect(backoffFor(2)).To(Equal(4 * initialBackoff))
+	})
+
+	It("never exceeds maxBackoff", func() {
+		Expect(backoffFor(100)).To(Equal(maxBackoff))
+	})
+})
+
+var _ = Describe("Registry", func() {
+	It("returns a registered task by its own ID", func() {
+		r := NewRegistry()
+		task := NewVerifyAPIReachableTask()
+		r.Register(task)
+
+		got, ok := r.Get(VerifyAPIReachableTaskID)
+		Expect(ok).To(BeTrue())
+		Expect(got.ID()).To(Equal(VerifyAPIReachableTaskID))
+	})
+
+	It("reports no task for an unregistered ID", func() {
+		r := NewRegistry()
+		_, ok := r.Get("not-a-task")
+		Expect(ok).To(BeFalse())
+	})
+})
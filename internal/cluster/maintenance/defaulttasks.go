@@ -0,0 +1,70 @@
+package maintenance
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VerifyAPIReachableTaskID and RefreshPullSecretTaskID are the default set
+// of manifests TransformClusterToDay2 can enqueue on transition.
+const (
+	VerifyAPIReachableTaskID = "verify-api-reachable"
+	RefreshPullSecretTaskID  = "refresh-pull-secret"
+)
+
+// verifyAPIReachableTask confirms the target cluster's kube-apiserver still
+// answers, by listing a single Node through its cached client - the same
+// check healthcheck.Prober already performs over raw HTTP, reused here
+// through the controller-runtime client ClusterDoc carries instead.
+type verifyAPIReachableTask struct{}
+
+// NewVerifyAPIReachableTask returns the VerifyAPIReachableTaskID task.
+func NewVerifyAPIReachableTask() Interface {
+	return verifyAPIReachableTask{}
+}
+
+func (verifyAPIReachableTask) ID() string { return VerifyAPIReachableTaskID }
+
+func (verifyAPIReachableTask) Run(ctx context.Context, clusterDoc ClusterDoc) (string, bool, error) {
+	var nodes corev1.NodeList
+	if err := clusterDoc.Client.List(ctx, &nodes, client.Limit(1)); err != nil {
+		return "", true, errors.Wrapf(err, "kube-apiserver for cluster %s did not respond", clusterDoc.ClusterID)
+	}
+	return "kube-apiserver responded to a Node list", false, nil
+}
+
+// refreshPullSecretTaskID's Secret/namespace mirror where OpenShift expects
+// the cluster-wide pull secret to live.
+const (
+	pullSecretNamespace = "openshift-config"
+	pullSecretName      = "pull-secret"
+)
+
+// refreshPullSecretTask confirms the cluster-wide pull secret Secret is
+// still present and non-empty, flagging drift (e.g. an operator or admin
+// having cleared it) for the caller to remediate rather than rewriting its
+// contents itself - this subsystem has no independent source of truth for
+// what the pull secret should contain.
+type refreshPullSecretTask struct{}
+
+// NewRefreshPullSecretTask returns the RefreshPullSecretTaskID task.
+func NewRefreshPullSecretTask() Interface {
+	return refreshPullSecretTask{}
+}
+
+func (refreshPullSecretTask) ID() string { return RefreshPullSecretTaskID }
+
+func (refreshPullSecretTask) Run(ctx context.Context, clusterDoc ClusterDoc) (string, bool, error) {
+	var secret corev1.Secret
+	key := client.ObjectKey{Namespace: pullSecretNamespace, Name: pullSecretName}
+	if err := clusterDoc.Client.Get(ctx, key, &secret); err != nil {
+		return "", true, errors.Wrapf(err, "failed to fetch pull secret for cluster %s", clusterDoc.ClusterID)
+	}
+	if len(secret.Data[".dockerconfigjson"]) == 0 {
+		return "", false, errors.Errorf("pull secret for cluster %s is present but empty", clusterDoc.ClusterID)
+	}
+	return "pull secret verified present", false, nil
+}
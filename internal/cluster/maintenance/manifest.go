@@ -0,0 +1,78 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ManifestState is a Manifest's position in the Actuator's lease/run/retry
+// lifecycle.
+type ManifestState string
+
+const (
+	ManifestStatePending   ManifestState = "Pending"
+	ManifestStateLeased    ManifestState = "Leased"
+	ManifestStateSucceeded ManifestState = "Succeeded"
+	ManifestStateFailed    ManifestState = "Failed"
+	ManifestStateCancelled ManifestState = "Cancelled"
+)
+
+// Manifest is one queued or executed maintenance task run against a
+// cluster. It's persisted so the Actuator survives restarts: RunAfter,
+// RetryCount and State drive reconcileOnce's lease loop, and Deadline lets
+// a manifest give up as Failed instead of retrying forever.
+type Manifest struct {
+	ID            string `gorm:"primaryKey"`
+	ClusterID     string `gorm:"index"`
+	TaskID        string
+	State         ManifestState `gorm:"index"`
+	Priority      int
+	RunAfter      time.Time
+	Deadline      *time.Time
+	RetryCount    int
+	LastError     string
+	ResultPayload string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName pins Manifest to a name that reads as what it is in a DB full
+// of cluster/host/event tables, rather than gorm's default "manifests".
+func (Manifest) TableName() string {
+	return "maintenance_manifests"
+}
+
+// Enqueue persists a new Pending manifest for clusterID/taskID, due
+// immediately, and returns its generated ID.
+func Enqueue(db *gorm.DB, clusterID, taskID string, priority int) (string, error) {
+	m := Manifest{
+		ID:        uuid.New().String(),
+		ClusterID: clusterID,
+		TaskID:    taskID,
+		State:     ManifestStatePending,
+		Priority:  priority,
+		RunAfter:  time.Now(),
+	}
+	if err := db.Create(&m).Error; err != nil {
+		return "", err
+	}
+	return m.ID, nil
+}
+
+// List returns clusterID's manifests, most recently created first.
+func List(db *gorm.DB, clusterID string) ([]Manifest, error) {
+	var manifests []Manifest
+	err := db.Where("cluster_id = ?", clusterID).Order("created_at desc").Find(&manifests).Error
+	return manifests, err
+}
+
+// Cancel marks manifestID Cancelled, provided it hasn't already finished
+// running - a manifest the Actuator already moved to Succeeded/Failed is
+// left alone.
+func Cancel(db *gorm.DB, manifestID string) error {
+	return db.Model(&Manifest{}).
+		Where("id = ? AND state IN ?", manifestID, []ManifestState{ManifestStatePending, ManifestStateLeased}).
+		Updates(map[string]interface{}{"state": ManifestStateCancelled}).Error
+}
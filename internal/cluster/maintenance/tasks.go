@@ -0,0 +1,71 @@
+// Package maintenance runs pluggable, asynchronous maintenance tasks
+// (cert rotation, mirror re-sync, CA bundle refresh, forced CVO resumes,
+// ...) against installed day-2 clusters, modeled on ARO's MIMO: tasks are
+// registered by a stable ID in a Registry, queued as persisted Manifest
+// rows, and driven to completion by an Actuator that leases due manifests,
+// runs their task, and records the resulting state transition with
+// exponential backoff on retryable failures.
+package maintenance
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterDoc is the live view of a cluster a task runs against: its ID plus
+// a controller-runtime client already resolved from its stored kubeconfig,
+// so a task never has to parse or cache kubeconfigs itself.
+type ClusterDoc struct {
+	ClusterID string
+	Client    client.Client
+}
+
+// Interface is one pluggable maintenance task. Run reports whether the
+// Actuator should retry a failed run (with backoff) or give up on it as
+// terminal.
+type Interface interface {
+	ID() string
+	Run(ctx context.Context, clusterDoc ClusterDoc) (msg string, retry bool, err error)
+}
+
+// Registry is a task-ID-keyed lookup of registered tasks, so the Actuator
+// can resolve a Manifest's TaskID to the code that runs it without a type
+// switch that grows with every new task.
+type Registry struct {
+	mu    sync.RWMutex
+	tasks map[string]Interface
+}
+
+// NewRegistry returns an empty task Registry.
+func NewRegistry() *Registry {
+	return &Registry{tasks: make(map[string]Interface)}
+}
+
+// Register adds task under its own ID, replacing any task previously
+// registered under the same ID.
+func (r *Registry) Register(task Interface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[task.ID()] = task
+}
+
+// Get returns the task registered under id, if any.
+func (r *Registry) Get(id string) (Interface, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tasks[id]
+	return t, ok
+}
+
+// IDs returns every currently registered task ID, in no particular order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.tasks))
+	for id := range r.tasks {
+		ids = append(ids, id)
+	}
+	return ids
+}
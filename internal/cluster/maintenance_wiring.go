@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/openshift/assisted-service/internal/cluster/maintenance"
+	"github.com/openshift/assisted-service/internal/constants"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultDay2MaintenanceTasks are the manifests TransformClusterToDay2
+// enqueues on every transition, so a cluster starts its add-hosts life with
+// a standing check that its API is reachable and its pull secret intact.
+var defaultDay2MaintenanceTasks = []string{
+	maintenance.VerifyAPIReachableTaskID,
+	maintenance.RefreshPullSecretTaskID,
+}
+
+// defaultMaintenanceTaskPriority is the priority newly enqueued default
+// manifests get; callers of EnqueueMaintenanceManifest can pass a higher
+// value for manifests that should jump the queue.
+const defaultMaintenanceTaskPriority = 0
+
+// managerClusterClientResolver implements maintenance.ClusterClientResolver
+// against m.objectHandler/m.clusterCache - the same kubeconfig
+// download-then-cache pattern controlplane_scaledown.go's
+// targetClusterClientForHost already uses for control-plane scale-down.
+type managerClusterClientResolver struct {
+	m *Manager
+}
+
+func (r managerClusterClientResolver) ResolveClient(ctx context.Context, clusterID string) (client.Client, error) {
+	reader, _, err := r.m.objectHandler.Download(ctx, fmt.Sprintf("%s/%s", clusterID, constants.Kubeconfig))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download kubeconfig for cluster %s", clusterID)
+	}
+	defer reader.Close()
+	kubeconfig, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read kubeconfig for cluster %s", clusterID)
+	}
+	return r.m.clusterCache.GetClient(ctx, clusterID, kubeconfig)
+}
+
+// StartMaintenanceActuator launches the maintenance Actuator's poll loop in
+// the background until ctx is canceled. Callers run this once per process,
+// the same way the leader-elected monitor loop is started elsewhere.
+func (m *Manager) StartMaintenanceActuator(ctx context.Context) {
+	go m.maintenanceActuator.Run(ctx)
+}
+
+// EnqueueMaintenanceManifest queues taskID to run against clusterID at the
+// given priority, returning the new manifest's ID.
+func (m *Manager) EnqueueMaintenanceManifest(clusterID strfmt.UUID, taskID string, priority int) (string, error) {
+	return maintenance.Enqueue(m.db, clusterID.String(), taskID, priority)
+}
+
+// ListMaintenanceManifests returns clusterID's maintenance manifests, most
+// recently created first.
+func (m *Manager) ListMaintenanceManifests(clusterID strfmt.UUID) ([]maintenance.Manifest, error) {
+	return maintenance.List(m.db, clusterID.String())
+}
+
+// CancelMaintenanceManifest cancels manifestID, provided the Actuator
+// hasn't already run it to completion.
+func (m *Manager) CancelMaintenanceManifest(manifestID string) error {
+	return maintenance.Cancel(m.db, manifestID)
+}
+
+// enqueueDefaultDay2MaintenanceManifests queues defaultDay2MaintenanceTasks
+// against clusterID, logging (rather than failing the caller's transition
+// on) any individual enqueue failure.
+func (m *Manager) enqueueDefaultDay2MaintenanceManifests(clusterID strfmt.UUID) {
+	for _, taskID := range defaultDay2MaintenanceTasks {
+		if _, err := m.EnqueueMaintenanceManifest(clusterID, taskID, defaultMaintenanceTaskPriority); err != nil {
+			m.log.WithError(err).Warnf("Failed to enqueue default maintenance manifest %s for cluster %s", taskID, clusterID)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/internal/network"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// majorityGroupsCache remembers, per cluster, the connectivitySignature the
+// last successful setConnectivityMajorityGroupsForClusterInternal run
+// computed against - so a monitor tick whose hosts reported nothing new can
+// short-circuit before the per-CIDR computation, JSON marshal and DB UPDATE
+// that dominate its cost on large clusters.
+type majorityGroupsCache struct {
+	mu   sync.Mutex
+	sigs map[string]string
+}
+
+func newMajorityGroupsCache() *majorityGroupsCache {
+	return &majorityGroupsCache{sigs: make(map[string]string)}
+}
+
+func (c *majorityGroupsCache) signatureUnchanged(clusterID, sig string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sig != "" && c.sigs[clusterID] == sig
+}
+
+func (c *majorityGroupsCache) record(clusterID, sig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sigs[clusterID] = sig
+}
+
+// connectivitySignature hashes hosts' IDs and connectivity reports, in the
+// order they're passed, into a single comparable string. Callers always
+// pass hosts already sorted by ID (the same order
+// setConnectivityMajorityGroupsForClusterInternal has long required for a
+// stable marshalled majority-group string), so two calls produce the same
+// signature iff both the host set and every host's reported connectivity
+// are unchanged.
+func connectivitySignature(hosts []*models.Host) string {
+	h := sha256.New()
+	for _, host := range hosts {
+		fmt.Fprintf(h, "%s:%s\n", host.ID.String(), host.Connectivity)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// majorityGroupJob is one CIDR or address family's majority-group
+// computation, named by key so computeMajorityGroupsParallel's worker pool
+// can report per-job failures without losing track of which group they
+// belong to.
+type majorityGroupJob struct {
+	key     string
+	compute func() ([]strfmt.UUID, error)
+}
+
+// computeMajorityGroupsParallel runs jobs across a worker pool sized to
+// GOMAXPROCS, so a cluster with many CIDRs and address families no longer
+// pays for each one serially on every host inventory change. A job that
+// fails is logged and simply omitted from the result, matching the
+// skip-and-continue behavior the serial implementation had for L2 groups.
+func (m *Manager) computeMajorityGroupsParallel(jobs []majorityGroupJob) map[string][]strfmt.UUID {
+	type result struct {
+		key   string
+		value []strfmt.UUID
+		err   error
+	}
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	resultsCh := make(chan result, len(jobs))
+	var wg sync.WaitGroup
+
+	for _, job := range jobs {
+		job := job
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			value, err := job.compute()
+			resultsCh <- result{key: job.key, value: value, err: err}
+		}()
+	}
+	wg.Wait()
+	close(resultsCh)
+
+	groups := make(map[string][]strfmt.UUID, len(jobs))
+	for res := range resultsCh {
+		if res.err != nil {
+			m.log.WithError(res.err).Warnf("Create majority group for %s", res.key)
+			continue
+		}
+		groups[res.key] = res.value
+	}
+	return groups
+}
+
+// majorityGroupsAllowedStates are the cluster Statuses majority groups are
+// computed in at all, since they only feed pre-install validations. Both
+// the full recompute and the incremental, per-host one enforce this gate.
+var majorityGroupsAllowedStates = []string{
+	models.ClusterStatusPendingForInput,
+	models.ClusterStatusInsufficient,
+	models.ClusterStatusReady,
+}
+
+// majorityGroupJobsForHosts returns one L2 job per CIDR scopeHosts span plus
+// one L3 job per address family - scopeHosts decides which job *keys* need
+// rebuilding, but every job's compute func still considers allHosts as the
+// candidate set, since CreateL2MajorityGroup/CreateL3MajorityGroup need
+// every host sharing a CIDR to decide majority membership. A full recompute
+// passes the same slice for both; UpdateConnectivityMajorityGroupsForHost
+// passes just the one changed host as scopeHosts so it only rebuilds the
+// CIDRs/families that host could have shifted.
+func majorityGroupJobsForHosts(scopeHosts, allHosts []*models.Host, log logrus.FieldLogger) []majorityGroupJob {
+	cidrs := network.GetClusterNetworks(scopeHosts, log)
+	jobs := make([]majorityGroupJob, 0, len(cidrs)+2)
+	for _, cidr := range cidrs {
+		cidr := cidr
+		jobs = append(jobs, majorityGroupJob{
+			key:     cidr,
+			compute: func() ([]strfmt.UUID, error) { return network.CreateL2MajorityGroup(cidr, allHosts) },
+		})
+	}
+	for _, family := range []network.AddressFamily{network.IPv4, network.IPv6} {
+		family := family
+		jobs = append(jobs, majorityGroupJob{
+			key:     family.String(),
+			compute: func() ([]strfmt.UUID, error) { return network.CreateL3MajorityGroup(allHosts, family) },
+		})
+	}
+	return jobs
+}
+
+// persistMajorityGroups marshals majorityGroups, writes it to
+// cluster.ConnectivityMajorityGroups when it changed, and records the
+// MajorityGroupsComputed condition either way - the tail end both
+// setConnectivityMajorityGroupsForClusterInternal and
+// UpdateConnectivityMajorityGroupsForHost share once they've settled on the
+// full set of groups to persist.
+func (m *Manager) persistMajorityGroups(db *gorm.DB, cluster *common.Cluster, majorityGroups map[string][]strfmt.UUID) error {
+	b, err := json.Marshal(&majorityGroups)
+	if err != nil {
+		return common.NewApiError(http.StatusInternalServerError, err)
+	}
+
+	marshalledMajorityGroups := string(b)
+	if marshalledMajorityGroups != cluster.ConnectivityMajorityGroups {
+		if err = db.Model(&common.Cluster{}).Where("id = ?", cluster.ID.String()).Updates(&common.Cluster{
+			Cluster: models.Cluster{
+				ConnectivityMajorityGroups: marshalledMajorityGroups,
+			},
+		}).Error; err != nil {
+			return common.NewApiError(http.StatusInternalServerError, err)
+		}
+		cluster.ConnectivityMajorityGroups = marshalledMajorityGroups
+	}
+
+	SetCondition(cluster, common.ClusterCondition{Type: MajorityGroupsComputedCondition, Status: corev1.ConditionTrue, Reason: "MajorityGroupsComputed", Message: "connectivity majority groups were computed"})
+	if _, err = m.updateConditionsInDB(context.Background(), db, cluster); err != nil {
+		m.log.WithError(err).Warnf("Failed to persist MajorityGroupsComputed condition for cluster %s", cluster.ID.String())
+	}
+	return nil
+}
+
+// UpdateConnectivityMajorityGroupsForHost is
+// setConnectivityMajorityGroupsForClusterInternal's incremental
+// counterpart: a single host's connectivity report changing can only shift
+// the L2 groups of CIDRs that host itself is on, plus the L3 groups of both
+// address families, so it recomputes just those jobs instead of every CIDR
+// in the cluster. Every other key in the previously persisted
+// ConnectivityMajorityGroups is carried over unchanged.
+func (m *Manager) UpdateConnectivityMajorityGroupsForHost(clusterID, hostID strfmt.UUID, db *gorm.DB) error {
+	if db == nil {
+		db = m.db
+	}
+	cluster, err := common.GetClusterFromDBWithHosts(db, clusterID)
+	if err != nil {
+		var statusCode int32 = http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		return common.NewApiError(statusCode, errors.Wrapf(err, "Getting cluster %s", clusterID.String()))
+	}
+
+	// Majority groups only feed pre-install validations, same as the full
+	// recompute - skip outside those states instead of writing groups that
+	// will never be read.
+	if !funk.ContainsString(majorityGroupsAllowedStates, swag.StringValue(cluster.Status)) {
+		return nil
+	}
+
+	var host *models.Host
+	for _, h := range cluster.Hosts {
+		if h.ID.String() == hostID.String() {
+			host = h
+			break
+		}
+	}
+	if host == nil {
+		return common.NewApiError(http.StatusNotFound, errors.Errorf("host %s not found in cluster %s", hostID, clusterID))
+	}
+
+	hosts := cluster.Hosts
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].ID.String() < hosts[j].ID.String()
+	})
+
+	existingGroups := make(map[string][]strfmt.UUID)
+	if cluster.ConnectivityMajorityGroups != "" {
+		if err = json.Unmarshal([]byte(cluster.ConnectivityMajorityGroups), &existingGroups); err != nil {
+			m.log.WithError(err).Warnf("Failed to parse existing connectivity majority groups for cluster %s, falling back to a full recompute", clusterID)
+			return m.setConnectivityMajorityGroupsForClusterInternal(cluster, db)
+		}
+	}
+
+	start := time.Now()
+	jobs := majorityGroupJobsForHosts([]*models.Host{host}, hosts, m.log)
+	for key, value := range m.computeMajorityGroupsParallel(jobs) {
+		existingGroups[key] = value
+	}
+	m.metricAPI.ConnectivityMajorityGroupsComputeDuration(time.Since(start))
+
+	if err = m.persistMajorityGroups(db, cluster, existingGroups); err != nil {
+		return err
+	}
+	m.majorityGroupsCache.record(clusterID.String(), connectivitySignature(hosts))
+	return nil
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"github.com/go-openapi/strfmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/network"
+	"github.com/openshift/assisted-service/models"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("connectivitySignature", func() {
+	newHost := func(id, connectivity string) *models.Host {
+		hostID := strfmt.UUID(id)
+		return &models.Host{ID: &hostID, Connectivity: connectivity}
+	}
+
+	It("is stable across calls for the same hosts", func() {
+		hosts := []*models.Host{newHost("11111111-1111-1111-1111-111111111111", "report-a")}
+		Expect(connectivitySignature(hosts)).To(Equal(connectivitySignature(hosts)))
+	})
+
+	It("changes when a host's connectivity report changes", func() {
+		before := []*models.Host{newHost("11111111-1111-1111-1111-111111111111", "report-a")}
+		after := []*models.Host{newHost("11111111-1111-1111-1111-111111111111", "report-b")}
+		Expect(connectivitySignature(before)).NotTo(Equal(connectivitySignature(after)))
+	})
+
+	It("changes when the host set changes", func() {
+		one := []*models.Host{newHost("11111111-1111-1111-1111-111111111111", "report-a")}
+		two := []*models.Host{
+			newHost("11111111-1111-1111-1111-111111111111", "report-a"),
+			newHost("22222222-2222-2222-2222-222222222222", "report-a"),
+		}
+		Expect(connectivitySignature(one)).NotTo(Equal(connectivitySignature(two)))
+	})
+})
+
+var _ = Describe("majorityGroupJobsForHosts", func() {
+	newHost := func(id string) *models.Host {
+		hostID := strfmt.UUID(id)
+		return &models.Host{ID: &hostID}
+	}
+
+	// UpdateConnectivityMajorityGroupsForHost scopes jobs to the single host
+	// that changed so it only rebuilds keys that host could have shifted,
+	// but every job still has to consider the whole cluster's hosts as its
+	// candidate set - otherwise a one-host scope would collapse every
+	// majority group it touches down to that one host, overwriting the
+	// previously-correct group for every other host sharing it.
+	It("builds one job per address family regardless of how many hosts are in scope", func() {
+		scope := []*models.Host{newHost("11111111-1111-1111-1111-111111111111")}
+		all := []*models.Host{
+			newHost("11111111-1111-1111-1111-111111111111"),
+			newHost("22222222-2222-2222-2222-222222222222"),
+			newHost("33333333-3333-3333-3333-333333333333"),
+		}
+
+		jobs := majorityGroupJobsForHosts(scope, all, logrus.New())
+
+		keys := make([]string, len(jobs))
+		for i, job := range jobs {
+			keys[i] = job.key
+		}
+		Expect(keys).To(ConsistOf(network.IPv4.String(), network.IPv6.String()))
+	})
+})
+
+var _ = Describe("majorityGroupsCache", func() {
+	It("reports unchanged only after a signature has been recorded", func() {
+		c := newMajorityGroupsCache()
+		Expect(c.signatureUnchanged("cluster-1", "sig-a")).To(BeFalse())
+
+		c.record("cluster-1", "sig-a")
+		Expect(c.signatureUnchanged("cluster-1", "sig-a")).To(BeTrue())
+		Expect(c.signatureUnchanged("cluster-1", "sig-b")).To(BeFalse())
+		Expect(c.signatureUnchanged("cluster-2", "sig-a")).To(BeFalse())
+	})
+})
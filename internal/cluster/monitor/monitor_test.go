@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeLeader struct{ isLeader bool }
+
+func (f *fakeLeader) IsLeader() bool { return f.isLeader }
+
+var _ = Describe("SingleShardScheduler", func() {
+	It("gates tiers on their own interval, not a shared cadence", func() {
+		s := NewSingleShardScheduler(&fakeLeader{isLeader: true})
+		tier := Tier{Name: "fast", Interval: time.Minute}
+
+		Expect(s.ShouldRunTier(tier, time.Now())).To(BeFalse())
+		Expect(s.ShouldRunTier(tier, time.Now().Add(-2*time.Minute))).To(BeTrue())
+	})
+
+	It("reproduces the existing leader-owns-everything behavior", func() {
+		Expect(NewSingleShardScheduler(&fakeLeader{isLeader: true}).OwnsCluster("any-cluster")).To(BeTrue())
+		Expect(NewSingleShardScheduler(&fakeLeader{isLeader: false}).OwnsCluster("any-cluster")).To(BeFalse())
+	})
+})
+
+var _ = Describe("ShardedScheduler", func() {
+	It("assigns every cluster to exactly one of N replicas", func() {
+		replicas := 4
+		schedulers := make([]*ShardedScheduler, replicas)
+		for i := 0; i < replicas; i++ {
+			i := i
+			schedulers[i] = NewShardedScheduler(&fakeLeader{isLeader: true}, func() int { return replicas }, func() int { return i })
+		}
+
+		clusterIDs := []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d", "cluster-e"}
+		for _, id := range clusterIDs {
+			owners := 0
+			for _, s := range schedulers {
+				if s.OwnsCluster(id) {
+					owners++
+				}
+			}
+			Expect(owners).To(Equal(1))
+		}
+	})
+
+	It("is deterministic for the same cluster ID and replica topology", func() {
+		s1 := NewShardedScheduler(&fakeLeader{isLeader: true}, func() int { return 3 }, func() int { return 1 })
+		s2 := NewShardedScheduler(&fakeLeader{isLeader: true}, func() int { return 3 }, func() int { return 1 })
+		Expect(s1.OwnsCluster("cluster-a")).To(Equal(s2.OwnsCluster("cluster-a")))
+	})
+})
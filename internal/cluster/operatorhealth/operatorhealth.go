@@ -0,0 +1,171 @@
+// Package operatorhealth periodically emits per-condition gauges for every
+// ClusterOperator on a registered installed or day-2 cluster, modeled after
+// ARO's emitAroOperatorConditions: SRE gets a standing signal on operator
+// health between installs, rather than only the one-shot
+// ClusterInstallationFinished metric CompleteInstallation emits once.
+package operatorhealth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KindCluster and KindDay2 label which lifecycle a registered cluster is
+// being monitored under, so the emitted gauges can be broken down the same
+// way the rest of the metrics in this repo distinguish day-1 from day-2.
+const (
+	KindCluster = "cluster"
+	KindDay2    = "day2"
+)
+
+// expectedConditions is the steady-state ClusterOperator status every
+// registered cluster is compared against; anything else counts as
+// unexpected, the same contract `oc adm upgrade status` and
+// emitAroOperatorConditions both check operators against.
+var expectedConditions = map[configv1.ClusterStatusConditionType]configv1.ConditionStatus{
+	configv1.OperatorAvailable:   configv1.ConditionTrue,
+	configv1.OperatorDegraded:    configv1.ConditionFalse,
+	configv1.OperatorProgressing: configv1.ConditionFalse,
+}
+
+// ClusterClientResolver resolves clusterID's live controller-runtime
+// client, the same capability maintenance.ClusterClientResolver provides
+// for maintenance tasks.
+type ClusterClientResolver interface {
+	ResolveClient(ctx context.Context, clusterID string) (client.Client, error)
+}
+
+// MetricsAPI is the subset of metrics.API this monitor emits to.
+type MetricsAPI interface {
+	OperatorConditionStatus(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType, status configv1.ConditionStatus)
+	OperatorConditionUnexpected(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType)
+}
+
+// ConditionRecorder persists the per-poll OperatorsHealthy verdict this
+// monitor computes onto the cluster's own ClusterCondition, so a watcher of
+// the condition sees the same signal the gauges expose to metrics.
+type ConditionRecorder interface {
+	RecordOperatorsHealthy(ctx context.Context, clusterID string, healthy bool, unexpected []string)
+}
+
+type registeredCluster struct {
+	id   string
+	kind string
+}
+
+// Monitor periodically lists ClusterOperators on every registered cluster
+// and emits a gauge per observed condition.
+type Monitor struct {
+	mu           sync.Mutex
+	registered   map[string]registeredCluster
+	resolver     ClusterClientResolver
+	metrics      MetricsAPI
+	recorder     ConditionRecorder
+	log          logrus.FieldLogger
+	pollInterval time.Duration
+}
+
+// NewMonitor returns a Monitor that polls every registered cluster every
+// pollInterval. recorder may be nil, in which case the monitor still emits
+// metrics but doesn't persist an OperatorsHealthy condition anywhere.
+func NewMonitor(resolver ClusterClientResolver, metrics MetricsAPI, recorder ConditionRecorder, log logrus.FieldLogger, pollInterval time.Duration) *Monitor {
+	return &Monitor{
+		registered:   make(map[string]registeredCluster),
+		resolver:     resolver,
+		metrics:      metrics,
+		recorder:     recorder,
+		log:          log,
+		pollInterval: pollInterval,
+	}
+}
+
+// Register adds clusterID to the set this Monitor polls, under kind
+// (KindCluster or KindDay2). Registering an already-registered cluster
+// under a different kind - the installed-to-day-2 transition - just
+// updates its kind in place.
+func (mon *Monitor) Register(clusterID, kind string) {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	mon.registered[clusterID] = registeredCluster{id: clusterID, kind: kind}
+}
+
+// Unregister removes clusterID from the set this Monitor polls, e.g. once
+// it's deregistered entirely.
+func (mon *Monitor) Unregister(clusterID string) {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	delete(mon.registered, clusterID)
+}
+
+// Run polls every registered cluster every pollInterval until ctx is
+// canceled.
+func (mon *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(mon.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mon.pollOnce(ctx)
+		}
+	}
+}
+
+func (mon *Monitor) pollOnce(ctx context.Context) {
+	mon.mu.Lock()
+	snapshot := make([]registeredCluster, 0, len(mon.registered))
+	for _, rc := range mon.registered {
+		snapshot = append(snapshot, rc)
+	}
+	mon.mu.Unlock()
+
+	for _, rc := range snapshot {
+		cl, err := mon.resolver.ResolveClient(ctx, rc.id)
+		if err != nil {
+			mon.log.WithError(err).Warnf("operatorhealth: failed to resolve client for cluster %s", rc.id)
+			continue
+		}
+
+		var operators configv1.ClusterOperatorList
+		if err := cl.List(ctx, &operators); err != nil {
+			mon.log.WithError(err).Warnf("operatorhealth: failed to list cluster operators for cluster %s", rc.id)
+			continue
+		}
+
+		var unexpected []string
+		for _, op := range operators.Items {
+			unexpected = append(unexpected, emitOperatorConditions(mon.metrics, rc.id, rc.kind, op)...)
+		}
+		if mon.recorder != nil {
+			mon.recorder.RecordOperatorsHealthy(ctx, rc.id, len(unexpected) == 0, unexpected)
+		}
+	}
+}
+
+// emitOperatorConditions emits a gauge for every condition on op that
+// expectedConditions tracks, an unexpected-status increment for any that
+// diverges from its expected value, and returns a "<operator>/<condition>"
+// label for each divergence so the caller can aggregate a cluster-wide
+// verdict.
+func emitOperatorConditions(metrics MetricsAPI, clusterID, kind string, op configv1.ClusterOperator) []string {
+	var unexpected []string
+	for _, cond := range op.Status.Conditions {
+		expected, tracked := expectedConditions[cond.Type]
+		if !tracked {
+			continue
+		}
+		metrics.OperatorConditionStatus(clusterID, kind, op.Name, cond.Type, cond.Status)
+		if cond.Status != expected {
+			metrics.OperatorConditionUnexpected(clusterID, kind, op.Name, cond.Type)
+			unexpected = append(unexpected, fmt.Sprintf("%s/%s", op.Name, cond.Type))
+		}
+	}
+	return unexpected
+}
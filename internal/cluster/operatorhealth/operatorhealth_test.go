@@ -0,0 +1,118 @@
+package operatorhealth
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeResolver struct {
+	cl  client.Client
+	err error
+}
+
+func (r fakeResolver) ResolveClient(context.Context, string) (client.Client, error) {
+	return r.cl, r.err
+}
+
+type recordedStatus struct {
+	clusterID, kind, operator string
+	condition                 configv1.ClusterStatusConditionType
+	status                    configv1.ConditionStatus
+}
+
+type fakeMetrics struct {
+	statuses   []recordedStatus
+	unexpected []recordedStatus
+}
+
+func (f *fakeMetrics) OperatorConditionStatus(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType, status configv1.ConditionStatus) {
+	f.statuses = append(f.statuses, recordedStatus{clusterID, kind, operator, condition, status})
+}
+
+func (f *fakeMetrics) OperatorConditionUnexpected(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType) {
+	f.unexpected = append(f.unexpected, recordedStatus{clusterID: clusterID, kind: kind, operator: operator, condition: condition})
+}
+
+type recordedVerdict struct {
+	clusterID  string
+	healthy    bool
+	unexpected []string
+}
+
+type fakeRecorder struct {
+	verdicts []recordedVerdict
+}
+
+func (f *fakeRecorder) RecordOperatorsHealthy(_ context.Context, clusterID string, healthy bool, unexpected []string) {
+	f.verdicts = append(f.verdicts, recordedVerdict{clusterID, healthy, unexpected})
+}
+
+func newFakeClient(objs ...client.Object) client.Client {
+	sch := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+	Expect(configv1.AddToScheme(sch)).To(Succeed())
+	return fakeclient.NewClientBuilder().WithScheme(sch).WithObjects(objs...).Build()
+}
+
+var _ = Describe("Monitor.pollOnce", func() {
+	It("emits a status gauge and counts unexpected statuses for every registered cluster", func() {
+		op := &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "authentication"},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse},
+					{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue},
+					{Type: configv1.OperatorProgressing, Status: configv1.ConditionFalse},
+				},
+			},
+		}
+		metrics := &fakeMetrics{}
+		recorder := &fakeRecorder{}
+		mon := NewMonitor(fakeResolver{cl: newFakeClient(op)}, metrics, recorder, logrus.New(), 0)
+		mon.Register("cluster-1", KindCluster)
+
+		mon.pollOnce(context.Background())
+
+		Expect(metrics.statuses).To(HaveLen(3))
+		Expect(metrics.unexpected).To(HaveLen(2))
+		for _, u := range metrics.unexpected {
+			Expect(u.clusterID).To(Equal("cluster-1"))
+			Expect(u.kind).To(Equal(KindCluster))
+		}
+
+		Expect(recorder.verdicts).To(HaveLen(1))
+		Expect(recorder.verdicts[0].clusterID).To(Equal("cluster-1"))
+		Expect(recorder.verdicts[0].healthy).To(BeFalse())
+		Expect(recorder.verdicts[0].unexpected).To(ConsistOf("authentication/Available", "authentication/Degraded"))
+	})
+
+	It("skips clusters that have been unregistered", func() {
+		metrics := &fakeMetrics{}
+		mon := NewMonitor(fakeResolver{cl: newFakeClient()}, metrics, nil, logrus.New(), 0)
+		mon.Register("cluster-1", KindCluster)
+		mon.Unregister("cluster-1")
+
+		mon.pollOnce(context.Background())
+
+		Expect(metrics.statuses).To(BeEmpty())
+	})
+
+	It("re-registering under a new kind updates it in place rather than duplicating the entry", func() {
+		metrics := &fakeMetrics{}
+		mon := NewMonitor(fakeResolver{cl: newFakeClient()}, metrics, nil, logrus.New(), 0)
+		mon.Register("cluster-1", KindCluster)
+		mon.Register("cluster-1", KindDay2)
+
+		Expect(mon.registered).To(HaveLen(1))
+		Expect(mon.registered["cluster-1"].kind).To(Equal(KindDay2))
+	})
+})
@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/assisted-service/internal/cluster/operatorhealth"
+	"github.com/openshift/assisted-service/internal/common"
+	logutil "github.com/openshift/assisted-service/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// operatorHealthMetricsAdapter implements operatorhealth.MetricsAPI against
+// m.metricAPI, the same thin-adapter role managerClusterClientResolver plays
+// for maintenance.ClusterClientResolver.
+type operatorHealthMetricsAdapter struct {
+	m *Manager
+}
+
+func (a operatorHealthMetricsAdapter) OperatorConditionStatus(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType, status configv1.ConditionStatus) {
+	a.m.metricAPI.OperatorConditionStatus(clusterID, kind, operator, string(condition), string(status))
+}
+
+func (a operatorHealthMetricsAdapter) OperatorConditionUnexpected(clusterID, kind, operator string, condition configv1.ClusterStatusConditionType) {
+	a.m.metricAPI.OperatorConditionUnexpected(clusterID, kind, operator, string(condition))
+}
+
+// operatorHealthConditionRecorder implements operatorhealth.ConditionRecorder
+// against m.db, persisting each poll's verdict onto OperatorsHealthyCondition
+// so a watcher of the condition sees the same signal the gauges expose to
+// metrics.
+type operatorHealthConditionRecorder struct {
+	m *Manager
+}
+
+func (r operatorHealthConditionRecorder) RecordOperatorsHealthy(ctx context.Context, clusterID string, healthy bool, unexpected []string) {
+	log := logutil.FromContext(ctx, r.m.log)
+	cluster, err := common.GetClusterFromDB(r.m.db, strfmt.UUID(clusterID), common.SkipEagerLoading)
+	if err != nil {
+		log.WithError(err).Warnf("operatorhealth: failed to load cluster %s to record OperatorsHealthy", clusterID)
+		return
+	}
+
+	if healthy {
+		SetCondition(cluster, common.ClusterCondition{Type: OperatorsHealthyCondition, Status: corev1.ConditionTrue, Reason: "OperatorsHealthy", Message: "all monitored operator conditions match their expected status"})
+	} else {
+		SetCondition(cluster, common.ClusterCondition{Type: OperatorsHealthyCondition, Status: corev1.ConditionFalse, Reason: "OperatorConditionsUnexpected", Message: strings.Join(unexpected, ", ")})
+	}
+	if _, err := r.m.updateConditionsInDB(ctx, r.m.db, cluster); err != nil {
+		log.WithError(err).Warnf("operatorhealth: failed to persist OperatorsHealthy condition for cluster %s", clusterID)
+	}
+}
+
+// StartOperatorHealthMonitor launches the operator-health Monitor's poll
+// loop in the background until ctx is canceled, the same lifecycle
+// StartMaintenanceActuator gives the maintenance Actuator.
+func (m *Manager) StartOperatorHealthMonitor(ctx context.Context) {
+	go m.operatorHealthMonitor.Run(ctx)
+}
@@ -0,0 +1,54 @@
+package cluster
+
+// PreparationStage is one step of preparationPipeline, the resumable
+// pipeline PrepareForInstallation drives. Persisting the most recently
+// completed stage as PreparationStage/Progress.PrevStage lets a restart
+// resume after it instead of redoing the whole pipeline from scratch.
+type PreparationStage string
+
+const (
+	StageGeneratingManifests     PreparationStage = "GeneratingManifests"
+	StageUploadingIgnitions      PreparationStage = "UploadingIgnitions"
+	StageValidatingImages        PreparationStage = "ValidatingImages"
+	StageAwaitingSchemaAgreement PreparationStage = "AwaitingSchemaAgreement"
+	StageDone                    PreparationStage = "Done"
+)
+
+// preparationPipeline is the ordered list of stages PrepareForInstallation
+// runs. StageDone is deliberately excluded - it's the terminal value
+// advancePreparationStage persists once every stage above has succeeded,
+// not a stage with work of its own.
+var preparationPipeline = []PreparationStage{
+	StageGeneratingManifests,
+	StageUploadingIgnitions,
+	StageValidatingImages,
+	StageAwaitingSchemaAgreement,
+}
+
+// preparationStageIndex returns stage's position in preparationPipeline, or
+// -1 if stage is empty or isn't a recognized pipeline stage - callers treat
+// -1 the same as "start from the beginning".
+func preparationStageIndex(stage PreparationStage) int {
+	for i, s := range preparationPipeline {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// preparationStagePercentage maps stage to the
+// PreparingForInstallationStagePercentage UpdateInstallProgress's existing
+// weighting already reads, so preparation progress advances stage-by-stage
+// instead of jumping from 0 to 100 only once the whole pipeline finishes.
+func preparationStagePercentage(stage PreparationStage) int64 {
+	if stage == StageDone {
+		return 100
+	}
+	for i, s := range preparationPipeline {
+		if s == stage {
+			return int64(float64(i+1) / float64(len(preparationPipeline)) * 100)
+		}
+	}
+	return 0
+}
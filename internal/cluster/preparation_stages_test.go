@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("preparationStageIndex", func() {
+	It("finds each pipeline stage at its position", func() {
+		for i, stage := range preparationPipeline {
+			Expect(preparationStageIndex(stage)).To(Equal(i))
+		}
+	})
+
+	It("returns -1 for an empty or unrecognized stage", func() {
+		Expect(preparationStageIndex("")).To(Equal(-1))
+		Expect(preparationStageIndex("NotAStage")).To(Equal(-1))
+	})
+})
+
+var _ = Describe("preparationStagePercentage", func() {
+	It("advances monotonically across the pipeline", func() {
+		last := int64(0)
+		for _, stage := range preparationPipeline {
+			pct := preparationStagePercentage(stage)
+			Expect(pct).To(BeNumerically(">", last))
+			last = pct
+		}
+	})
+
+	It("reports 100 for StageDone", func() {
+		Expect(preparationStagePercentage(StageDone)).To(Equal(int64(100)))
+	})
+
+	It("reports 0 for an unrecognized stage", func() {
+		Expect(preparationStagePercentage("NotAStage")).To(Equal(int64(0)))
+	})
+})
@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/cluster/clustercache"
+	"github.com/openshift/assisted-service/internal/common"
+	eventgen "github.com/openshift/assisted-service/internal/common/events"
+	"github.com/openshift/assisted-service/internal/constants"
+	eventsapi "github.com/openshift/assisted-service/internal/events/api"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/pkg/s3wrapper"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SanitizedCondition records whether Sanitize has scrubbed a
+// deregistered/deleted cluster's secrets.
+const SanitizedCondition = "Sanitized"
+
+// SanitizePolicy is the per-artifact action a Sanitizer takes.
+type SanitizePolicy string
+
+const (
+	SanitizeSkip   SanitizePolicy = "skip"
+	SanitizeWipe   SanitizePolicy = "wipe"
+	SanitizeDelete SanitizePolicy = "delete"
+)
+
+// SanitizeSpec configures, per artifact, what Sanitize does to it before a
+// cluster's S3 objects and DB rows are removed.
+type SanitizeSpec struct {
+	Kubeconfig        SanitizePolicy `envconfig:"SANITIZE_KUBECONFIG_POLICY" default:"wipe"`
+	KubeadminPassword SanitizePolicy `envconfig:"SANITIZE_KUBEADMIN_PASSWORD_POLICY" default:"wipe"`
+	IgnitionSecrets   SanitizePolicy `envconfig:"SANITIZE_IGNITION_SECRETS_POLICY" default:"wipe"`
+	StepTimeout       time.Duration  `envconfig:"SANITIZE_STEP_TIMEOUT" default:"30s"`
+}
+
+// kubeadminSecretName and the bootstrap-token namespace/name mirror the
+// objects a real OpenShift installation leaves behind once Installed.
+const (
+	kubeadminSecretName      = "kubeadmin"
+	kubeadminSecretNamespace = "kube-system"
+	bootstrapTokenNamespace  = "kube-system"
+)
+
+// Sanitizer performs a best-effort secret-scrubbing pass over a cluster's S3
+// artifacts and, when the cluster is Installed and reachable, over
+// bootstrap-related secrets on the target cluster itself.
+type Sanitizer interface {
+	Sanitize(ctx context.Context, c *common.Cluster) error
+}
+
+type sanitizer struct {
+	objectHandler s3wrapper.API
+	clusterCache  *clustercache.ClusterCache
+	eventsHandler eventsapi.Handler
+	spec          SanitizeSpec
+}
+
+func newSanitizer(objectHandler s3wrapper.API, clusterCache *clustercache.ClusterCache, eventsHandler eventsapi.Handler, spec SanitizeSpec) *sanitizer {
+	return &sanitizer{objectHandler: objectHandler, clusterCache: clusterCache, eventsHandler: eventsHandler, spec: spec}
+}
+
+// Sanitize scrubs c's kubeconfig, kubeadmin-password and ignition secrets in
+// S3 per s.spec, then - if c is Installed and a cached client for it still
+// exists - attempts to delete the target cluster's kubeadmin Secret and any
+// bootstrap tokens. Every step runs under its own s.spec.StepTimeout so an
+// unreachable target API server can never block the caller (DeregisterCluster
+// or PermanentClustersDeletion) indefinitely. Errors from individual steps
+// are collected and returned, but do not stop the remaining steps from
+// running - sanitization is inherently best-effort.
+func (s *sanitizer) Sanitize(ctx context.Context, c *common.Cluster) error {
+	eventgen.SendClusterSanitizationStartedEvent(ctx, s.eventsHandler, *c.ID)
+
+	var failures []string
+
+	// Target-cluster cleanup needs the kubeconfig this cluster's S3 object
+	// still holds, so it has to run before the artifact loop below wipes or
+	// deletes that very object.
+	if swag.StringValue(c.Status) == models.ClusterStatusInstalled {
+		if err := s.sanitizeTargetCluster(ctx, c); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	for _, artifact := range []struct {
+		name   string
+		policy SanitizePolicy
+	}{
+		{constants.Kubeconfig, s.spec.Kubeconfig},
+		{"kubeadmin-password", s.spec.KubeadminPassword},
+	} {
+		if err := s.sanitizeObject(ctx, c, artifact.name, artifact.policy); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if err := s.sanitizeIgnitionSecrets(ctx, c); err != nil {
+		failures = append(failures, err.Error())
+	}
+
+	status, reason, message := corev1.ConditionTrue, "Sanitized", "cluster secrets were scrubbed"
+	if len(failures) > 0 {
+		status, reason, message = corev1.ConditionFalse, "SanitizationFailed", strings.Join(failures, "; ")
+		eventgen.SendClusterSanitizationFailedEvent(ctx, s.eventsHandler, *c.ID, message)
+	}
+	SetCondition(c, common.ClusterCondition{Type: SanitizedCondition, Status: status, Reason: reason, Message: message})
+
+	if len(failures) > 0 {
+		return errors.Errorf("sanitization of cluster %s failed: %s", c.ID, message)
+	}
+	return nil
+}
+
+func (s *sanitizer) sanitizeObject(ctx context.Context, c *common.Cluster, fileName string, policy SanitizePolicy) error {
+	if policy == SanitizeSkip {
+		return nil
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, s.spec.StepTimeout)
+	defer cancel()
+
+	key := fmt.Sprintf("%s/%s", c.ID.String(), fileName)
+	exists, err := s.objectHandler.DoesObjectExist(stepCtx, key)
+	if err != nil || !exists {
+		return err
+	}
+
+	if policy == SanitizeWipe {
+		if _, err = s.objectHandler.Upload(stepCtx, []byte{}, key); err != nil {
+			return errors.Wrapf(err, "failed to wipe %s", key)
+		}
+	}
+	if _, err = s.objectHandler.DeleteObject(stepCtx, key); err != nil {
+		return errors.Wrapf(err, "failed to delete %s", key)
+	}
+	return nil
+}
+
+func (s *sanitizer) sanitizeIgnitionSecrets(ctx context.Context, c *common.Cluster) error {
+	if s.spec.IgnitionSecrets == SanitizeSkip {
+		return nil
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, s.spec.StepTimeout)
+	defer cancel()
+
+	files, err := s.objectHandler.ListObjectsByPrefix(stepCtx, fmt.Sprintf("%s/ignition", c.ID.String()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to list ignition secrets for cluster %s", c.ID)
+	}
+
+	var failures []string
+	for _, file := range files {
+		if s.spec.IgnitionSecrets == SanitizeWipe {
+			if _, err = s.objectHandler.Upload(stepCtx, []byte{}, file); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+		}
+		if _, err = s.objectHandler.DeleteObject(stepCtx, file); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("failed to sanitize ignition secrets for cluster %s: %s", c.ID, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// sanitizeTargetCluster downloads c's kubeconfig and deletes the kubeadmin
+// Secret and any bootstrap.kubernetes.io/token Secrets on c's target
+// cluster, the same kubeconfig-download-then-GetClient path
+// RefreshClusterHealth and managerClusterClientResolver use - unlike
+// clustercache.ClusterCache.GetClientIfPresent, this actually dials the
+// target cluster instead of depending on a client some unrelated feature
+// happened to have cached already.
+func (s *sanitizer) sanitizeTargetCluster(ctx context.Context, c *common.Cluster) error {
+	stepCtx, cancel := context.WithTimeout(ctx, s.spec.StepTimeout)
+	defer cancel()
+
+	reader, _, err := s.objectHandler.Download(stepCtx, fmt.Sprintf("%s/%s", c.ID.String(), constants.Kubeconfig))
+	if err != nil {
+		return errors.Wrapf(err, "failed to download kubeconfig for cluster %s", c.ID)
+	}
+	defer reader.Close()
+	kubeconfig, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read kubeconfig for cluster %s", c.ID)
+	}
+
+	cl, err := s.clusterCache.GetClient(stepCtx, c.ID.String(), kubeconfig)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to target cluster %s", c.ID)
+	}
+
+	var failures []string
+	kubeadmin := &corev1.Secret{}
+	if err = cl.Get(stepCtx, types.NamespacedName{Namespace: kubeadminSecretNamespace, Name: kubeadminSecretName}, kubeadmin); err == nil {
+		if err = cl.Delete(stepCtx, kubeadmin); err != nil && !apierrors.IsNotFound(err) {
+			failures = append(failures, err.Error())
+		}
+	} else if !apierrors.IsNotFound(err) {
+		failures = append(failures, err.Error())
+	}
+
+	var secrets corev1.SecretList
+	if err = cl.List(stepCtx, &secrets, client.InNamespace(bootstrapTokenNamespace)); err != nil {
+		failures = append(failures, err.Error())
+	} else {
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			if secret.Type != corev1.SecretTypeBootstrapToken {
+				continue
+			}
+			if err = cl.Delete(stepCtx, secret); err != nil && !apierrors.IsNotFound(err) {
+				failures = append(failures, err.Error())
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Errorf("failed to sanitize target cluster %s: %s", c.ID, strings.Join(failures, "; "))
+	}
+	return nil
+}
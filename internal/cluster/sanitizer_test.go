@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/cluster/clustercache"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/pkg/s3wrapper"
+)
+
+var _ = Describe("sanitizer", func() {
+	var (
+		ctrl     *gomock.Controller
+		mockObjs *s3wrapper.MockAPI
+		s        *sanitizer
+		c        *common.Cluster
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockObjs = s3wrapper.NewMockAPI(ctrl)
+		clusterID := strfmt.UUID("11111111-1111-1111-1111-111111111111")
+		c = &common.Cluster{Cluster: models.Cluster{ID: &clusterID, Status: swag.String(models.ClusterStatusAddingHosts)}}
+		s = &sanitizer{objectHandler: mockObjs, clusterCache: clustercache.NewClusterCache()}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("wipes then deletes a present object under SanitizeWipe", func() {
+		key := c.ID.String() + "/kubeconfig"
+		mockObjs.EXPECT().DoesObjectExist(gomock.Any(), key).Return(true, nil)
+		mockObjs.EXPECT().Upload(gomock.Any(), gomock.Any(), key).Return(true, nil)
+		mockObjs.EXPECT().DeleteObject(gomock.Any(), key).Return(true, nil)
+
+		Expect(s.sanitizeObject(context.Background(), c, "kubeconfig", SanitizeWipe)).To(Succeed())
+	})
+
+	It("does nothing for a missing object", func() {
+		key := c.ID.String() + "/kubeconfig"
+		mockObjs.EXPECT().DoesObjectExist(gomock.Any(), key).Return(false, nil)
+
+		Expect(s.sanitizeObject(context.Background(), c, "kubeconfig", SanitizeWipe)).To(Succeed())
+	})
+
+	It("deletes without wiping under SanitizeDelete", func() {
+		key := c.ID.String() + "/kubeconfig"
+		mockObjs.EXPECT().DoesObjectExist(gomock.Any(), key).Return(true, nil)
+		mockObjs.EXPECT().DeleteObject(gomock.Any(), key).Return(true, nil)
+
+		Expect(s.sanitizeObject(context.Background(), c, "kubeconfig", SanitizeDelete)).To(Succeed())
+	})
+
+	It("leaves a present object untouched under SanitizeSkip", func() {
+		Expect(s.sanitizeObject(context.Background(), c, "kubeconfig", SanitizeSkip)).To(Succeed())
+	})
+
+	It("surfaces a failure from sanitizeTargetCluster instead of silently succeeding when the kubeconfig can't be downloaded", func() {
+		key := c.ID.String() + "/kubeconfig"
+		mockObjs.EXPECT().Download(gomock.Any(), key).Return(nil, int64(0), errors.New("not found"))
+
+		Expect(s.sanitizeTargetCluster(context.Background(), c)).To(HaveOccurred())
+	})
+
+	It("attempts to connect to the target cluster using the downloaded kubeconfig rather than an incidentally cached client", func() {
+		key := c.ID.String() + "/kubeconfig"
+		mockObjs.EXPECT().Download(gomock.Any(), key).Return(io.NopCloser(strings.NewReader("not a valid kubeconfig")), int64(0), nil)
+
+		// GetClient fails to parse this as a real kubeconfig, proving the
+		// call reached clustercache at all - before this fix,
+		// GetClientIfPresent would have returned (nil, nil) here and
+		// sanitizeTargetCluster would have reported success without ever
+		// trying to dial anything.
+		Expect(s.sanitizeTargetCluster(context.Background(), c)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// conditionTransitionEvent is the Kubernetes Event AgentReconciler raises
+// when one of the Agent's conditions settles on a given Reason, so
+// downstream operators (ClusterDeployment, InfraEnv) can react to an Agent's
+// status changing (e.g. Disconnected -> DiscoveringUnbound, Binding ->
+// Bound) without polling Status.Conditions themselves.
+type conditionTransitionEvent struct {
+	reason    string
+	eventType string
+}
+
+// conditionTransitionEvents maps every Reason the builtin agenthealth
+// checker produces to the Event it should raise. Reasons that already read
+// as an event reason (e.g. AgentDisconnected) are deliberately kept close to
+// their condition Reason so an operator correlating `kubectl describe` output
+// with `kubectl get events` doesn't have to learn a second vocabulary.
+var conditionTransitionEvents = map[string]conditionTransitionEvent{
+	v1beta1.AgentNotReadyReason:              {"AgentNotReady", corev1.EventTypeWarning},
+	v1beta1.AgentReadyReason:                 {"AgentReady", corev1.EventTypeNormal},
+	v1beta1.AgentIsNotApprovedReason:         {"AgentNotApproved", corev1.EventTypeWarning},
+	v1beta1.AgentAlreadyInstallingReason:     {"AgentAlreadyInstalling", corev1.EventTypeNormal},
+	v1beta1.AgentInstallationStoppedReason:   {"AgentInstallationStopped", corev1.EventTypeNormal},
+	v1beta1.AgentConnectedReason:             {"AgentConnected", corev1.EventTypeNormal},
+	v1beta1.AgentDisconnectedReason:          {"AgentDisconnected", corev1.EventTypeWarning},
+	v1beta1.InstallationNotStartedReason:     {"AgentInstallationNotStarted", corev1.EventTypeNormal},
+	v1beta1.InstalledReason:                  {"AgentInstalled", corev1.EventTypeNormal},
+	v1beta1.InstallationFailedReason:         {"AgentInstallationFailed", corev1.EventTypeWarning},
+	v1beta1.InstallationInProgressReason:     {"AgentInstalling", corev1.EventTypeNormal},
+	v1beta1.ValidationsPassingReason:         {"AgentValidationsPassing", corev1.EventTypeNormal},
+	v1beta1.ValidationsFailingReason:         {"AgentValidationFailed", corev1.EventTypeWarning},
+	v1beta1.ValidationsUserPendingReason:     {"AgentValidationPending", corev1.EventTypeNormal},
+	v1beta1.BoundReason:                      {"AgentBound", corev1.EventTypeNormal},
+	v1beta1.UnboundReason:                    {"AgentUnbound", corev1.EventTypeNormal},
+	v1beta1.BindingReason:                    {"AgentBinding", corev1.EventTypeNormal},
+	v1beta1.UnbindingReason:                  {"AgentUnbinding", corev1.EventTypeNormal},
+	v1beta1.UnbindingPendingUserActionReason: {"AgentUnbindingPendingUserAction", corev1.EventTypeWarning},
+}
+
+// recordConditionTransitionEvents compares agent's current Status.Conditions
+// against previous (its Conditions before this reconcile set new ones) and
+// raises an Event for every condition whose Reason changed, carrying the
+// backend statusInfo as the message when one is available. It's a no-op for
+// the initial reconcile of a brand new Agent - nothing has "transitioned"
+// yet - and for any Reason not in conditionTransitionEvents.
+func recordConditionTransitionEvents(recorder record.EventRecorder, agent *v1beta1.Agent, previous []conditionsv1.Condition, statusInfo string) {
+	if recorder == nil || previous == nil {
+		return
+	}
+	for _, current := range agent.Status.Conditions {
+		prev := conditionsv1.FindStatusCondition(previous, current.Type)
+		if prev != nil && prev.Reason == current.Reason {
+			continue
+		}
+		event, ok := conditionTransitionEvents[current.Reason]
+		if !ok {
+			continue
+		}
+		message := current.Message
+		if statusInfo != "" {
+			message = statusInfo
+		}
+		recorder.Eventf(agent, event.eventType, event.reason, message)
+	}
+}
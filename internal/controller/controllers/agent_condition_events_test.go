@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func conditionEventTestAgent() *v1beta1.Agent {
+	return newAgent("test-agent", testNamespace, v1beta1.AgentSpec{})
+}
+
+func withCondition(conditions []conditionsv1.Condition, condType conditionsv1.ConditionType, status corev1.ConditionStatus, reason, message string) []conditionsv1.Condition {
+	conditionsv1.SetStatusCondition(&conditions, conditionsv1.Condition{Type: condType, Status: status, Reason: reason, Message: message})
+	return conditions
+}
+
+var _ = Describe("recordConditionTransitionEvents", func() {
+	var (
+		recorder *record.FakeRecorder
+		agent    *v1beta1.Agent
+	)
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		agent = conditionEventTestAgent()
+	})
+
+	It("raises no event on the first reconcile of a new Agent", func() {
+		agent.Status.Conditions = withCondition(nil, v1beta1.ConnectedCondition, corev1.ConditionTrue, v1beta1.AgentConnectedReason, v1beta1.AgentConnectedMsg)
+		recordConditionTransitionEvents(recorder, agent, nil, "")
+		Consistently(recorder.Events).ShouldNot(Receive())
+	})
+
+	It("raises AgentDisconnected when Connected flips from true to false", func() {
+		previous := withCondition(nil, v1beta1.ConnectedCondition, corev1.ConditionTrue, v1beta1.AgentConnectedReason, v1beta1.AgentConnectedMsg)
+		agent.Status.Conditions = withCondition(nil, v1beta1.ConnectedCondition, corev1.ConditionFalse, v1beta1.AgentDisconnectedReason, v1beta1.AgentDisonnectedMsg)
+
+		recordConditionTransitionEvents(recorder, agent, previous, "")
+		Expect(<-recorder.Events).To(ContainSubstring("AgentDisconnected"))
+	})
+
+	It("uses statusInfo as the event message when the backend supplied one", func() {
+		previous := withCondition(nil, v1beta1.BoundCondition, corev1.ConditionFalse, v1beta1.BindingReason, v1beta1.BindingMsg)
+		agent.Status.Conditions = withCondition(nil, v1beta1.BoundCondition, corev1.ConditionTrue, v1beta1.BoundReason, v1beta1.BoundMsg)
+
+		recordConditionTransitionEvents(recorder, agent, previous, "host joined the cluster")
+		Expect(<-recorder.Events).To(ContainSubstring("host joined the cluster"))
+	})
+
+	It("does not raise an event when the Reason hasn't changed", func() {
+		previous := withCondition(nil, v1beta1.ConnectedCondition, corev1.ConditionTrue, v1beta1.AgentConnectedReason, v1beta1.AgentConnectedMsg)
+		agent.Status.Conditions = withCondition(nil, v1beta1.ConnectedCondition, corev1.ConditionTrue, v1beta1.AgentConnectedReason, v1beta1.AgentConnectedMsg)
+
+		recordConditionTransitionEvents(recorder, agent, previous, "")
+		Consistently(recorder.Events).ShouldNot(Receive())
+	})
+
+	It("raises one event per transitioned condition in a multi-condition update", func() {
+		previous := withCondition(nil, v1beta1.BoundCondition, corev1.ConditionFalse, v1beta1.BindingReason, v1beta1.BindingMsg)
+		previous = withCondition(previous, v1beta1.RequirementsMetCondition, corev1.ConditionFalse, v1beta1.BindingReason, v1beta1.BindingMsg)
+
+		conditions := withCondition(nil, v1beta1.BoundCondition, corev1.ConditionFalse, v1beta1.UnbindingPendingUserActionReason, v1beta1.UnbindingPendingUserActionMsg)
+		conditions = withCondition(conditions, v1beta1.RequirementsMetCondition, corev1.ConditionFalse, v1beta1.UnbindingReason, v1beta1.UnbindingMsg)
+		agent.Status.Conditions = conditions
+
+		recordConditionTransitionEvents(recorder, agent, previous, "")
+		Expect(<-recorder.Events).To(ContainSubstring("AgentUnbindingPendingUserAction"))
+		Expect(<-recorder.Events).To(ContainSubstring("AgentUnbinding"))
+	})
+})
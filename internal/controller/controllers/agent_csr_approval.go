@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/pkg/errors"
+	certificatesv1 "k8s.io/api/certificates/v1"
+)
+
+// nodeUserPrefix is the Subject CommonName / requester username prefix
+// kube-apiserver's node authorizer expects for a kubelet's identity:
+// "system:node:<hostname>".
+const nodeUserPrefix = "system:node:"
+
+// systemNodesGroup is the Subject Organization every node client/serving
+// certificate must carry for the node authorizer to recognize it.
+const systemNodesGroup = "system:nodes"
+
+// nodeBootstrapperUsername and kubeletBootstrapUsername are the two
+// requester identities a day-2 host's initial client CSR is expected to
+// come in under: the machine-config-operator's bootstrapper service account,
+// or the legacy kubeadm-style "kubelet-bootstrap" user.
+const (
+	nodeBootstrapperUsername = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+	kubeletBootstrapUsername = "kubelet-bootstrap"
+)
+
+// maxPendingCSRAge bounds how old a pending CSR AgentCSRApprover will
+// approve. A CSR this stale most likely belongs to a host that has since
+// been reprovisioned with a new keypair (e.g. re-added to the cluster), so
+// approving it would hand out a certificate nobody is waiting on anymore.
+const maxPendingCSRAge = time.Hour
+
+// allowedClientCSRUsages and allowedServerCSRUsages bound the key usages
+// AgentCSRApprover accepts on a pending CSR; anything outside this set (e.g.
+// a client CSR also requesting server auth) is rejected rather than
+// approved with broader capabilities than the joining kubelet needs.
+var (
+	allowedClientCSRUsages = map[certificatesv1.KeyUsage]bool{
+		certificatesv1.UsageDigitalSignature: true,
+		certificatesv1.UsageKeyEncipherment:  true,
+		certificatesv1.UsageClientAuth:       true,
+	}
+	allowedServerCSRUsages = map[certificatesv1.KeyUsage]bool{
+		certificatesv1.UsageDigitalSignature: true,
+		certificatesv1.UsageKeyEncipherment:  true,
+		certificatesv1.UsageServerAuth:       true,
+	}
+)
+
+// CSRApprover decides whether a pending CertificateSigningRequest observed
+// on a spoke cluster should be approved for agent's day-2 join, so
+// AgentReconciler doesn't have to blindly approve every kubelet-serving /
+// node-bootstrapper CSR it finds - unsafe on a multi-tenant spoke cluster,
+// where nothing otherwise ties a pending CSR to the Agent that's supposed to
+// have produced it.
+type CSRApprover interface {
+	// Approve returns nil if csr should be approved for agent, or an error
+	// describing the attribute that didn't match (wrong requester, a
+	// hostname/IP outside what agent reports, an unparseable or stale
+	// request). AgentReconciler must not call ApproveCsr when Approve
+	// returns an error.
+	Approve(csr *certificatesv1.CertificateSigningRequest, agent *v1beta1.Agent) error
+}
+
+// AgentCSRApprover is the default CSRApprover: it parses the embedded x509
+// CertificateRequest and checks it against the expected node identity
+// (hostname and inventory IPs) of the Agent being reconciled, instead of
+// trusting the CSR's own claims.
+type AgentCSRApprover struct{}
+
+var _ CSRApprover = &AgentCSRApprover{}
+
+func (a *AgentCSRApprover) Approve(csr *certificatesv1.CertificateSigningRequest, agent *v1beta1.Agent) error {
+	if age := time.Since(csr.CreationTimestamp.Time); age > maxPendingCSRAge {
+		return errors.Errorf("csr %s is %s old, older than the %s limit for an unapproved join request", csr.Name, age, maxPendingCSRAge)
+	}
+
+	hostname := expectedHostname(agent)
+	if hostname == "" {
+		return errors.Errorf("agent %s/%s has no known hostname yet", agent.Namespace, agent.Name)
+	}
+	expectedCN := nodeUserPrefix + hostname
+
+	req, err := parseCSRRequest(csr.Spec.Request)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Subject.Organization) != 1 || req.Subject.Organization[0] != systemNodesGroup {
+		return errors.Errorf("csr %s has organization %v, expected [%s]", csr.Name, req.Subject.Organization, systemNodesGroup)
+	}
+	if req.Subject.CommonName != expectedCN {
+		return errors.Errorf("csr %s has common name %q, expected %q", csr.Name, req.Subject.CommonName, expectedCN)
+	}
+
+	if isServingCSR(csr) {
+		return a.approveServingCSR(csr, req, hostname, agent)
+	}
+	return a.approveClientCSR(csr, req)
+}
+
+func (a *AgentCSRApprover) approveClientCSR(csr *certificatesv1.CertificateSigningRequest, req *x509.CertificateRequest) error {
+	if !validClientRequester(csr.Spec.Username) {
+		return errors.Errorf("csr %s was requested by %q, expected %q or %q", csr.Name, csr.Spec.Username, nodeBootstrapperUsername, kubeletBootstrapUsername)
+	}
+	if len(req.DNSNames) != 0 || len(req.IPAddresses) != 0 {
+		return errors.Errorf("csr %s is a client CSR but requests SANs %v/%v", csr.Name, req.DNSNames, req.IPAddresses)
+	}
+	for _, usage := range csr.Spec.Usages {
+		if !allowedClientCSRUsages[usage] {
+			return errors.Errorf("csr %s requests unexpected usage %q for a client CSR", csr.Name, usage)
+		}
+	}
+	return nil
+}
+
+func (a *AgentCSRApprover) approveServingCSR(csr *certificatesv1.CertificateSigningRequest, req *x509.CertificateRequest, hostname string, agent *v1beta1.Agent) error {
+	for _, dnsName := range req.DNSNames {
+		if dnsName != hostname {
+			return errors.Errorf("csr %s requests DNS SAN %q, expected only %q", csr.Name, dnsName, hostname)
+		}
+	}
+
+	allowedIPs := expectedIPs(agent)
+	for _, ip := range req.IPAddresses {
+		if !allowedIPs[ip.String()] {
+			return errors.Errorf("csr %s requests IP SAN %s, not found in the agent's reported inventory", csr.Name, ip)
+		}
+	}
+
+	for _, usage := range csr.Spec.Usages {
+		if !allowedServerCSRUsages[usage] {
+			return errors.Errorf("csr %s requests unexpected usage %q for a serving CSR", csr.Name, usage)
+		}
+	}
+	return nil
+}
+
+// isServingCSR tells a kubelet-serving CSR apart from a node-client CSR by
+// the key usages it requests, mirroring how kube-controller-manager's own
+// CSR approver distinguishes the two signers.
+func isServingCSR(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, usage := range csr.Spec.Usages {
+		if usage == certificatesv1.UsageServerAuth {
+			return true
+		}
+	}
+	return false
+}
+
+func validClientRequester(username string) bool {
+	return username == nodeBootstrapperUsername || username == kubeletBootstrapUsername
+}
+
+func parseCSRRequest(pemBytes []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("csr request is not a valid PEM block")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing csr request")
+	}
+	return req, nil
+}
+
+// expectedHostname is the node identity AgentCSRApprover checks a CSR
+// against: Spec.Hostname if the user (or the installer) has set it,
+// otherwise whatever hostname the host reported in its inventory.
+func expectedHostname(agent *v1beta1.Agent) string {
+	if agent.Spec.Hostname != "" {
+		return agent.Spec.Hostname
+	}
+	return agent.Status.Inventory.Hostname
+}
+
+// expectedIPs collects every address (v4 and v6, CIDR suffix stripped) the
+// host reported across all its NICs, so a serving CSR's IP SANs can be
+// checked against them.
+func expectedIPs(agent *v1beta1.Agent) map[string]bool {
+	ips := map[string]bool{}
+	for _, nic := range agent.Status.Inventory.Interfaces {
+		for _, addr := range nic.IPV4Addresses {
+			if ip := addrIP(addr); ip != "" {
+				ips[ip] = true
+			}
+		}
+		for _, addr := range nic.IPV6Addresses {
+			if ip := addrIP(addr); ip != "" {
+				ips[ip] = true
+			}
+		}
+	}
+	return ips
+}
+
+// addrIP strips the CIDR suffix inventory NIC addresses are reported with
+// (e.g. "192.168.111.28/24"), returning "" if addr isn't a valid address.
+func addrIP(addr string) string {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(strings.TrimSpace(addr)); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const csrTestHostname = "ostest-extraworker-3"
+
+func csrTestAgent() *v1beta1.Agent {
+	agent := newAgent("test-agent", testNamespace, v1beta1.AgentSpec{Hostname: csrTestHostname})
+	agent.Status.Inventory = v1beta1.HostInventory{
+		Hostname: csrTestHostname,
+		Interfaces: []v1beta1.Interface{
+			{
+				Name:          "eth0",
+				IPV4Addresses: []string{"192.168.111.28/24"},
+				IPV6Addresses: []string{"1001:db8::10/120"},
+			},
+		},
+	}
+	return agent
+}
+
+func pemEncodeCSR(template *x509.CertificateRequest) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	Expect(err).ToNot(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func newClientCSR(username, commonName string, created time.Time) *certificatesv1.CertificateSigningRequest {
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{Organization: []string{systemNodesGroup}, CommonName: commonName},
+	}
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-csr", CreationTimestamp: metav1.NewTime(created)},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:  pemEncodeCSR(template),
+			Username: username,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageClientAuth,
+			},
+		},
+	}
+}
+
+func newServerCSR(commonName string, dnsNames []string, ips []string, created time.Time) *certificatesv1.CertificateSigningRequest {
+	var ipAddresses []net.IP
+	for _, ip := range ips {
+		ipAddresses = append(ipAddresses, net.ParseIP(ip))
+	}
+	template := &x509.CertificateRequest{
+		Subject:     pkix.Name{Organization: []string{systemNodesGroup}, CommonName: commonName},
+		DNSNames:    dnsNames,
+		IPAddresses: ipAddresses,
+	}
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "server-csr", CreationTimestamp: metav1.NewTime(created)},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:  pemEncodeCSR(template),
+			Username: nodeUserPrefix + csrTestHostname,
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageServerAuth,
+			},
+		},
+	}
+}
+
+var _ = Describe("AgentCSRApprover", func() {
+	var (
+		approver *AgentCSRApprover
+		agent    *v1beta1.Agent
+	)
+
+	BeforeEach(func() {
+		approver = &AgentCSRApprover{}
+		agent = csrTestAgent()
+	})
+
+	It("approves a client CSR from the machine-config-operator bootstrapper", func() {
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+csrTestHostname, time.Now())
+		Expect(approver.Approve(csr, agent)).To(Succeed())
+	})
+
+	It("approves a client CSR from the legacy kubelet-bootstrap user", func() {
+		csr := newClientCSR(kubeletBootstrapUsername, nodeUserPrefix+csrTestHostname, time.Now())
+		Expect(approver.Approve(csr, agent)).To(Succeed())
+	})
+
+	It("approves a server CSR whose SANs match the agent's hostname and inventory IPs", func() {
+		csr := newServerCSR(nodeUserPrefix+csrTestHostname, []string{csrTestHostname}, []string{"192.168.111.28"}, time.Now())
+		Expect(approver.Approve(csr, agent)).To(Succeed())
+	})
+
+	It("rejects a client CSR whose common name doesn't match the agent's hostname", func() {
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+"some-other-host", time.Now())
+		Expect(approver.Approve(csr, agent)).To(HaveOccurred())
+	})
+
+	It("rejects a server CSR with an IP SAN outside the agent's inventory", func() {
+		csr := newServerCSR(nodeUserPrefix+csrTestHostname, []string{csrTestHostname}, []string{"10.0.0.99"}, time.Now())
+		Expect(approver.Approve(csr, agent)).To(HaveOccurred())
+	})
+
+	It("rejects a client CSR from an unexpected requester", func() {
+		csr := newClientCSR("system:serviceaccount:default:attacker", nodeUserPrefix+csrTestHostname, time.Now())
+		Expect(approver.Approve(csr, agent)).To(HaveOccurred())
+	})
+
+	It("rejects a CSR older than the pending-approval age limit", func() {
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+csrTestHostname, time.Now().Add(-2*maxPendingCSRAge))
+		Expect(approver.Approve(csr, agent)).To(HaveOccurred())
+	})
+})
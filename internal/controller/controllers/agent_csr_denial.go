@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// DenyCsrsAnnotation, set to "true" on an Agent, denies every pending CSR
+// for that Agent outright - an admin override for a host that should never
+// be allowed to (re)join (e.g. one already decommissioned), without relying
+// on AgentCSRApprover's attribute checks to reject it incidentally.
+const DenyCsrsAnnotation = "agent-install.openshift.io/deny-csrs"
+
+// maxRejectedCSRs bounds Agent.Status.DebugInfo.RejectedCSRs to the most
+// recent entries, so a host stuck endlessly resubmitting a CSR AgentCSRApprover
+// keeps rejecting doesn't grow the Agent CR without limit.
+const maxRejectedCSRs = 10
+
+// applyCSRPolicy approves or denies csr on the spoke cluster on behalf of
+// agent: denied outright if agent carries DenyCsrsAnnotation, otherwise
+// approved or denied by approver's attribute checks. A denial is recorded on
+// agent's RejectedCSRs history and raised as a Kubernetes Event, giving an
+// operator the same auditability `kubectl certificate deny` would have left
+// by hand.
+func applyCSRPolicy(spokeClient SpokeK8sClient, recorder record.EventRecorder, approver CSRApprover, agent *v1beta1.Agent, csr *certificatesv1.CertificateSigningRequest) (v1beta1.CSRPolicyReason, error) {
+	reason := ""
+	if agent.Annotations[DenyCsrsAnnotation] == "true" {
+		reason = "agent carries the " + DenyCsrsAnnotation + "=true annotation"
+	} else if err := approver.Approve(csr, agent); err != nil {
+		reason = err.Error()
+	}
+
+	if reason == "" {
+		if err := spokeClient.ApproveCsr(csr); err != nil {
+			return "", err
+		}
+		return v1beta1.CSRPolicyApprovedReason, nil
+	}
+
+	if err := spokeClient.DenyCsr(csr, reason); err != nil {
+		return "", err
+	}
+	recordRejectedCSR(agent, csr.Name, reason)
+	if recorder != nil {
+		recorder.Eventf(agent, corev1.EventTypeWarning, "CSRDenied", "csr %s denied: %s", csr.Name, reason)
+	}
+	return v1beta1.CSRPolicyDeniedReason, nil
+}
+
+// recordRejectedCSR appends name/reason to agent's bounded rejected-CSR
+// history, dropping the oldest entries once it's over maxRejectedCSRs so the
+// list always reflects the most recent denials.
+func recordRejectedCSR(agent *v1beta1.Agent, name, reason string) {
+	rejected := append(agent.Status.DebugInfo.RejectedCSRs, v1beta1.RejectedCSR{
+		Name:     name,
+		Reason:   reason,
+		DeniedAt: metav1.Now(),
+	})
+	if len(rejected) > maxRejectedCSRs {
+		rejected = rejected[len(rejected)-maxRejectedCSRs:]
+	}
+	agent.Status.DebugInfo.RejectedCSRs = rejected
+}
+
+// setCSRPolicyCondition sets v1beta1.CSRPolicyCondition on agent to reflect
+// reason, so the Agent CR carries the last CSR policy decision without an
+// operator having to read its Events.
+func setCSRPolicyCondition(agent *v1beta1.Agent, reason v1beta1.CSRPolicyReason, message string) {
+	status := corev1.ConditionUnknown
+	switch reason {
+	case v1beta1.CSRPolicyApprovedReason:
+		status = corev1.ConditionTrue
+	case v1beta1.CSRPolicyDeniedReason:
+		status = corev1.ConditionFalse
+	}
+	conditionsv1.SetStatusCondition(&agent.Status.Conditions, conditionsv1.Condition{
+		Type:    v1beta1.CSRPolicyCondition,
+		Status:  status,
+		Reason:  string(reason),
+		Message: message,
+	})
+}
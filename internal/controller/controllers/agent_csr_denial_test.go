@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("applyCSRPolicy", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		spokeClient *MockSpokeK8sClient
+		recorder    *record.FakeRecorder
+		agent       *v1beta1.Agent
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		spokeClient = NewMockSpokeK8sClient(mockCtrl)
+		recorder = record.NewFakeRecorder(1)
+		agent = csrTestAgent()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("approves a CSR that passes AgentCSRApprover's checks", func() {
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+csrTestHostname, time.Now())
+		spokeClient.EXPECT().ApproveCsr(csr).Return(nil)
+
+		reason, err := applyCSRPolicy(spokeClient, recorder, &AgentCSRApprover{}, agent, csr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal(v1beta1.CSRPolicyApprovedReason))
+		Expect(agent.Status.DebugInfo.RejectedCSRs).To(BeEmpty())
+	})
+
+	It("denies a CSR that fails AgentCSRApprover's checks and records it", func() {
+		csr := newClientCSR("some-other-user", nodeUserPrefix+csrTestHostname, time.Now())
+		spokeClient.EXPECT().DenyCsr(csr, gomock.Any()).Return(nil)
+
+		reason, err := applyCSRPolicy(spokeClient, recorder, &AgentCSRApprover{}, agent, csr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal(v1beta1.CSRPolicyDeniedReason))
+		Expect(agent.Status.DebugInfo.RejectedCSRs).To(HaveLen(1))
+		Expect(agent.Status.DebugInfo.RejectedCSRs[0].Name).To(Equal(csr.Name))
+		Expect(<-recorder.Events).To(ContainSubstring("CSRDenied"))
+	})
+
+	It("denies every CSR outright when the agent carries DenyCsrsAnnotation, without consulting the approver", func() {
+		agent.Annotations = map[string]string{DenyCsrsAnnotation: "true"}
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+csrTestHostname, time.Now())
+		spokeClient.EXPECT().DenyCsr(csr, gomock.Any()).Return(nil)
+
+		reason, err := applyCSRPolicy(spokeClient, recorder, &AgentCSRApprover{}, agent, csr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal(v1beta1.CSRPolicyDeniedReason))
+	})
+
+	It("bounds RejectedCSRs to maxRejectedCSRs, dropping the oldest first", func() {
+		for i := 0; i < maxRejectedCSRs+3; i++ {
+			recordRejectedCSR(agent, "csr-overflow", "test reason")
+		}
+		Expect(agent.Status.DebugInfo.RejectedCSRs).To(HaveLen(maxRejectedCSRs))
+	})
+})
+
+var _ = Describe("setCSRPolicyCondition", func() {
+	It("sets CSRPolicyCondition true for an approval", func() {
+		agent := csrTestAgent()
+		setCSRPolicyCondition(agent, v1beta1.CSRPolicyApprovedReason, "approved")
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.CSRPolicyCondition)
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(string(v1beta1.CSRPolicyApprovedReason)))
+	})
+
+	It("sets CSRPolicyCondition false for a denial", func() {
+		agent := csrTestAgent()
+		setCSRPolicyCondition(agent, v1beta1.CSRPolicyDeniedReason, "denied")
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.CSRPolicyCondition)
+		Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+	})
+
+	It("sets CSRPolicyCondition unknown when there was nothing pending", func() {
+		agent := csrTestAgent()
+		setCSRPolicyCondition(agent, v1beta1.CSRPolicyNoActionReason, "nothing pending")
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.CSRPolicyCondition)
+		Expect(cond.Status).To(Equal(corev1.ConditionUnknown))
+	})
+})
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+)
+
+// classifyAgentError maps an error returned by V2UpdateHostInternal,
+// BindHostInternal, UnbindHostInternal, V2UpdateHostIgnitionInternal or
+// UpdateHostApprovedInternal onto the v1beta1.AgentErrorCode the reconciler
+// should set as SpecSyncedCondition's Reason (and status.debugInfo.lastErrorCode),
+// so downstream automation can tell a transient "cluster not registered yet"
+// apart from a permanent "fix your IgnitionConfigOverride" without parsing
+// the human-readable Message. Falls back to v1beta1.BackendErrorReason when
+// the error doesn't match any known shape.
+func classifyAgentError(err error) v1beta1.AgentErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	statusErr, ok := err.(apiStatusError)
+	if !ok {
+		return v1beta1.AgentErrorCode(v1beta1.BackendErrorReason)
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case statusErr.StatusCode() == http.StatusNotFound && strings.Contains(message, "cluster"):
+		return v1beta1.ClusterNotFoundErrorCode
+	case statusErr.StatusCode() == http.StatusNotFound:
+		return v1beta1.HostNotFoundErrorCode
+	case statusErr.StatusCode() == http.StatusConflict:
+		return v1beta1.BindConflictErrorCode
+	case statusErr.StatusCode() == http.StatusBadRequest && strings.Contains(message, "ignition"):
+		return v1beta1.InvalidIgnitionOverrideErrorCode
+	case statusErr.StatusCode() == http.StatusBadRequest && strings.Contains(message, "installer arg"):
+		return v1beta1.InvalidInstallerArgsErrorCode
+	case statusErr.StatusCode() == http.StatusForbidden:
+		return v1beta1.ApprovalRejectedErrorCode
+	case statusErr.StatusCode() >= http.StatusInternalServerError:
+		return v1beta1.InternalBackendErrorCode
+	default:
+		return v1beta1.AgentErrorCode(v1beta1.BackendErrorReason)
+	}
+}
+
+// permanentAgentErrorCodes are classifications that won't resolve themselves
+// with a retry - they need a user to fix the Agent/ClusterDeployment spec or
+// the host to be removed - so the reconciler should not schedule another
+// RequeueAfter for them; it instead waits on the next spec or backend-state
+// change to re-trigger reconciliation.
+var permanentAgentErrorCodes = map[v1beta1.AgentErrorCode]bool{
+	v1beta1.InvalidIgnitionOverrideErrorCode: true,
+	v1beta1.InvalidInstallerArgsErrorCode:    true,
+	v1beta1.HostNotFoundErrorCode:            true,
+	v1beta1.ApprovalRejectedErrorCode:        true,
+}
+
+// requeueAfterForAgentError returns the ctrl.Result the reconciler should
+// return for a classified error: defaultRequeueAfterOnError for transient
+// codes, or no requeue at all for permanentAgentErrorCodes.
+func requeueAfterForAgentError(code v1beta1.AgentErrorCode) (shouldRequeue bool) {
+	return !permanentAgentErrorCodes[code]
+}
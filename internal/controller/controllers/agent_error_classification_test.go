@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("classifyAgentError", func() {
+	It("classifies a 404 mentioning the cluster as ClusterNotFound", func() {
+		err := common.NewApiError(http.StatusNotFound, errors.New("cluster not found in DB"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.ClusterNotFoundErrorCode))
+	})
+
+	It("classifies a plain 404 as HostNotFound", func() {
+		err := common.NewApiError(http.StatusNotFound, errors.New("host not found"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.HostNotFoundErrorCode))
+	})
+
+	It("classifies a 409 as BindConflict", func() {
+		err := common.NewApiError(http.StatusConflict, errors.New("host already bound"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.BindConflictErrorCode))
+	})
+
+	It("classifies a 400 mentioning ignition as InvalidIgnitionOverride", func() {
+		err := common.NewApiError(http.StatusBadRequest, errors.New("invalid ignition config"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.InvalidIgnitionOverrideErrorCode))
+	})
+
+	It("classifies a 403 as ApprovalRejected", func() {
+		err := common.NewApiError(http.StatusForbidden, errors.New("approval rejected"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.ApprovalRejectedErrorCode))
+	})
+
+	It("classifies a 500 as InternalBackendError", func() {
+		err := common.NewApiError(http.StatusInternalServerError, errors.New("boom"))
+		Expect(classifyAgentError(err)).To(Equal(v1beta1.InternalBackendErrorCode))
+	})
+
+	It("falls back to BackendErrorReason for unclassified errors", func() {
+		Expect(classifyAgentError(errors.New("not an api error"))).To(Equal(v1beta1.AgentErrorCode(v1beta1.BackendErrorReason)))
+	})
+})
+
+var _ = Describe("requeueAfterForAgentError", func() {
+	It("requeues for transient codes", func() {
+		Expect(requeueAfterForAgentError(v1beta1.ClusterNotFoundErrorCode)).To(BeTrue())
+		Expect(requeueAfterForAgentError(v1beta1.InternalBackendErrorCode)).To(BeTrue())
+	})
+
+	It("does not requeue for permanent, user-input codes", func() {
+		Expect(requeueAfterForAgentError(v1beta1.InvalidIgnitionOverrideErrorCode)).To(BeFalse())
+		Expect(requeueAfterForAgentError(v1beta1.InvalidInstallerArgsErrorCode)).To(BeFalse())
+		Expect(requeueAfterForAgentError(v1beta1.HostNotFoundErrorCode)).To(BeFalse())
+		Expect(requeueAfterForAgentError(v1beta1.ApprovalRejectedErrorCode)).To(BeFalse())
+	})
+})
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AgentFinalizerName blocks an Agent's deletion until its backend host
+// record has actually been unbound and deregistered, so a premature `oc
+// delete agent` can't leave an orphaned host behind on the backend cluster.
+const AgentFinalizerName = "agent.agent-install.openshift.io/deprovision"
+
+// finalizeAgent performs the backend cleanup agent's finalizer is blocking
+// on: unbinding it first if it's still bound to a ClusterDeployment, then
+// deregistering its host record (and InfraEnv-side record) from the backend.
+// It returns whether it's now safe to remove the finalizer; a non-nil error
+// means the cleanup itself failed and AgentReconciler must requeue and retry
+// rather than removing the finalizer before the backend has confirmed the
+// host is actually gone.
+func finalizeAgent(agent *v1beta1.Agent, unbindHost, deregisterHost func() error) (finalizerRemovable bool, err error) {
+	if agent.Spec.ClusterDeploymentName != nil {
+		if err := unbindHost(); err != nil {
+			return false, err
+		}
+	}
+	if err := deregisterHost(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setDeletingCondition sets v1beta1.DeletingCondition on agent to reflect
+// finalizeAgent's outcome: DeleteFailedReason with cleanupErr's message if
+// the backend cleanup errored, otherwise DeletingReason while the finalizer
+// is still draining.
+func setDeletingCondition(agent *v1beta1.Agent, cleanupErr error) {
+	reason := v1beta1.DeletingReason
+	message := "agent is being unbound and deregistered from the backend cluster"
+	if cleanupErr != nil {
+		reason = v1beta1.DeleteFailedReason
+		message = cleanupErr.Error()
+	}
+	conditionsv1.SetStatusCondition(&agent.Status.Conditions, conditionsv1.Condition{
+		Type:    v1beta1.DeletingCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+}
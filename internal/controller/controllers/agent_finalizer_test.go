@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("finalizeAgent", func() {
+	var agent *v1beta1.Agent
+
+	tests := []struct {
+		name             string
+		bound            bool
+		unbindErr        error
+		deregisterErr    error
+		expectRemovable  bool
+		expectedErr      error
+		expectUnbindCall bool
+	}{
+		{
+			name:             "unbound host deregisters cleanly",
+			bound:            false,
+			expectRemovable:  true,
+			expectUnbindCall: false,
+		},
+		{
+			name:             "bound host is unbound then deregistered",
+			bound:            true,
+			expectRemovable:  true,
+			expectUnbindCall: true,
+		},
+		{
+			name:             "unbind failure blocks finalizer removal",
+			bound:            true,
+			unbindErr:        errors.New("backend unavailable"),
+			expectRemovable:  false,
+			expectedErr:      errors.New("backend unavailable"),
+			expectUnbindCall: true,
+		},
+		{
+			name:             "deregister failure blocks finalizer removal",
+			bound:            false,
+			deregisterErr:    errors.New("host not found"),
+			expectRemovable:  false,
+			expectedErr:      errors.New("host not found"),
+			expectUnbindCall: false,
+		},
+	}
+
+	for _, t := range tests {
+		t := t
+		It(t.name, func() {
+			agent = newAgent("test-agent", testNamespace, v1beta1.AgentSpec{})
+			if t.bound {
+				agent.Spec.ClusterDeploymentName = &v1beta1.ClusterReference{Name: "test-cluster", Namespace: testNamespace}
+			}
+
+			unbindCalled := false
+			removable, err := finalizeAgent(agent,
+				func() error {
+					unbindCalled = true
+					return t.unbindErr
+				},
+				func() error {
+					return t.deregisterErr
+				},
+			)
+
+			Expect(unbindCalled).To(Equal(t.expectUnbindCall))
+			Expect(removable).To(Equal(t.expectRemovable))
+			if t.expectedErr != nil {
+				Expect(err).To(MatchError(t.expectedErr))
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+})
+
+var _ = Describe("setDeletingCondition", func() {
+	It("sets DeletingReason when cleanup is still in progress", func() {
+		agent := newAgent("test-agent", testNamespace, v1beta1.AgentSpec{})
+		setDeletingCondition(agent, nil)
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.DeletingCondition)
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(v1beta1.DeletingReason))
+	})
+
+	It("sets DeleteFailedReason and surfaces the backend error when cleanup fails", func() {
+		agent := newAgent("test-agent", testNamespace, v1beta1.AgentSpec{})
+		setDeletingCondition(agent, errors.New("backend unavailable"))
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.DeletingCondition)
+		Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(v1beta1.DeleteFailedReason))
+		Expect(cond.Message).To(Equal("backend unavailable"))
+	})
+})
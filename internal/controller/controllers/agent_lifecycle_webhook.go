@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-agent-lifecycle-agent-install-openshift-io-v1beta1-agent,mutating=false,failurePolicy=fail,sideEffects=None,groups=agent-install.openshift.io,resources=agents,verbs=update;delete,versions=v1beta1,name=vagentlifecycle.kb.io,admissionReviewVersions=v1
+
+// AllowDeleteWhileInstallingAnnotation, set to "true", is the escape hatch an
+// operator uses to delete an Agent mid-installation despite
+// AgentLifecycleValidator's DELETE guard - for the rare case of abandoning a
+// host that will never finish (e.g. it's being decommissioned), rather than
+// waiting out an install that's already known to be going nowhere.
+const AllowDeleteWhileInstallingAnnotation = "agent-install.openshift.io/allow-delete-while-installing"
+
+// Structured reasons AgentLifecycleValidator returns so callers (the
+// assisted-service UI among them) can distinguish which lifecycle guard
+// fired instead of pattern-matching the message text.
+const (
+	ReasonInstallationInProgress     metav1.StatusReason = "AgentInstallationInProgress"
+	ReasonClusterDeploymentImmutable metav1.StatusReason = "AgentClusterDeploymentImmutable"
+	ReasonApprovalImmutable          metav1.StatusReason = "AgentApprovalImmutable"
+)
+
+// hostStagesAtOrAfterRebooting are the models.HostStage values a host only
+// reaches once it has rebooted into the target OS, the point past which
+// un-approving the Agent (spec.approved=false) can no longer stop the
+// install - the host is already running on the disk it was told to write
+// to - so AgentLifecycleValidator rejects the transition instead of letting
+// it set an expectation the reconciler can't honor.
+var hostStagesAtOrAfterRebooting = map[models.HostStage]bool{
+	models.HostStageRebooting: true,
+	models.HostStageJoined:    true,
+	models.HostStageDone:      true,
+}
+
+// preBindingHostStatuses are the models.HostStatus values a host can still
+// be unbound/rebound from. Once a host's DebugInfo.State leaves this set,
+// spec.clusterDeploymentName has already been acted on by the backend (disk
+// partitioning, ignition served, etc.), so AgentLifecycleValidator rejects
+// changing it out from under an install already underway.
+var preBindingHostStatuses = map[string]bool{
+	models.HostStatusDiscovering:     true,
+	models.HostStatusKnown:           true,
+	models.HostStatusKnownUnbound:    true,
+	models.HostStatusInsufficient:    true,
+	models.HostStatusDisconnected:    true,
+	models.HostStatusDisabled:        true,
+	models.HostStatusPendingForInput: true,
+}
+
+// AgentLifecycleValidator is a raw admission.Handler (rather than a
+// webhook.Validator on the Agent type itself, like ValidateInstallerArgs
+// uses) because it needs both the old and new object on UPDATE and the old
+// object on DELETE to compare state transitions, and it wants to control the
+// admissionv1.Status it returns instead of a plain error string.
+type AgentLifecycleValidator struct {
+	decoder *admission.Decoder
+}
+
+// SetupWebhookWithManager registers AgentLifecycleValidator's admission
+// endpoint with mgr.
+func (v *AgentLifecycleValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(
+		"/validate-agent-lifecycle-agent-install-openshift-io-v1beta1-agent",
+		&webhook.Admission{Handler: v},
+	)
+	return nil
+}
+
+// InjectDecoder is called by the webhook server at startup, per
+// admission.DecoderInjector.
+func (v *AgentLifecycleValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *AgentLifecycleValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	switch req.Operation {
+	case admissionv1.Delete:
+		return v.handleDelete(req)
+	case admissionv1.Update:
+		return v.handleUpdate(req)
+	default:
+		return admission.Allowed("")
+	}
+}
+
+func (v *AgentLifecycleValidator) handleDelete(req admission.Request) admission.Response {
+	agent := &v1beta1.Agent{}
+	if err := v.decoder.DecodeRaw(req.OldObject, agent); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	installing := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.InstalledCondition)
+	if installing == nil || installing.Reason != v1beta1.InstallationInProgressReason {
+		return admission.Allowed("")
+	}
+	if agent.Annotations[AllowDeleteWhileInstallingAnnotation] == "true" {
+		return admission.Allowed("")
+	}
+
+	return deniedf(ReasonInstallationInProgress,
+		"agent %s/%s is still %s; set the %q annotation to true to delete it anyway",
+		agent.Namespace, agent.Name, v1beta1.InstallationInProgressReason, AllowDeleteWhileInstallingAnnotation)
+}
+
+func (v *AgentLifecycleValidator) handleUpdate(req admission.Request) admission.Response {
+	oldAgent := &v1beta1.Agent{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldAgent); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	newAgent := &v1beta1.Agent{}
+	if err := v.decoder.Decode(req, newAgent); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if clusterDeploymentNameChanged(oldAgent, newAgent) && !preBindingHostStatuses[oldAgent.Status.DebugInfo.State] {
+		return deniedf(ReasonClusterDeploymentImmutable,
+			"agent %s/%s: spec.clusterDeploymentName cannot change once the host has moved past %s (current state: %s)",
+			oldAgent.Namespace, oldAgent.Name, models.HostStatusKnown, oldAgent.Status.DebugInfo.State)
+	}
+
+	if oldAgent.Spec.Approved && !newAgent.Spec.Approved && hostStagesAtOrAfterRebooting[oldAgent.Status.Progress.CurrentStage] {
+		return deniedf(ReasonApprovalImmutable,
+			"agent %s/%s: spec.approved cannot be set to false once the host has reached stage %s",
+			oldAgent.Namespace, oldAgent.Name, oldAgent.Status.Progress.CurrentStage)
+	}
+
+	return admission.Allowed("")
+}
+
+func clusterDeploymentNameChanged(oldAgent, newAgent *v1beta1.Agent) bool {
+	oldRef, newRef := oldAgent.Spec.ClusterDeploymentName, newAgent.Spec.ClusterDeploymentName
+	if oldRef == nil || newRef == nil {
+		return oldRef != newRef
+	}
+	return *oldRef != *newRef
+}
+
+// deniedf builds a Denied admission.Response carrying reason as the
+// structured Result.Reason so the assisted-service UI can key its display
+// off reason instead of parsing the free-form message.
+func deniedf(reason metav1.StatusReason, format string, args ...interface{}) admission.Response {
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: fmt.Sprintf(format, args...),
+				Reason:  reason,
+				Code:    http.StatusForbidden,
+			},
+		},
+	}
+}
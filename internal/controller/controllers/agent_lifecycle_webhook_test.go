@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/controller/scheme"
+	"github.com/openshift/assisted-service/models"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func rawAgent(agent *v1beta1.Agent) runtime.RawExtension {
+	data, err := json.Marshal(agent)
+	Expect(err).ToNot(HaveOccurred())
+	return runtime.RawExtension{Raw: data}
+}
+
+var _ = Describe("AgentLifecycleValidator", func() {
+	var (
+		validator *AgentLifecycleValidator
+		agent     *v1beta1.Agent
+	)
+
+	BeforeEach(func() {
+		decoder, err := admission.NewDecoder(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		validator = &AgentLifecycleValidator{}
+		Expect(validator.InjectDecoder(decoder)).To(Succeed())
+
+		agent = newAgent("test-agent", testNamespace, v1beta1.AgentSpec{Approved: true})
+	})
+
+	Context("DELETE", func() {
+		It("allows deleting an agent that isn't installing", func() {
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				OldObject: rawAgent(agent),
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies deleting an agent mid-installation", func() {
+			conditionsv1.SetStatusCondition(&agent.Status.Conditions, conditionsv1.Condition{
+				Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationInProgressReason,
+			})
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				OldObject: rawAgent(agent),
+			}})
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Reason).To(Equal(ReasonInstallationInProgress))
+		})
+
+		It("allows deleting an agent mid-installation when the override annotation is set", func() {
+			conditionsv1.SetStatusCondition(&agent.Status.Conditions, conditionsv1.Condition{
+				Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationInProgressReason,
+			})
+			agent.Annotations = map[string]string{AllowDeleteWhileInstallingAnnotation: "true"}
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				OldObject: rawAgent(agent),
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+		})
+	})
+
+	Context("UPDATE", func() {
+		It("allows changing spec.clusterDeploymentName while the host is still Known", func() {
+			agent.Status.DebugInfo.State = models.HostStatusKnown
+			updated := agent.DeepCopy()
+			updated.Spec.ClusterDeploymentName = &v1beta1.ClusterReference{Name: "other-cluster", Namespace: testNamespace}
+
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: rawAgent(agent),
+				Object:    rawAgent(updated),
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies changing spec.clusterDeploymentName once the host has moved past Known", func() {
+			agent.Status.DebugInfo.State = models.HostStatusInstalling
+			updated := agent.DeepCopy()
+			updated.Spec.ClusterDeploymentName = &v1beta1.ClusterReference{Name: "other-cluster", Namespace: testNamespace}
+
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: rawAgent(agent),
+				Object:    rawAgent(updated),
+			}})
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Reason).To(Equal(ReasonClusterDeploymentImmutable))
+		})
+
+		It("allows un-approving an agent before it has rebooted", func() {
+			agent.Status.Progress.CurrentStage = models.HostStageInstalling
+			updated := agent.DeepCopy()
+			updated.Spec.Approved = false
+
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: rawAgent(agent),
+				Object:    rawAgent(updated),
+			}})
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies un-approving an agent once it has reached HostStageRebooting", func() {
+			agent.Status.Progress.CurrentStage = models.HostStageRebooting
+			updated := agent.DeepCopy()
+			updated.Spec.Approved = false
+
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				OldObject: rawAgent(agent),
+				Object:    rawAgent(updated),
+			}})
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Reason).To(Equal(ReasonApprovalImmutable))
+		})
+	})
+})
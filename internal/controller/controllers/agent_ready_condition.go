@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// readySubConditions are always factored into ReadyCondition. Installed is
+// added on top of these once installation has actually started - see
+// setReadyCondition - so a freshly discovered host that hasn't begun
+// installing isn't held NotReady on Installed's InstallationNotStartedReason.
+var readySubConditions = []conditionsv1.ConditionType{
+	v1beta1.RequirementsMetCondition,
+	v1beta1.ConnectedCondition,
+	v1beta1.ValidatedCondition,
+	v1beta1.BoundCondition,
+}
+
+// setReadyCondition sets v1beta1.ReadyCondition on agent to the conjunction
+// of RequirementsMet, Connected, Validated and Bound, plus Installed once
+// installation has started, so callers get one stable field to watch
+// instead of reading every sub-condition themselves.
+func setReadyCondition(agent *v1beta1.Agent) {
+	subConditions := readySubConditions
+	if installed := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.InstalledCondition); installed != nil && installed.Reason != v1beta1.InstallationNotStartedReason {
+		subConditions = append(append([]conditionsv1.ConditionType{}, readySubConditions...), v1beta1.InstalledCondition)
+	}
+
+	status := corev1.ConditionTrue
+	reason := v1beta1.ReadyReason
+	message := v1beta1.ReadyMsg
+	for _, condType := range subConditions {
+		cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, condType)
+		if cond == nil || cond.Status != corev1.ConditionTrue {
+			status = corev1.ConditionFalse
+			reason = v1beta1.NotReadyReason
+			message = v1beta1.NotReadyMsg
+			break
+		}
+	}
+
+	conditionsv1.SetStatusCondition(&agent.Status.Conditions, conditionsv1.Condition{
+		Type:    v1beta1.ReadyCondition,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("setReadyCondition", func() {
+	// Mirrors the state table in TestConditions: for every combination of
+	// sub-conditions that table exercises, lock in whether ReadyCondition
+	// should read True or False.
+	tests := []struct {
+		name       string
+		conditions []conditionsv1.Condition
+		expectTrue bool
+	}{
+		{
+			name: "PendingForInput: RequirementsMet false",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionFalse, Reason: v1beta1.AgentNotReadyReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationNotStartedReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionFalse, Reason: v1beta1.ValidationsUserPendingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionTrue, Reason: v1beta1.BoundReason},
+			},
+			expectTrue: false,
+		},
+		{
+			name: "Known approved and bound: every sub-condition healthy, installation not started",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentReadyReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationNotStartedReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ValidationsPassingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionTrue, Reason: v1beta1.BoundReason},
+			},
+			expectTrue: true,
+		},
+		{
+			name: "KnownUnbound: Bound false",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentReadyReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationNotStartedReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ValidationsPassingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionFalse, Reason: v1beta1.UnboundReason},
+			},
+			expectTrue: false,
+		},
+		{
+			name: "Installing: installation started and Installed unhealthy",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentAlreadyInstallingReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationInProgressReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ValidationsPassingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionTrue, Reason: v1beta1.BoundReason},
+			},
+			expectTrue: false,
+		},
+		{
+			name: "Installed: installation started and Installed healthy",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentInstallationStoppedReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionTrue, Reason: v1beta1.InstalledReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ValidationsPassingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionTrue, Reason: v1beta1.BoundReason},
+			},
+			expectTrue: true,
+		},
+		{
+			name: "Disconnected: Connected false",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionFalse, Reason: v1beta1.AgentNotReadyReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionFalse, Reason: v1beta1.AgentDisconnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.InstallationNotStartedReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ValidationsPassingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionTrue, Reason: v1beta1.BoundReason},
+			},
+			expectTrue: false,
+		},
+		{
+			name: "Binding: every sub-condition unhealthy",
+			conditions: []conditionsv1.Condition{
+				{Type: v1beta1.RequirementsMetCondition, Status: corev1.ConditionFalse, Reason: v1beta1.BindingReason},
+				{Type: v1beta1.ConnectedCondition, Status: corev1.ConditionTrue, Reason: v1beta1.AgentConnectedReason},
+				{Type: v1beta1.InstalledCondition, Status: corev1.ConditionFalse, Reason: v1beta1.BindingReason},
+				{Type: v1beta1.ValidatedCondition, Status: corev1.ConditionFalse, Reason: v1beta1.BindingReason},
+				{Type: v1beta1.BoundCondition, Status: corev1.ConditionFalse, Reason: v1beta1.BindingReason},
+			},
+			expectTrue: false,
+		},
+	}
+
+	for i := range tests {
+		t := tests[i]
+		It(t.name, func() {
+			agent := newAgent("test-agent", testNamespace, v1beta1.AgentSpec{})
+			agent.Status.Conditions = t.conditions
+
+			setReadyCondition(agent)
+
+			cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.ReadyCondition)
+			Expect(cond).ToNot(BeNil())
+			if t.expectTrue {
+				Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+				Expect(cond.Reason).To(Equal(v1beta1.ReadyReason))
+			} else {
+				Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(v1beta1.NotReadyReason))
+			}
+		})
+	}
+})
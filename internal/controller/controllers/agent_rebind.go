@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/restapi/operations/installer"
+)
+
+// rebindHost moves host directly onto targetClusterID via a single call to
+// Installer.RebindHostInternal, so a ClusterDeploymentName change that points
+// an already-bound Agent at a *different* cluster resolves in one reconcile
+// with one SpecSyncedCondition transition, instead of this reconcile
+// unbinding and a later one binding (during which SpecSyncedCondition flaps
+// from "synced" back to "in progress").
+//
+// Backends that don't yet expose the rebind endpoint report it as
+// http.StatusNotImplemented; on that response only, rebindHost degrades to
+// the older unbind-then-bind sequence so older backends keep working.
+func (r *AgentReconciler) rebindHost(ctx context.Context, host *common.Host, targetClusterID strfmt.UUID) (*common.Host, error) {
+	rebound, err := r.Installer.RebindHostInternal(ctx, installer.RebindHostParams{
+		InfraEnvID: host.InfraEnvID,
+		HostID:     *host.ID,
+		RebindHostParams: &models.RebindHostParams{
+			ClusterID: &targetClusterID,
+		},
+	})
+	if err == nil {
+		return rebound, nil
+	}
+	if !isRebindUnsupported(err) {
+		return nil, err
+	}
+
+	unbound, err := r.Installer.UnbindHostInternal(ctx, installer.UnbindHostParams{
+		InfraEnvID: host.InfraEnvID,
+		HostID:     *host.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Installer.BindHostInternal(ctx, installer.BindHostParams{
+		InfraEnvID: unbound.InfraEnvID,
+		HostID:     *unbound.ID,
+		BindHostParams: &models.BindHostParams{
+			ClusterID: &targetClusterID,
+		},
+	})
+}
+
+// apiStatusError is implemented by the error common.NewApiError returns;
+// declared locally so rebindHost doesn't need to know its concrete type.
+type apiStatusError interface {
+	StatusCode() int32
+}
+
+func isRebindUnsupported(err error) bool {
+	statusErr, ok := err.(apiStatusError)
+	return ok && statusErr.StatusCode() == http.StatusNotImplemented
+}
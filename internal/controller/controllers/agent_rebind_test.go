@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/internal/bminventory"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+)
+
+var _ = Describe("rebindHost", func() {
+	var (
+		ctx                   = context.Background()
+		mockCtrl              *gomock.Controller
+		mockInstallerInternal *bminventory.MockInstallerInternals
+		hr                    *AgentReconciler
+		hostId                strfmt.UUID
+		host                  *common.Host
+		targetClusterID       strfmt.UUID
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockInstallerInternal = bminventory.NewMockInstallerInternals(mockCtrl)
+		hr = &AgentReconciler{Installer: mockInstallerInternal}
+		hostId = strfmt.UUID(uuid.New().String())
+		targetClusterID = strfmt.UUID(uuid.New().String())
+		host = &common.Host{Host: models.Host{ID: &hostId}}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("rebinds in a single backend call when the backend supports it", func() {
+		rebound := &common.Host{Host: models.Host{ID: &hostId, ClusterID: &targetClusterID}}
+		mockInstallerInternal.EXPECT().RebindHostInternal(gomock.Any(), gomock.Any()).Return(rebound, nil)
+
+		result, err := hr.rebindHost(ctx, host, targetClusterID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ClusterID).To(Equal(&targetClusterID))
+	})
+
+	It("falls back to unbind-then-bind when the backend doesn't support rebind", func() {
+		rebound := &common.Host{Host: models.Host{ID: &hostId, ClusterID: &targetClusterID}}
+		mockInstallerInternal.EXPECT().RebindHostInternal(gomock.Any(), gomock.Any()).
+			Return(nil, common.NewApiError(http.StatusNotImplemented, errors.New("rebind not supported")))
+		mockInstallerInternal.EXPECT().UnbindHostInternal(gomock.Any(), gomock.Any()).Return(host, nil)
+		mockInstallerInternal.EXPECT().BindHostInternal(gomock.Any(), gomock.Any()).Return(rebound, nil)
+
+		result, err := hr.rebindHost(ctx, host, targetClusterID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ClusterID).To(Equal(&targetClusterID))
+	})
+
+	It("propagates any other rebind error without falling back", func() {
+		mockInstallerInternal.EXPECT().RebindHostInternal(gomock.Any(), gomock.Any()).
+			Return(nil, common.NewApiError(http.StatusInternalServerError, errors.New("backend error")))
+
+		_, err := hr.rebindHost(ctx, host, targetClusterID)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// startupTaintKeyPrefix identifies a "node.startup/*" taint.
+// Spec.IgnoreAllStartupTaints removes every taint under this prefix
+// regardless of whether it's individually listed in Spec.StartupTaints, the
+// same escape hatch CSI node drivers rely on for a taint they didn't
+// anticipate at install time.
+const startupTaintKeyPrefix = "node.startup/"
+
+// ClearStartupTaints removes node's startup taints once the host has
+// actually finished joining, mirroring how CSI node drivers gate scheduling
+// on readiness: a taint matching a key in agent.Spec.StartupTaints (or any
+// "node.startup/*" taint when agent.Spec.IgnoreAllStartupTaints is set) is
+// only patched off once node is Ready AND hostStage is models.HostStageDone,
+// so a Node that's technically Ready but whose host install is still
+// finishing up doesn't get scheduled onto prematurely.
+//
+// It returns whether every targeted taint is now confirmed absent from
+// node, which the caller uses to decide whether to set
+// v1beta1.StartupTaintsClearedCondition true.
+func ClearStartupTaints(spokeClient SpokeK8sClient, agent *v1beta1.Agent, node *corev1.Node, hostStage models.HostStage) (bool, error) {
+	keys := startupTaintKeysToClear(agent, node)
+	if len(keys) == 0 {
+		return true, nil
+	}
+
+	if node == nil || !nodeIsReady(node) || hostStage != models.HostStageDone {
+		return false, nil
+	}
+
+	for _, key := range keys {
+		if err := spokeClient.RemoveNodeTaint(node.Name, key); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// startupTaintKeysToClear returns the keys of node's current taints that
+// ClearStartupTaints is responsible for: ones explicitly listed in
+// agent.Spec.StartupTaints, plus every "node.startup/*" taint when
+// agent.Spec.IgnoreAllStartupTaints is set.
+func startupTaintKeysToClear(agent *v1beta1.Agent, node *corev1.Node) []string {
+	if node == nil {
+		return nil
+	}
+
+	wanted := map[string]bool{}
+	for _, t := range agent.Spec.StartupTaints {
+		wanted[t.Key] = true
+	}
+
+	var keys []string
+	for _, t := range node.Spec.Taints {
+		if wanted[t.Key] || (agent.Spec.IgnoreAllStartupTaints && isStartupTaint(t.Key)) {
+			keys = append(keys, t.Key)
+		}
+	}
+	return keys
+}
+
+func isStartupTaint(key string) bool {
+	return strings.HasPrefix(key, startupTaintKeyPrefix)
+}
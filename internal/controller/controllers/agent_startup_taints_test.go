@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ClearStartupTaints", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		spokeClient *MockSpokeK8sClient
+		agent       *v1beta1.Agent
+		node        *corev1.Node
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		spokeClient = NewMockSpokeK8sClient(mockCtrl)
+		agent = newAgent("test-agent", testNamespace, v1beta1.AgentSpec{
+			StartupTaints: []corev1.Taint{{Key: "node.startup/assisted", Effect: corev1.TaintEffectNoSchedule}},
+		})
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec: corev1.NodeSpec{
+				Taints: []corev1.Taint{{Key: "node.startup/assisted", Effect: corev1.TaintEffectNoSchedule}},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("does not remove the taint while the node isn't Ready", func() {
+		node.Status.Conditions[0].Status = corev1.ConditionFalse
+		cleared, err := ClearStartupTaints(spokeClient, agent, node, models.HostStageDone)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleared).To(BeFalse())
+	})
+
+	It("does not remove the taint while the host hasn't reached HostStageDone", func() {
+		cleared, err := ClearStartupTaints(spokeClient, agent, node, models.HostStageRebooting)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleared).To(BeFalse())
+	})
+
+	It("removes the taint once the node is Ready and the host is done", func() {
+		spokeClient.EXPECT().RemoveNodeTaint("node-1", "node.startup/assisted").Return(nil)
+		cleared, err := ClearStartupTaints(spokeClient, agent, node, models.HostStageDone)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleared).To(BeTrue())
+	})
+
+	It("is a no-op when the taint is already absent", func() {
+		node.Spec.Taints = nil
+		cleared, err := ClearStartupTaints(spokeClient, agent, node, models.HostStageDone)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleared).To(BeTrue())
+	})
+
+	It("also removes unlisted node.startup/* taints when IgnoreAllStartupTaints is set", func() {
+		agent.Spec.StartupTaints = nil
+		agent.Spec.IgnoreAllStartupTaints = true
+		node.Spec.Taints = []corev1.Taint{{Key: "node.startup/other-operator", Effect: corev1.TaintEffectNoSchedule}}
+		spokeClient.EXPECT().RemoveNodeTaint("node-1", "node.startup/other-operator").Return(nil)
+		cleared, err := ClearStartupTaints(spokeClient, agent, node, models.HostStageDone)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleared).To(BeTrue())
+	})
+})
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// allowedInstallerArgs is the set of coreos-installer flags the backend is
+// known to accept for Spec.InstallerArgs. validateInstallerArgs rejects
+// anything else locally instead of letting it reach V2UpdateHostInstallerArgsInternal,
+// where an unrecognized flag is indistinguishable from a transient backend error.
+var allowedInstallerArgs = map[string]bool{
+	"--append-karg":       true,
+	"--delete-karg":       true,
+	"--save-partlabel":    true,
+	"--save-partindex":    true,
+	"--insecure":          true,
+	"--insecure-ignition": true,
+	"--image-url":         true,
+	"--copy-network":      true,
+	"--network-dir":       true,
+	"-n":                  true,
+	"--dry-run":           true,
+}
+
+// ignitionConfig is the minimal shape validateIgnitionConfigOverride needs
+// out of a Butane/Ignition v3 config: just enough of the spec to confirm
+// Spec.IgnitionConfigOverrides parses as JSON and declares a version this
+// cluster's ignition generator supports.
+type ignitionConfig struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+}
+
+// validateIgnitionConfigOverride parses raw as a Butane/Ignition v3 config,
+// checking it's valid JSON and that ignition.version is a 3.x version, so the
+// reconciler can reject a malformed override locally with InvalidIgnitionOverrideReason
+// instead of round-tripping it to V2UpdateHostIgnitionInternal and surfacing
+// whatever opaque error the backend happens to return for it.
+func validateIgnitionConfigOverride(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	var cfg ignitionConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return errors.Wrap(err, "ignitionConfigOverrides is not valid JSON")
+	}
+	if !strings.HasPrefix(cfg.Ignition.Version, "3.") {
+		return errors.Errorf("unsupported ignition.version %q, expected a 3.x version", cfg.Ignition.Version)
+	}
+	return nil
+}
+
+// validateInstallerArgs parses raw as a JSON array of coreos-installer
+// arguments and checks every flag (an element starting with "-") against
+// allowedInstallerArgs, so the reconciler can reject one locally with
+// InvalidInstallerArgsReason instead of passing it through to
+// V2UpdateHostInstallerArgsInternal.
+func validateInstallerArgs(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	var args []string
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return errors.Wrap(err, "installerArgs is not a valid JSON string array")
+	}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !allowedInstallerArgs[arg] {
+			return errors.Errorf("installer arg %q is not in the allow-list", arg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validateIgnitionConfigOverride", func() {
+	It("accepts an empty override", func() {
+		Expect(validateIgnitionConfigOverride("")).To(Succeed())
+	})
+
+	It("accepts a well-formed ignition v3 override", func() {
+		Expect(validateIgnitionConfigOverride(`{"ignition": {"version": "3.1.0"}}`)).To(Succeed())
+	})
+
+	It("rejects malformed JSON", func() {
+		Expect(validateIgnitionConfigOverride(`{"ignition": "version": "3.1.0"}`)).To(HaveOccurred())
+	})
+
+	It("rejects an unsupported ignition.version", func() {
+		Expect(validateIgnitionConfigOverride(`{"ignition": {"version": "2.2.0"}}`)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("validateInstallerArgs", func() {
+	It("accepts an empty value", func() {
+		Expect(validateInstallerArgs("")).To(Succeed())
+	})
+
+	It("accepts allow-listed flags", func() {
+		Expect(validateInstallerArgs(`["--append-karg", "ip=dhcp", "--save-partindex", "1", "-n"]`)).To(Succeed())
+	})
+
+	It("rejects malformed JSON", func() {
+		Expect(validateInstallerArgs(`"--append-karg"]`)).To(HaveOccurred())
+	})
+
+	It("rejects a flag outside the allow-list", func() {
+		Expect(validateInstallerArgs(`["--not-a-real-flag"]`)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// defaultJoinStatusRequeueAfter is how soon AgentClusterInstallStatusReconciler
+// retries an AgentClusterInstall that still has an Agent blocked on a pending
+// CSR or an unready Node, analogous to AgentReconciler's
+// ApproveCsrsRequeueDuration.
+const defaultJoinStatusRequeueAfter = 30 * time.Second
+
+// AgentClusterInstallStatusReconciler watches Agent CRs but reconciles their
+// parent AgentClusterInstall, aggregating the per-host join progress produced
+// by the day-2 CSR-approval/startup-taint flow into a cluster-wide rollup -
+// the same role Karmada's cluster_status_controller plays for member cluster
+// health, just keyed on AgentClusterInstall instead of a Karmada Cluster.
+//
+// hiveextension/v1beta1 isn't vendored in this tree, so the aggregate this
+// reconciler computes (JoinStatus) is a plain struct rather than a patch
+// against AgentClusterInstallStatus; the reconciler is responsible for
+// copying its fields onto status and setting AllNodesJoinedConditionType.
+type AgentClusterInstallStatusReconciler struct {
+	client.Client
+	APIReader             client.Reader
+	Scheme                *runtime.Scheme
+	Log                   logrus.FieldLogger
+	SpokeK8sClientFactory SpokeK8sClientFactory
+	RequeueAfter          time.Duration
+}
+
+// JoinStatus is the per-AgentClusterInstall rollup
+// AgentClusterInstallStatusReconciler computes from the Agents bound to its
+// ClusterDeployment.
+type JoinStatus struct {
+	// HostStageCounts counts bound Agents by their host's current
+	// models.HostStage, restricted to the day-2-join-relevant stages
+	// (Rebooting, Joined, Done).
+	HostStageCounts map[models.HostStage]int
+
+	// ExpectedNodesCount is the number of Agents bound to the
+	// ClusterDeployment.
+	ExpectedNodesCount int
+
+	// JoinedNodesCount is the number of those Agents whose host has reached
+	// models.HostStageDone and whose spoke Node (if reachable) is Ready.
+	JoinedNodesCount int
+
+	// AllNodesJoined is true when JoinedNodesCount == ExpectedNodesCount and
+	// ExpectedNodesCount > 0.
+	AllNodesJoined bool
+
+	// PendingCSRAgents names the Agents (namespace/name) this rollup found
+	// still blocked on a CSR neither approved nor rejected on the spoke.
+	PendingCSRAgents []string
+}
+
+func (r *AgentClusterInstallStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithField("agent_cluster_install", req.NamespacedName)
+
+	aci := &hiveext.AgentClusterInstall{}
+	if err := r.Get(ctx, req.NamespacedName, aci); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	agents := &v1beta1.AgentList{}
+	if err := r.List(ctx, agents, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	bound := boundAgents(agents.Items, aci.Spec.ClusterDeploymentRef.Name)
+
+	spokeClient, err := r.spokeClientFor(ctx, req.Namespace, aci.Spec.ClusterDeploymentRef.Name)
+	if err != nil {
+		log.WithError(err).Warn("spoke cluster unreachable, computing join status without live node readiness")
+	}
+
+	status := computeJoinStatus(bound, spokeClient)
+
+	requeueAfter := r.RequeueAfter
+	if requeueAfter == 0 {
+		requeueAfter = defaultJoinStatusRequeueAfter
+	}
+	if status.AllNodesJoined {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// boundAgents filters agents down to the ones bound to the ClusterDeployment
+// named clusterDeploymentName.
+func boundAgents(agents []v1beta1.Agent, clusterDeploymentName string) []v1beta1.Agent {
+	var bound []v1beta1.Agent
+	for _, agent := range agents {
+		if agent.Spec.ClusterDeploymentName != nil && agent.Spec.ClusterDeploymentName.Name == clusterDeploymentName {
+			bound = append(bound, agent)
+		}
+	}
+	return bound
+}
+
+// spokeClientFor builds a SpokeK8sClient from the ClusterDeployment's admin
+// kubeconfig Secret, or returns a nil client (not an error the caller should
+// fail the reconcile over) if the Secret doesn't exist yet - the install may
+// not have reached that point.
+func (r *AgentClusterInstallStatusReconciler) spokeClientFor(ctx context.Context, namespace, clusterDeploymentName string) (SpokeK8sClient, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: namespace, Name: fmt.Sprintf(adminKubeConfigStringTemplate, clusterDeploymentName)}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting admin kubeconfig secret %s", key)
+	}
+	return r.SpokeK8sClientFactory.Create(secret)
+}
+
+// computeJoinStatus aggregates bound by host stage and Node readiness.
+// spokeClient may be nil (spoke unreachable); a nil client treats every
+// Agent as not-yet-joined rather than erroring, so a transient spoke outage
+// degrades the rollup instead of blocking it entirely.
+func computeJoinStatus(bound []v1beta1.Agent, spokeClient SpokeK8sClient) JoinStatus {
+	status := JoinStatus{
+		HostStageCounts:    map[models.HostStage]int{},
+		ExpectedNodesCount: len(bound),
+	}
+
+	for i := range bound {
+		agent := &bound[i]
+		stage := agent.Status.Progress.CurrentStage
+		status.HostStageCounts[stage]++
+
+		if stage != models.HostStageDone {
+			continue
+		}
+
+		if spokeClient == nil {
+			continue
+		}
+
+		hostname := expectedHostname(agent)
+		if hostname == "" {
+			continue
+		}
+		node, err := spokeClient.GetNode(hostname)
+		if err != nil || node == nil || !nodeIsReady(node) {
+			continue
+		}
+		status.JoinedNodesCount++
+	}
+
+	status.AllNodesJoined = status.ExpectedNodesCount > 0 && status.JoinedNodesCount == status.ExpectedNodesCount
+	status.PendingCSRAgents = pendingCSRAgents(bound, spokeClient)
+	return status
+}
+
+// pendingCSRAgents names every bound Agent whose spoke CSR (matched by the
+// "system:node:<hostname>" requester/CN AgentCSRApprover checks) is still
+// outstanding: present on the spoke but neither Approved nor Denied.
+func pendingCSRAgents(bound []v1beta1.Agent, spokeClient SpokeK8sClient) []string {
+	if spokeClient == nil {
+		return nil
+	}
+
+	csrs, err := spokeClient.ListCsrs()
+	if err != nil || csrs == nil {
+		return nil
+	}
+
+	pendingHostnames := map[string]bool{}
+	for i := range csrs.Items {
+		if csrApprovalDecided(&csrs.Items[i]) {
+			continue
+		}
+		pendingHostnames[pendingCSRHostname(&csrs.Items[i])] = true
+	}
+
+	var names []string
+	for i := range bound {
+		agent := &bound[i]
+		if pendingHostnames[expectedHostname(agent)] {
+			names = append(names, client.ObjectKeyFromObject(agent).String())
+		}
+	}
+	return names
+}
+
+// SetupWithManager registers AgentClusterInstallStatusReconciler, watching
+// Agent CRs but mapping each change back onto the owning AgentClusterInstall
+// so the aggregate rollup stays current without AgentClusterInstall itself
+// needing to change.
+func (r *AgentClusterInstallStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return watchAgentsForClusterInstall(ctrl.NewControllerManagedBy(mgr).For(&hiveext.AgentClusterInstall{}), r.Client).Complete(r)
+}
+
+func watchAgentsForClusterInstall(bldr *builder.Builder, c client.Client) *builder.Builder {
+	return bldr.Watches(
+		&source.Kind{Type: &v1beta1.Agent{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			return agentClusterInstallRequestsForAgent(context.Background(), c, obj)
+		}),
+	)
+}
+
+// agentClusterInstallRequestsForAgent resolves the AgentClusterInstall
+// driving agentObj's ClusterDeployment, so a Status/Progress change on one
+// Agent re-enqueues the shared rollup instead of only the Agent itself.
+func agentClusterInstallRequestsForAgent(ctx context.Context, c client.Client, agentObj client.Object) []reconcile.Request {
+	agent, ok := agentObj.(*v1beta1.Agent)
+	if !ok || agent.Spec.ClusterDeploymentName == nil {
+		return nil
+	}
+
+	acis := &hiveext.AgentClusterInstallList{}
+	if err := c.List(ctx, acis, client.InNamespace(agent.Spec.ClusterDeploymentName.Namespace)); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range acis.Items {
+		aci := &acis.Items[i]
+		if aci.Spec.ClusterDeploymentRef.Name == agent.Spec.ClusterDeploymentName.Name {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(aci)})
+		}
+	}
+	return requests
+}
+
+// csrApprovalDecided reports whether csr already carries an Approved or
+// Denied condition, so pendingCSRAgents only reports ones AgentCSRApprover
+// (or an operator) hasn't acted on yet.
+func csrApprovalDecided(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, cond := range csr.Status.Conditions {
+		if cond.Type == certificatesv1.CertificateApproved || cond.Type == certificatesv1.CertificateDenied {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingCSRHostname extracts the node hostname a pending CSR's requester
+// identity is for, from either a "system:node:<hostname>" common name
+// (serving CSR) or the matching requester username (client CSR requested on
+// the node's own behalf once it has a client cert). Returns "" if csr
+// doesn't carry a recognizable node identity.
+func pendingCSRHostname(csr *certificatesv1.CertificateSigningRequest) string {
+	if strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) {
+		return strings.TrimPrefix(csr.Spec.Username, nodeUserPrefix)
+	}
+	req, err := parseCSRRequest(csr.Spec.Request)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(req.Subject.CommonName, nodeUserPrefix)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func joinStatusTestAgent(name, hostname string, stage models.HostStage) v1beta1.Agent {
+	agent := newAgent(name, testNamespace, v1beta1.AgentSpec{
+		Hostname:              hostname,
+		ClusterDeploymentName: &v1beta1.ClusterReference{Name: "test-cluster", Namespace: testNamespace},
+	})
+	agent.Status.Progress.CurrentStage = stage
+	return *agent
+}
+
+func readyNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+var _ = Describe("computeJoinStatus", func() {
+	var (
+		mockCtrl    *gomock.Controller
+		spokeClient *MockSpokeK8sClient
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		spokeClient = NewMockSpokeK8sClient(mockCtrl)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("counts bound agents by host stage", func() {
+		bound := []v1beta1.Agent{
+			joinStatusTestAgent("a1", "host-1", models.HostStageRebooting),
+			joinStatusTestAgent("a2", "host-2", models.HostStageRebooting),
+			joinStatusTestAgent("a3", "host-3", models.HostStageJoined),
+		}
+		status := computeJoinStatus(bound, nil)
+		Expect(status.HostStageCounts[models.HostStageRebooting]).To(Equal(2))
+		Expect(status.HostStageCounts[models.HostStageJoined]).To(Equal(1))
+		Expect(status.ExpectedNodesCount).To(Equal(3))
+	})
+
+	It("is not AllNodesJoined until every bound agent's node is Ready and Done", func() {
+		bound := []v1beta1.Agent{
+			joinStatusTestAgent("a1", "host-1", models.HostStageDone),
+			joinStatusTestAgent("a2", "host-2", models.HostStageRebooting),
+		}
+		spokeClient.EXPECT().GetNode("host-1").Return(readyNode("host-1"), nil)
+		status := computeJoinStatus(bound, spokeClient)
+		Expect(status.JoinedNodesCount).To(Equal(1))
+		Expect(status.AllNodesJoined).To(BeFalse())
+	})
+
+	It("is AllNodesJoined once every bound agent has reached Done with a Ready node", func() {
+		bound := []v1beta1.Agent{
+			joinStatusTestAgent("a1", "host-1", models.HostStageDone),
+			joinStatusTestAgent("a2", "host-2", models.HostStageDone),
+		}
+		spokeClient.EXPECT().GetNode("host-1").Return(readyNode("host-1"), nil)
+		spokeClient.EXPECT().GetNode("host-2").Return(readyNode("host-2"), nil)
+		status := computeJoinStatus(bound, spokeClient)
+		Expect(status.JoinedNodesCount).To(Equal(2))
+		Expect(status.AllNodesJoined).To(BeTrue())
+	})
+
+	It("does not count a Done host whose node isn't Ready yet", func() {
+		bound := []v1beta1.Agent{joinStatusTestAgent("a1", "host-1", models.HostStageDone)}
+		notReady := readyNode("host-1")
+		notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+		spokeClient.EXPECT().GetNode("host-1").Return(notReady, nil)
+		status := computeJoinStatus(bound, spokeClient)
+		Expect(status.JoinedNodesCount).To(Equal(0))
+		Expect(status.AllNodesJoined).To(BeFalse())
+	})
+
+	It("treats every agent as not-yet-joined when the spoke is unreachable", func() {
+		bound := []v1beta1.Agent{joinStatusTestAgent("a1", "host-1", models.HostStageDone)}
+		status := computeJoinStatus(bound, nil)
+		Expect(status.JoinedNodesCount).To(Equal(0))
+		Expect(status.AllNodesJoined).To(BeFalse())
+	})
+
+	It("reports agents whose CSR is pending neither Approved nor Denied", func() {
+		bound := []v1beta1.Agent{
+			joinStatusTestAgent("a1", "host-1", models.HostStageRebooting),
+			joinStatusTestAgent("a2", "host-2", models.HostStageRebooting),
+		}
+		csr := newClientCSR(nodeBootstrapperUsername, nodeUserPrefix+"host-1", time.Now())
+		spokeClient.EXPECT().ListCsrs().Return(&certificatesv1.CertificateSigningRequestList{Items: []certificatesv1.CertificateSigningRequest{*csr}}, nil)
+		status := computeJoinStatus(bound, spokeClient)
+		Expect(status.PendingCSRAgents).To(ConsistOf(testNamespace + "/a1"))
+	})
+})
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/bminventory"
+	"github.com/openshift/assisted-service/restapi/operations/installer"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultAgentHealthCheckRequeueAfter is how soon AgentHealthCheckReconciler
+// re-scans its selected Agents when no unhealthy candidate needed handling
+// this time, so a condition that is still accumulating towards its timeout
+// gets re-evaluated without waiting for an unrelated watch event.
+const defaultAgentHealthCheckRequeueAfter = time.Minute
+
+// AgentHealthCheckReconciler reconciles an AgentHealthCheck object, modeled on
+// Cluster API's MachineHealthCheck controller: it scans the Agents matched by
+// Spec.Selector, determines which of them have held one of
+// Spec.UnhealthyConditions past its timeout, and remediates up to
+// Spec.MaxUnhealthy of them per reconcile.
+type AgentHealthCheckReconciler struct {
+	client.Client
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	Log       logrus.FieldLogger
+	Installer bminventory.InstallerInternals
+	Recorder  record.EventRecorder
+}
+
+func (r *AgentHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithField("agent_health_check", req.NamespacedName)
+
+	healthCheck := &v1beta1.AgentHealthCheck{}
+	if err := r.Get(ctx, req.NamespacedName, healthCheck); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&healthCheck.Spec.Selector)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "invalid selector")
+	}
+
+	agents := &v1beta1.AgentList{}
+	if err := r.List(ctx, agents, client.InNamespace(req.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "listing selected agents")
+	}
+
+	var targets []*v1beta1.Agent
+	currentHealthy := int32(0)
+	for i := range agents.Items {
+		agent := &agents.Items[i]
+		if _, alreadyRemediating := agent.Annotations[v1beta1.RemediationAnnotation]; alreadyRemediating {
+			targets = append(targets, agent)
+			continue
+		}
+		unhealthy, err := r.isUnhealthy(ctx, healthCheck, agent)
+		if err != nil {
+			log.WithError(err).Warnf("failed to evaluate health of agent %s, treating as healthy this reconcile", agent.Name)
+			currentHealthy++
+			continue
+		}
+		if !unhealthy {
+			currentHealthy++
+			continue
+		}
+		if r.installPaused(ctx, agent) {
+			log.Infof("agent %s is unhealthy but its cluster deployment is still installing, skipping remediation", agent.Name)
+			continue
+		}
+		targets = append(targets, agent)
+	}
+
+	budget := maxUnhealthyBudget(healthCheck.Spec.MaxUnhealthy, len(agents.Items))
+	remediationsAllowed := budget - int32(len(targets))
+	if remediationsAllowed < 0 {
+		remediationsAllowed = 0
+	}
+
+	remediated := int32(0)
+	for _, agent := range targets {
+		if _, alreadyRemediating := agent.Annotations[v1beta1.RemediationAnnotation]; alreadyRemediating {
+			continue
+		}
+		if remediated >= budget {
+			break
+		}
+		if err := r.remediate(ctx, healthCheck, agent); err != nil {
+			log.WithError(err).Errorf("failed to remediate agent %s", agent.Name)
+			continue
+		}
+		remediated++
+	}
+
+	healthCheck.Status.ExpectedHosts = int32(len(agents.Items))
+	healthCheck.Status.CurrentHealthy = currentHealthy
+	healthCheck.Status.RemediationsAllowed = remediationsAllowed
+	healthCheck.Status.Targets = make([]corev1.ObjectReference, 0, len(targets))
+	for _, agent := range targets {
+		healthCheck.Status.Targets = append(healthCheck.Status.Targets, corev1.ObjectReference{
+			Kind:      "Agent",
+			Name:      agent.Name,
+			Namespace: agent.Namespace,
+			UID:       agent.UID,
+		})
+	}
+	if err := r.Status().Update(ctx, healthCheck); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "updating agent health check status")
+	}
+
+	return ctrl.Result{RequeueAfter: defaultAgentHealthCheckRequeueAfter}, nil
+}
+
+// isUnhealthy reports whether agent matches any of healthCheck's
+// UnhealthyConditions for at least that condition's Timeout.
+func (r *AgentHealthCheckReconciler) isUnhealthy(ctx context.Context, healthCheck *v1beta1.AgentHealthCheck, agent *v1beta1.Agent) (bool, error) {
+	for _, uc := range healthCheck.Spec.UnhealthyConditions {
+		if uc.HostStatus != "" {
+			stuck, err := r.hostStatusStuckSince(ctx, agent, uc.HostStatus, uc.Timeout.Duration)
+			if err != nil {
+				return false, err
+			}
+			if stuck {
+				return true, nil
+			}
+			continue
+		}
+
+		condition := conditionsv1.FindStatusCondition(agent.Status.Conditions, conditionsv1.ConditionType(uc.Type))
+		if condition == nil {
+			continue
+		}
+		if condition.Status != uc.Status {
+			continue
+		}
+		if time.Since(condition.LastTransitionTime.Time) >= uc.Timeout.Duration {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hostStatusStuckSince asks the backend for the host behind agent and reports
+// whether its status has been status continuously for at least since.
+func (r *AgentHealthCheckReconciler) hostStatusStuckSince(ctx context.Context, agent *v1beta1.Agent, status string, since time.Duration) (bool, error) {
+	host, err := r.Installer.GetHostByKubeKey(client.ObjectKeyFromObject(agent))
+	if err != nil {
+		return false, errors.Wrapf(err, "getting host for agent %s", agent.Name)
+	}
+	if host == nil || string(host.Status) != status {
+		return false, nil
+	}
+	return time.Since(time.Time(host.StatusUpdatedAt)) >= since, nil
+}
+
+// installPaused reports whether agent's bound ClusterDeployment is still
+// installing, in which case AgentHealthCheck should not remediate it yet to
+// avoid thrashing the install.
+func (r *AgentHealthCheckReconciler) installPaused(ctx context.Context, agent *v1beta1.Agent) bool {
+	if agent.Spec.ClusterDeploymentName == nil {
+		return false
+	}
+	cd := &hivev1.ClusterDeployment{}
+	key := client.ObjectKey{Name: agent.Spec.ClusterDeploymentName.Name, Namespace: agent.Spec.ClusterDeploymentName.Namespace}
+	if err := r.Get(ctx, key, cd); err != nil {
+		return false
+	}
+	return !cd.Status.Installed
+}
+
+// remediate executes healthCheck's RemediationStrategy against agent and
+// annotates it so a later reconcile doesn't remediate it again while the
+// action is still pending.
+func (r *AgentHealthCheckReconciler) remediate(ctx context.Context, healthCheck *v1beta1.AgentHealthCheck, agent *v1beta1.Agent) error {
+	r.Recorder.Eventf(agent, corev1.EventTypeWarning, "AgentUnhealthy", "agent %s failed an AgentHealthCheck unhealthy condition and is being remediated", agent.Name)
+
+	switch healthCheck.Spec.RemediationStrategy {
+	case v1beta1.RemediationStrategyReboot:
+		if err := r.rebootHost(agent); err != nil {
+			return err
+		}
+	case v1beta1.RemediationStrategyReprovision:
+		agent.Spec.ClusterDeploymentName = nil
+		if err := r.Update(ctx, agent); err != nil {
+			return errors.Wrapf(err, "clearing cluster deployment on agent %s for reprovisioning", agent.Name)
+		}
+	case v1beta1.RemediationStrategyAnnotateOnly, "":
+		// no action beyond the annotation below.
+	default:
+		return fmt.Errorf("unknown remediation strategy %q", healthCheck.Spec.RemediationStrategy)
+	}
+
+	if agent.Annotations == nil {
+		agent.Annotations = map[string]string{}
+	}
+	agent.Annotations[v1beta1.RemediationAnnotation] = metav1.Now().Format(time.RFC3339)
+	if err := r.Update(ctx, agent); err != nil {
+		return errors.Wrapf(err, "annotating agent %s as under remediation", agent.Name)
+	}
+
+	r.Recorder.Eventf(agent, corev1.EventTypeNormal, "AgentRemediated", "remediation action %q taken against agent %s", healthCheck.Spec.RemediationStrategy, agent.Name)
+	return nil
+}
+
+// rebootHost reboots the host behind agent. This tree has no BareMetalHost
+// CRD to cycle online=false->true against (the metal3 types aren't vendored
+// here), so it goes through the backend's V2ResetHost directly; a real BMH
+// cycle, where available, is the preferred path and should be attempted
+// first once those types are available to this package.
+func (r *AgentHealthCheckReconciler) rebootHost(agent *v1beta1.Agent) error {
+	host, err := r.Installer.GetHostByKubeKey(client.ObjectKeyFromObject(agent))
+	if err != nil {
+		return errors.Wrapf(err, "getting host for agent %s", agent.Name)
+	}
+	_, err = r.Installer.V2ResetHost(context.Background(), installer.V2ResetHostParams{
+		HostID:     *host.ID,
+		InfraEnvID: host.InfraEnvID,
+	})
+	return err
+}
+
+// maxUnhealthyBudget resolves spec.MaxUnhealthy (absolute or percentage)
+// against the number of selected Agents, defaulting to all of them.
+func maxUnhealthyBudget(maxUnhealthy *intstr.IntOrString, total int) int32 {
+	if maxUnhealthy == nil {
+		return int32(total)
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(maxUnhealthy, total, true)
+	if err != nil {
+		return int32(total)
+	}
+	return int32(value)
+}
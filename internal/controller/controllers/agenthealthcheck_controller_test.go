@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/bminventory"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/internal/controller/scheme"
+	"github.com/openshift/assisted-service/test/fake"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("AgentHealthCheckReconciler", func() {
+	var (
+		c                     client.Client
+		hr                    *AgentHealthCheckReconciler
+		ctx                   = context.Background()
+		mockCtrl              *gomock.Controller
+		mockInstallerInternal *bminventory.MockInstallerInternals
+	)
+
+	BeforeEach(func() {
+		c = fake.NewFakeClientBuilder().Build()
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockInstallerInternal = bminventory.NewMockInstallerInternals(mockCtrl)
+
+		hr = &AgentHealthCheckReconciler{
+			Client:    c,
+			APIReader: c,
+			Scheme:    scheme.Scheme,
+			Log:       common.GetTestLog(),
+			Installer: mockInstallerInternal,
+			Recorder:  record.NewFakeRecorder(32),
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	newAgentHealthCheck := func(maxUnhealthy *intstr.IntOrString) *v1beta1.AgentHealthCheck {
+		return &v1beta1.AgentHealthCheck{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ahc", Namespace: testNamespace},
+			Spec: v1beta1.AgentHealthCheckSpec{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent-under-test"}},
+				UnhealthyConditions: []v1beta1.UnhealthyCondition{
+					{Type: "Connected", Status: corev1.ConditionFalse, Timeout: metav1.Duration{Duration: 5 * time.Minute}},
+				},
+				MaxUnhealthy:        maxUnhealthy,
+				RemediationStrategy: v1beta1.RemediationStrategyAnnotateOnly,
+			},
+		}
+	}
+
+	newCandidateAgent := func(name string, lastTransition time.Time) *v1beta1.Agent {
+		agent := newAgent(name, testNamespace, v1beta1.AgentSpec{})
+		agent.Labels = map[string]string{"app": "agent-under-test"}
+		agent.Status.Conditions = []conditionsv1.Condition{
+			{Type: "Connected", Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(lastTransition)},
+		}
+		return agent
+	}
+
+	It("remediates an agent whose unhealthy condition has been held past its timeout", func() {
+		healthCheck := newAgentHealthCheck(nil)
+		Expect(c.Create(ctx, healthCheck)).To(Succeed())
+
+		agent := newCandidateAgent("unhealthy-agent", time.Now().Add(-10*time.Minute))
+		Expect(c.Create(ctx, agent)).To(Succeed())
+
+		_, err := hr.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-ahc", Namespace: testNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		updated := &v1beta1.Agent{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "unhealthy-agent", Namespace: testNamespace}, updated)).To(Succeed())
+		Expect(updated.Annotations).To(HaveKey(v1beta1.RemediationAnnotation))
+
+		updatedHealthCheck := &v1beta1.AgentHealthCheck{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "test-ahc", Namespace: testNamespace}, updatedHealthCheck)).To(Succeed())
+		Expect(updatedHealthCheck.Status.ExpectedHosts).To(Equal(int32(1)))
+		Expect(updatedHealthCheck.Status.CurrentHealthy).To(Equal(int32(0)))
+		Expect(updatedHealthCheck.Status.Targets).To(HaveLen(1))
+	})
+
+	It("does not remediate an agent whose unhealthy condition hasn't reached its timeout yet", func() {
+		healthCheck := newAgentHealthCheck(nil)
+		Expect(c.Create(ctx, healthCheck)).To(Succeed())
+
+		agent := newCandidateAgent("recent-agent", time.Now().Add(-time.Minute))
+		Expect(c.Create(ctx, agent)).To(Succeed())
+
+		_, err := hr.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-ahc", Namespace: testNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		updated := &v1beta1.Agent{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "recent-agent", Namespace: testNamespace}, updated)).To(Succeed())
+		Expect(updated.Annotations).ToNot(HaveKey(v1beta1.RemediationAnnotation))
+	})
+
+	It("caps remediation at maxUnhealthy", func() {
+		maxUnhealthy := intstr.FromInt(1)
+		healthCheck := newAgentHealthCheck(&maxUnhealthy)
+		Expect(c.Create(ctx, healthCheck)).To(Succeed())
+
+		for _, name := range []string{"unhealthy-a", "unhealthy-b"} {
+			Expect(c.Create(ctx, newCandidateAgent(name, time.Now().Add(-10*time.Minute)))).To(Succeed())
+		}
+
+		_, err := hr.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-ahc", Namespace: testNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		remediatedCount := 0
+		for _, name := range []string{"unhealthy-a", "unhealthy-b"} {
+			updated := &v1beta1.Agent{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: testNamespace}, updated)).To(Succeed())
+			if _, ok := updated.Annotations[v1beta1.RemediationAnnotation]; ok {
+				remediatedCount++
+			}
+		}
+		Expect(remediatedCount).To(Equal(1))
+	})
+})
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bootstrapTokenIDChars is the character set kubeadm draws token IDs and
+// secrets from: lowercase alphanumeric, so the token is safe to embed in a
+// Secret name and in the kubeconfig's bearer-token-style "id.secret" form.
+const bootstrapTokenIDChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// bootstrapTokenIDLen and bootstrapTokenSecretLen match kubeadm's
+// BootstrapTokenString format (RFC: token-id.token-secret).
+const (
+	bootstrapTokenIDLen     = 6
+	bootstrapTokenSecretLen = 16
+)
+
+// bootstrapTokenNamespace is where kubeadm-style bootstrap token Secrets
+// live on the spoke cluster.
+const bootstrapTokenNamespace = "kube-system"
+
+// bootstrapTokenSecretPrefix is kubeadm's naming convention for bootstrap
+// token Secrets: "bootstrap-token-<token id>".
+const bootstrapTokenSecretPrefix = "bootstrap-token-"
+
+var bootstrapTokenIDPattern = regexp.MustCompile(`^[a-z0-9]{6}$`)
+
+// BootstrapTokenString is a kubeadm-style bootstrap token: a public ID used
+// to look up the token's Secret, and a secret half the joining kubelet
+// authenticates with. Its zero value is never valid; use
+// GenerateBootstrapToken to create one.
+type BootstrapTokenString struct {
+	ID     string
+	Secret string
+}
+
+// String renders the token in the "id.secret" form kubeadm's kubelet
+// bootstrap-kubeconfig expects as its bearer token.
+func (t BootstrapTokenString) String() string {
+	return fmt.Sprintf("%s.%s", t.ID, t.Secret)
+}
+
+// SecretName returns the name of this token's Secret in the spoke cluster's
+// kube-system namespace.
+func (t BootstrapTokenString) SecretName() string {
+	return bootstrapTokenSecretPrefix + t.ID
+}
+
+// GenerateBootstrapToken returns a new, random BootstrapTokenString.
+func GenerateBootstrapToken() (BootstrapTokenString, error) {
+	id, err := randomBootstrapTokenString(bootstrapTokenIDLen)
+	if err != nil {
+		return BootstrapTokenString{}, errors.Wrap(err, "generating bootstrap token id")
+	}
+	secret, err := randomBootstrapTokenString(bootstrapTokenSecretLen)
+	if err != nil {
+		return BootstrapTokenString{}, errors.Wrap(err, "generating bootstrap token secret")
+	}
+	return BootstrapTokenString{ID: id, Secret: secret}, nil
+}
+
+func randomBootstrapTokenString(n int) (string, error) {
+	out := make([]byte, n)
+	idx := make([]byte, n)
+	if _, err := rand.Read(idx); err != nil {
+		return "", err
+	}
+	for i, b := range idx {
+		out[i] = bootstrapTokenIDChars[int(b)%len(bootstrapTokenIDChars)]
+	}
+	return string(out), nil
+}
+
+// newBootstrapTokenSecret builds the kube-system Secret kubeadm's kubelet
+// and controller-manager bootstrap-token authenticator expect, authorized
+// only for node-client CSR creation (signing) and authentication, expiring
+// after ttl so an unused token can't be replayed indefinitely.
+func newBootstrapTokenSecret(token BootstrapTokenString, ttl time.Duration) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      token.SecretName(),
+			Namespace: bootstrapTokenNamespace,
+		},
+		Type: corev1.SecretType("bootstrap.kubernetes.io/token"),
+		StringData: map[string]string{
+			"token-id":                       token.ID,
+			"token-secret":                   token.Secret,
+			"expiration":                     time.Now().Add(ttl).UTC().Format(time.RFC3339),
+			"usage-bootstrap-authentication": "true",
+			"usage-bootstrap-signing":        "true",
+			"description":                    "Used by assisted-service to join a day-2 Agent host without a pre-shared bootstrapper identity",
+		},
+	}
+}
+
+// IssueBootstrapToken creates a new bootstrap token Secret on the spoke
+// cluster pointed at by cd and returns the token to hand to the joining
+// host's discovery ignition. spokeClient is expected to come from
+// SpokeClusterCache.GetClient so the write reuses the tracked connection
+// rather than dialing the spoke API server directly.
+func IssueBootstrapToken(ctx context.Context, spokeClient client.Client, ttl time.Duration) (BootstrapTokenString, error) {
+	token, err := GenerateBootstrapToken()
+	if err != nil {
+		return BootstrapTokenString{}, err
+	}
+	if err := spokeClient.Create(ctx, newBootstrapTokenSecret(token, ttl)); err != nil {
+		return BootstrapTokenString{}, errors.Wrapf(err, "creating bootstrap token secret %s/%s", bootstrapTokenNamespace, token.SecretName())
+	}
+	return token, nil
+}
+
+// RotateBootstrapToken replaces the bootstrap token Secret identified by
+// previous with a freshly generated one, so a token handed to a host whose
+// install is taking a while doesn't sit around at its original TTL forever.
+// previous may be the zero value, in which case this is equivalent to
+// IssueBootstrapToken.
+func RotateBootstrapToken(ctx context.Context, spokeClient client.Client, previous BootstrapTokenString, ttl time.Duration) (BootstrapTokenString, error) {
+	if previous.ID != "" {
+		if err := deleteBootstrapTokenSecret(ctx, spokeClient, previous); err != nil {
+			return BootstrapTokenString{}, err
+		}
+	}
+	return IssueBootstrapToken(ctx, spokeClient, ttl)
+}
+
+// GarbageCollectBootstrapToken deletes the bootstrap token Secret identified
+// by token once the Node it was issued for has joined and gone Ready, so a
+// successfully-used token doesn't linger as a standing credential.
+func GarbageCollectBootstrapToken(ctx context.Context, spokeClient client.Client, token BootstrapTokenString, node *corev1.Node) error {
+	if node == nil || !nodeIsReady(node) {
+		return nil
+	}
+	return deleteBootstrapTokenSecret(ctx, spokeClient, token)
+}
+
+func deleteBootstrapTokenSecret(ctx context.Context, spokeClient client.Client, token BootstrapTokenString) error {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: bootstrapTokenNamespace, Name: token.SecretName()}
+	if err := spokeClient.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "getting bootstrap token secret %s", key)
+	}
+	if err := spokeClient.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting bootstrap token secret %s", key)
+	}
+	return nil
+}
+
+func nodeIsReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsValidBootstrapTokenID reports whether id matches kubeadm's 6-character
+// lowercase-alphanumeric token ID format.
+func IsValidBootstrapTokenID(id string) bool {
+	return bootstrapTokenIDPattern.MatchString(id)
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("bootstrap token join", func() {
+	var (
+		ctx context.Context
+		c   client.Client
+	)
+
+	readyNode := func() *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+
+	notReadyNode := func() *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = fakeclient.NewClientBuilder().Build()
+	})
+
+	It("generates a token matching kubeadm's id/secret format", func() {
+		token, err := GenerateBootstrapToken()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.ID).To(HaveLen(bootstrapTokenIDLen))
+		Expect(token.Secret).To(HaveLen(bootstrapTokenSecretLen))
+		Expect(IsValidBootstrapTokenID(token.ID)).To(BeTrue())
+		Expect(token.String()).To(Equal(token.ID + "." + token.Secret))
+	})
+
+	It("issues a bootstrap token secret in kube-system", func() {
+		token, err := IssueBootstrapToken(ctx, c, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: bootstrapTokenNamespace, Name: token.SecretName()}, secret)).To(Succeed())
+		Expect(secret.StringData["token-id"]).To(Equal(token.ID))
+		Expect(secret.StringData["token-secret"]).To(Equal(token.Secret))
+	})
+
+	It("rotates a token by deleting the previous secret and issuing a new one", func() {
+		previous, err := IssueBootstrapToken(ctx, c, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		rotated, err := RotateBootstrapToken(ctx, c, previous, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated.ID).ToNot(Equal(previous.ID))
+
+		err = c.Get(ctx, types.NamespacedName{Namespace: bootstrapTokenNamespace, Name: previous.SecretName()}, &corev1.Secret{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("garbage-collects the token once its Node is Ready", func() {
+		token, err := IssueBootstrapToken(ctx, c, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(GarbageCollectBootstrapToken(ctx, c, token, notReadyNode())).To(Succeed())
+		Expect(c.Get(ctx, types.NamespacedName{Namespace: bootstrapTokenNamespace, Name: token.SecretName()}, &corev1.Secret{})).To(Succeed())
+
+		Expect(GarbageCollectBootstrapToken(ctx, c, token, readyNode())).To(Succeed())
+		err = c.Get(ctx, types.NamespacedName{Namespace: bootstrapTokenNamespace, Name: token.SecretName()}, &corev1.Secret{})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// watchIgnitionEndpointTokenSecrets adds a watch for corev1.Secret to bldr so
+// that a Secret referenced by an Agent's Spec.IgnitionEndpointTokenReference
+// re-enqueues every Agent referencing it as soon as it changes, instead of
+// the backend keeping a stale token until something unrelated perturbs the
+// Agent. AgentReconciler.SetupWithManager should chain this onto the builder
+// it gets from ctrl.NewControllerManagedBy(mgr).For(&v1beta1.Agent{}), e.g.:
+//
+//	return watchIgnitionEndpointTokenSecrets(bldr, mgr.GetClient()).Complete(r)
+func watchIgnitionEndpointTokenSecrets(bldr *builder.Builder, c client.Client) *builder.Builder {
+	return bldr.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			return agentsReferencingIgnitionTokenSecret(context.Background(), c, obj)
+		}),
+	)
+}
+
+// agentsReferencingIgnitionTokenSecret lists the Agents in secret's namespace
+// whose Spec.IgnitionEndpointTokenReference points at it.
+func agentsReferencingIgnitionTokenSecret(ctx context.Context, c client.Client, secret client.Object) []reconcile.Request {
+	agents := &v1beta1.AgentList{}
+	if err := c.List(ctx, agents, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range agents.Items {
+		ref := agents.Items[i].Spec.IgnitionEndpointTokenReference
+		if ref == nil || ref.Name != secret.GetName() || ref.Namespace != secret.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&agents.Items[i])})
+	}
+	return requests
+}
+
+// ignitionEndpointTokenVersion is the observed-version marker
+// AgentReconciler persists at Agent.Status.IgnitionEndpointTokenVersion after
+// reading the referenced token Secret, so a later reconcile can tell a
+// rotation apart from an unrelated reconcile by comparing this against the
+// Secret's current ResourceVersion.
+func ignitionEndpointTokenVersion(secret *corev1.Secret) string {
+	return secret.ResourceVersion
+}
+
+// ignitionEndpointTokenDrifted reports whether the token Secret observed in
+// this reconcile is newer than lastObservedVersion (the value last recorded
+// on the Agent), meaning the backend's copy of the token is stale and
+// V2UpdateHostInternal must be called again even though nothing else about
+// the Agent changed.
+func ignitionEndpointTokenDrifted(secret *corev1.Secret, lastObservedVersion string) bool {
+	return ignitionEndpointTokenVersion(secret) != lastObservedVersion
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/test/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func namespacedNamesOf(requests []reconcile.Request) []types.NamespacedName {
+	names := make([]types.NamespacedName, len(requests))
+	for i, req := range requests {
+		names[i] = req.NamespacedName
+	}
+	return names
+}
+
+var _ = Describe("agentsReferencingIgnitionTokenSecret", func() {
+	var c client.Client
+
+	secretRef := func(secret *corev1.Secret) *v1beta1.IgnitionEndpointTokenReference {
+		return &v1beta1.IgnitionEndpointTokenReference{Name: secret.Name, Namespace: secret.Namespace}
+	}
+
+	BeforeEach(func() {
+		c = fake.NewFakeClientBuilder().Build()
+	})
+
+	It("enqueues every agent referencing the rotated secret, and no others", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token-secret", Namespace: testNamespace}}
+		Expect(c.Create(context.Background(), secret)).To(Succeed())
+
+		referencing1 := newAgent("referencing-1", testNamespace, v1beta1.AgentSpec{IgnitionEndpointTokenReference: secretRef(secret)})
+		referencing2 := newAgent("referencing-2", testNamespace, v1beta1.AgentSpec{IgnitionEndpointTokenReference: secretRef(secret)})
+		unrelated := newAgent("unrelated", testNamespace, v1beta1.AgentSpec{})
+		for _, agent := range []*v1beta1.Agent{referencing1, referencing2, unrelated} {
+			Expect(c.Create(context.Background(), agent)).To(Succeed())
+		}
+
+		requests := agentsReferencingIgnitionTokenSecret(context.Background(), c, secret)
+		Expect(namespacedNamesOf(requests)).To(ConsistOf(
+			types.NamespacedName{Name: "referencing-1", Namespace: testNamespace},
+			types.NamespacedName{Name: "referencing-2", Namespace: testNamespace},
+		))
+	})
+
+	It("fans out to multiple agents sharing the same secret", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared-secret", Namespace: testNamespace}}
+		Expect(c.Create(context.Background(), secret)).To(Succeed())
+
+		for i := 0; i < 3; i++ {
+			agent := newAgent(
+				[]string{"a", "b", "c"}[i], testNamespace,
+				v1beta1.AgentSpec{IgnitionEndpointTokenReference: secretRef(secret)},
+			)
+			Expect(c.Create(context.Background(), agent)).To(Succeed())
+		}
+
+		requests := agentsReferencingIgnitionTokenSecret(context.Background(), c, secret)
+		Expect(requests).To(HaveLen(3))
+	})
+
+	It("still matches agents after the secret is deleted, since matching only needs name/namespace", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "deleted-secret", Namespace: testNamespace}}
+		agent := newAgent("agent", testNamespace, v1beta1.AgentSpec{IgnitionEndpointTokenReference: secretRef(secret)})
+		Expect(c.Create(context.Background(), agent)).To(Succeed())
+
+		requests := agentsReferencingIgnitionTokenSecret(context.Background(), c, secret)
+		Expect(namespacedNamesOf(requests)).To(ConsistOf(types.NamespacedName{Name: "agent", Namespace: testNamespace}))
+	})
+})
+
+var _ = Describe("ignitionEndpointTokenDrifted", func() {
+	It("detects a resource-version change", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "2"}}
+		Expect(ignitionEndpointTokenDrifted(secret, "1")).To(BeTrue())
+	})
+
+	It("reports no drift when the resource version matches what was last observed", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"}}
+		Expect(ignitionEndpointTokenDrifted(secret, "1")).To(BeFalse())
+	})
+})
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InventoryLabelPrefix namespaces the inventory-derived labels AgentReconciler
+// copies onto each Agent (cpu-architecture, host-manufacturer, host-isvirtual,
+// ...). ManagedClusterHandoffReconciler reuses the same keys so a
+// ManagedCluster's labels describe the same facts the Agent already does.
+const InventoryLabelPrefix = "inventory.agent-install.openshift.io/"
+
+// managedClusterInventoryLabelKeys is the subset of InventoryLabelPrefix
+// labels copied from an installed cluster's Agents onto its ManagedCluster;
+// these are the ones OCM placement and addon selection typically key off.
+var managedClusterInventoryLabelKeys = []string{
+	"cpu-architecture",
+	"host-manufacturer",
+	"host-isvirtual",
+}
+
+// managedClusterRequeueAfter is how soon ManagedClusterHandoffReconciler
+// retries an Installed ClusterDeployment whose spoke wasn't reachable yet,
+// rather than waiting indefinitely for an unrelated watch event.
+const managedClusterRequeueAfter = time.Minute
+
+var (
+	managedClusterGVK        = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1", Kind: "ManagedCluster"}
+	klusterletGVK            = schema.GroupVersionKind{Group: "operator.open-cluster-management.io", Version: "v1", Kind: "Klusterlet"}
+	klusterletAddonConfigGVK = schema.GroupVersionKind{Group: "agent.open-cluster-management.io", Version: "v1", Kind: "KlusterletAddonConfig"}
+)
+
+// ManagedClusterHandoffConfig is the feature flag gating
+// ManagedClusterHandoffReconciler; operators not running Open Cluster
+// Management leave EnableManagedClusterHandoff unset and installed clusters
+// are left alone.
+type ManagedClusterHandoffConfig struct {
+	EnableManagedClusterHandoff bool `envconfig:"ENABLE_OCM_MANAGED_CLUSTER_HANDOFF" default:"false"`
+}
+
+// ManagedClusterHandoffReconciler watches ClusterDeployments and, once one
+// reaches Installed with its admin kubeconfig available, installs the OCM
+// klusterlet on the spoke and registers the cluster with the hub as a
+// ManagedCluster, so bringing an Agent-installed cluster under multi-cluster
+// management doesn't need a separate, manually-run registration step. It
+// sits alongside AgentReconciler and reuses its SpokeClusterCache to reach
+// the spoke rather than dialing the admin kubeconfig Secret itself.
+//
+// OCM's ManagedCluster, KlusterletAddonConfig and Klusterlet CRDs aren't
+// vendored in this tree, so their manifests are built and applied as
+// unstructured.Unstructured rather than through typed clients.
+type ManagedClusterHandoffReconciler struct {
+	client.Client
+	APIReader         client.Reader
+	Scheme            *runtime.Scheme
+	Log               logrus.FieldLogger
+	SpokeClusterCache SpokeClusterCache
+	Config            ManagedClusterHandoffConfig
+}
+
+func (r *ManagedClusterHandoffReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !r.Config.EnableManagedClusterHandoff {
+		return ctrl.Result{}, nil
+	}
+
+	log := r.Log.WithField("cluster_deployment", req.NamespacedName)
+
+	cd := &hivev1.ClusterDeployment{}
+	if err := r.Get(ctx, req.NamespacedName, cd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !cd.Status.Installed {
+		return ctrl.Result{}, nil
+	}
+
+	aci, err := r.agentClusterInstallFor(ctx, cd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	spokeClient, err := r.SpokeClusterCache.GetClient(ctx, client.ObjectKeyFromObject(cd))
+	if err != nil {
+		log.WithError(err).Warn("spoke cluster unreachable, requeuing managed cluster handoff")
+		return ctrl.Result{RequeueAfter: managedClusterRequeueAfter}, nil
+	}
+
+	if err := installKlusterlet(ctx, spokeClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "installing klusterlet on spoke cluster")
+	}
+
+	labels, err := r.inventoryLabelsFor(ctx, cd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := createOrUpdateUnstructured(ctx, r.Client, newManagedCluster(cd.Name, labels)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "creating managed cluster")
+	}
+
+	if err := createOrUpdateUnstructured(ctx, r.Client, newKlusterletAddonConfig(cd.Name, cd.Namespace, aci.Spec.ManagedClusterSet)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "creating klusterlet addon config")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// agentClusterInstallFor resolves the AgentClusterInstall driving cd's
+// installation, which carries the ManagedClusterSet operators configure the
+// resulting KlusterletAddonConfig with.
+func (r *ManagedClusterHandoffReconciler) agentClusterInstallFor(ctx context.Context, cd *hivev1.ClusterDeployment) (*hiveext.AgentClusterInstall, error) {
+	if cd.Spec.ClusterInstallRef == nil {
+		return nil, errors.Errorf("cluster deployment %s/%s has no ClusterInstallRef", cd.Namespace, cd.Name)
+	}
+	aci := &hiveext.AgentClusterInstall{}
+	key := client.ObjectKey{Namespace: cd.Namespace, Name: cd.Spec.ClusterInstallRef.Name}
+	if err := r.Get(ctx, key, aci); err != nil {
+		return nil, errors.Wrapf(err, "getting agent cluster install %s", key)
+	}
+	return aci, nil
+}
+
+// inventoryLabelsFor copies the managedClusterInventoryLabelKeys labels off
+// the first Agent bound to cd, so the ManagedCluster describes the same
+// inventory facts (cpu-arch, host-manufacturer, virtualization) the Agent
+// already carries instead of re-deriving them.
+func (r *ManagedClusterHandoffReconciler) inventoryLabelsFor(ctx context.Context, cd *hivev1.ClusterDeployment) (map[string]string, error) {
+	agents := &v1beta1.AgentList{}
+	if err := r.List(ctx, agents, client.InNamespace(cd.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "listing agents")
+	}
+
+	labels := map[string]string{}
+	for i := range agents.Items {
+		agent := &agents.Items[i]
+		if agent.Spec.ClusterDeploymentName == nil || agent.Spec.ClusterDeploymentName.Name != cd.Name {
+			continue
+		}
+		for _, key := range managedClusterInventoryLabelKeys {
+			if v, ok := agent.Labels[InventoryLabelPrefix+key]; ok {
+				labels[InventoryLabelPrefix+key] = v
+			}
+		}
+		break
+	}
+	return labels, nil
+}
+
+// installKlusterlet applies the minimal Klusterlet CR that has the OCM
+// operator (assumed already running on the spoke, since this reconciler only
+// hands off registration rather than bootstrapping the operator itself)
+// register the spoke with the hub.
+func installKlusterlet(ctx context.Context, spokeClient client.Client) error {
+	klusterlet := &unstructured.Unstructured{}
+	klusterlet.SetGroupVersionKind(klusterletGVK)
+	klusterlet.SetName("klusterlet")
+	if err := unstructured.SetNestedField(klusterlet.Object, "open-cluster-management-agent", "spec", "namespace"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(klusterlet.Object, "klusterlet", "spec", "clusterName"); err != nil {
+		return err
+	}
+	return createOrUpdateUnstructured(ctx, spokeClient, klusterlet)
+}
+
+// newManagedCluster builds the hub-side ManagedCluster registering name as a
+// managed spoke, labeled with the inventory facts copied from its Agents.
+func newManagedCluster(name string, labels map[string]string) *unstructured.Unstructured {
+	mc := &unstructured.Unstructured{}
+	mc.SetGroupVersionKind(managedClusterGVK)
+	mc.SetName(name)
+	mc.SetLabels(labels)
+	_ = unstructured.SetNestedField(mc.Object, true, "spec", "hubAcceptsClient")
+	return mc
+}
+
+// newKlusterletAddonConfig builds the KlusterletAddonConfig OCM's
+// console/observability/policy addons key their installation off of.
+// clusterSet, taken from AgentClusterInstall.Spec.ManagedClusterSet, groups
+// the resulting ManagedCluster for OCM's placement API; it may be empty if
+// the operator hasn't opted into a set.
+func newKlusterletAddonConfig(clusterName, clusterNamespace, clusterSet string) *unstructured.Unstructured {
+	kac := &unstructured.Unstructured{}
+	kac.SetGroupVersionKind(klusterletAddonConfigGVK)
+	kac.SetName(clusterName)
+	kac.SetNamespace(clusterName)
+	if clusterSet != "" {
+		kac.SetLabels(map[string]string{"cluster.open-cluster-management.io/clusterset": clusterSet})
+	}
+	_ = unstructured.SetNestedField(kac.Object, clusterName, "spec", "clusterName")
+	_ = unstructured.SetNestedField(kac.Object, clusterNamespace, "spec", "clusterNamespace")
+	_ = unstructured.SetNestedField(kac.Object, clusterName, "spec", "clusterLabels", "name")
+	return kac
+}
+
+// createOrUpdateUnstructured creates obj, or updates it in place (carrying
+// over the existing ResourceVersion) if it already exists.
+func createOrUpdateUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		return c.Create(ctx, obj)
+	case err != nil:
+		return errors.Wrapf(err, "getting existing %s %s", obj.GroupVersionKind(), client.ObjectKeyFromObject(obj))
+	default:
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		return c.Update(ctx, obj)
+	}
+}
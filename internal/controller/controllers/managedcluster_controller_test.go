@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/test/fake"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// fakeSpokeClusterCache is a minimal SpokeClusterCache stand-in: it returns a
+// fixed client, or a fixed error when the test wants to simulate an
+// unreachable spoke, without dialing anything.
+type fakeSpokeClusterCache struct {
+	client client.Client
+	err    error
+}
+
+func (f *fakeSpokeClusterCache) GetClient(ctx context.Context, cd client.ObjectKey) (client.Client, error) {
+	return f.client, f.err
+}
+
+func (f *fakeSpokeClusterCache) Watch(ctx context.Context, cd client.ObjectKey, obj client.Object, h handler.EventHandler) error {
+	return nil
+}
+
+var _ = Describe("ManagedClusterHandoffReconciler", func() {
+	var (
+		ctx        = context.Background()
+		hubClient  client.Client
+		spoke      client.Client
+		reconciler *ManagedClusterHandoffReconciler
+		cd         *hivev1.ClusterDeployment
+		aci        *hiveext.AgentClusterInstall
+		agent      *v1beta1.Agent
+	)
+
+	BeforeEach(func() {
+		cd = &hivev1.ClusterDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: testNamespace},
+			Spec: hivev1.ClusterDeploymentSpec{
+				ClusterInstallRef: &hivev1.ClusterInstallLocalReference{Name: "test-cluster-aci"},
+			},
+			Status: hivev1.ClusterDeploymentStatus{Installed: true},
+		}
+		aci = &hiveext.AgentClusterInstall{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-aci", Namespace: testNamespace},
+			Spec:       hiveext.AgentClusterInstallSpec{ManagedClusterSet: "default"},
+		}
+		agent = &v1beta1.Agent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "agent-1",
+				Namespace: testNamespace,
+				Labels: map[string]string{
+					InventoryLabelPrefix + "cpu-architecture":  "x86_64",
+					InventoryLabelPrefix + "host-manufacturer": "RedHat",
+				},
+			},
+			Spec: v1beta1.AgentSpec{
+				ClusterDeploymentName: &v1beta1.ClusterReference{Name: "test-cluster", Namespace: testNamespace},
+			},
+		}
+		hubClient = fake.NewFakeClientBuilder(cd, aci, agent).Build()
+		spoke = fake.NewFakeClientBuilder().Build()
+		reconciler = &ManagedClusterHandoffReconciler{
+			Client:            hubClient,
+			Log:               common.GetTestLog(),
+			SpokeClusterCache: &fakeSpokeClusterCache{client: spoke},
+			Config:            ManagedClusterHandoffConfig{EnableManagedClusterHandoff: true},
+		}
+	})
+
+	req := func() ctrl.Request {
+		return ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-cluster", Namespace: testNamespace}}
+	}
+
+	It("registers the installed cluster as a ManagedCluster with inventory labels", func() {
+		_, err := reconciler.Reconcile(ctx, req())
+		Expect(err).ToNot(HaveOccurred())
+
+		mc := newManagedCluster("test-cluster", nil)
+		Expect(hubClient.Get(ctx, client.ObjectKeyFromObject(mc), mc)).To(Succeed())
+		Expect(mc.GetLabels()[InventoryLabelPrefix+"cpu-architecture"]).To(Equal("x86_64"))
+
+		kac := newKlusterletAddonConfig("test-cluster", testNamespace, "")
+		Expect(hubClient.Get(ctx, client.ObjectKeyFromObject(kac), kac)).To(Succeed())
+
+		klusterlet := &unstructured.Unstructured{}
+		klusterlet.SetGroupVersionKind(klusterletGVK)
+		Expect(spoke.Get(ctx, types.NamespacedName{Name: "klusterlet"}, klusterlet)).To(Succeed())
+	})
+
+	It("requeues instead of failing when the spoke is unreachable", func() {
+		reconciler.SpokeClusterCache = &fakeSpokeClusterCache{err: errors.New("dial tcp: connection refused")}
+
+		result, err := reconciler.Reconcile(ctx, req())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(managedClusterRequeueAfter))
+	})
+
+	It("is a no-op when the feature flag is off", func() {
+		reconciler.Config.EnableManagedClusterHandoff = false
+
+		_, err := reconciler.Reconcile(ctx, req())
+		Expect(err).ToNot(HaveOccurred())
+
+		mc := newManagedCluster("test-cluster", nil)
+		err = hubClient.Get(ctx, client.ObjectKeyFromObject(mc), mc)
+		Expect(err).To(HaveOccurred())
+	})
+})
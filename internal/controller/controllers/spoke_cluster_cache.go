@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//go:generate mockgen -source=spoke_cluster_cache.go -package=controllers -destination=mock_spoke_cluster_cache.go
+
+// adminKubeConfigStringTemplate is Hive's naming convention for the Secret
+// holding the admin kubeconfig of an installed ClusterDeployment.
+const adminKubeConfigStringTemplate = "%s-admin-kubeconfig"
+
+// spokeHealthCheckInterval is how often a cached spoke connection is
+// health-checked via a cheap discovery call; a failure evicts the entry so
+// the next GetClient/Watch call rebuilds it from a (possibly rotated)
+// kubeconfig instead of continuing to serve a dead client.
+const spokeHealthCheckInterval = 30 * time.Second
+
+var (
+	spokeClusterCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "assisted_installer_spoke_cluster_cache_requests_total",
+		Help: "Count of ClusterCacheTracker connection lookups, by whether an existing cached connection was reused (hit) or a new one had to be built (miss).",
+	}, []string{"result"})
+	spokeClusterCacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "assisted_installer_spoke_cluster_cache_evictions_total",
+		Help: "Count of cached spoke cluster connections torn down after a failed health check.",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(spokeClusterCacheRequests, spokeClusterCacheEvictions)
+}
+
+// SpokeClientBuilder builds the cache/client pair a spoke connection is
+// served from, given the rest.Config decoded from that cluster's admin
+// kubeconfig. It exists as a seam so tests can substitute a fake client
+// instead of dialing a real API server; ClusterCacheTracker is the only
+// production caller, and treats it as an implementation detail rather than
+// something reconcilers interact with directly.
+type SpokeClientBuilder interface {
+	NewClient(ctx context.Context, restConfig *rest.Config) (client.Client, cache.Cache, error)
+}
+
+// defaultSpokeClientBuilder builds a real controller-runtime cache.Cache
+// and a client.Client reading through it.
+type defaultSpokeClientBuilder struct{}
+
+func (defaultSpokeClientBuilder) NewClient(ctx context.Context, restConfig *rest.Config) (client.Client, cache.Cache, error) {
+	spokeCache, err := cache.New(restConfig, cache.Options{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "building cache")
+	}
+	spokeClient, err := client.New(restConfig, client.Options{Cache: &client.CacheOptions{Reader: spokeCache}})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "building client")
+	}
+	return spokeClient, spokeCache, nil
+}
+
+// SpokeClusterCache lazily builds and caches a controller-runtime cache/client
+// per spoke cluster, keyed by the client.ObjectKey of the owning
+// ClusterDeployment. It is modeled on Cluster API's remote.ClusterCacheTracker
+// and is shared by every reconciler that needs to talk to a spoke cluster
+// (Agent CSR approval, node-label sync, InstallConfigOverrides reconciliation)
+// instead of each dialing the admin kubeconfig Secret and building its own
+// client on every reconcile, and registers watches on spoke-side objects
+// (Node, and optionally CertificateSigningRequest) so that changes there
+// re-enqueue the owning Agent through a mapping function rather than
+// requiring a poll.
+//
+// Implementations must be safe for concurrent use.
+type SpokeClusterCache interface {
+	// GetClient returns a cached client against the spoke cluster pointed at
+	// by cd's admin kubeconfig Secret, building and starting the underlying
+	// cache the first time it is requested for that ClusterDeployment. Reads
+	// are served from the cache; writes go straight to the spoke API server,
+	// so callers that need to approve a CSR or label a Node can use the same
+	// client they use to read it.
+	GetClient(ctx context.Context, cd client.ObjectKey) (client.Client, error)
+
+	// Watch registers handler to be called for events on obj's GVK in the
+	// spoke cluster pointed at by cd, building the cache first if needed.
+	// Calling Watch more than once for the same (cd, obj GVK) pair is a
+	// no-op: the handler from the first call is kept.
+	Watch(ctx context.Context, cd client.ObjectKey, obj client.Object, handler handler.EventHandler) error
+}
+
+// spokeConnection is the cached cache/client pair for a single spoke cluster,
+// plus the bookkeeping needed to health-check and evict it.
+type spokeConnection struct {
+	cache       cache.Cache
+	client      client.Client
+	cancel      context.CancelFunc
+	watchedGVKs map[string]bool
+}
+
+// ClusterCacheTracker is the default SpokeClusterCache implementation. Given
+// a ClusterDeployment's admin kubeconfig Secret, it builds a
+// sigs.k8s.io/controller-runtime cache.Cache scoped to that spoke, starts it
+// on its own goroutine, and tears it down and forgets it if the connection's
+// periodic health check fails, so the next caller rebuilds a fresh one.
+type ClusterCacheTracker struct {
+	client        client.Client
+	log           logr.Logger
+	clientFactory SpokeClientBuilder
+
+	mu          sync.Mutex
+	connections map[client.ObjectKey]*spokeConnection
+}
+
+// NewClusterCacheTracker returns a ClusterCacheTracker that reads admin
+// kubeconfig Secrets through hubClient and builds spoke connections through
+// the default SpokeClientBuilder.
+func NewClusterCacheTracker(hubClient client.Client, log logr.Logger) *ClusterCacheTracker {
+	return &ClusterCacheTracker{
+		client:        hubClient,
+		log:           log,
+		clientFactory: defaultSpokeClientBuilder{},
+		connections:   make(map[client.ObjectKey]*spokeConnection),
+	}
+}
+
+// NewClusterCacheTrackerWithFactory is NewClusterCacheTracker with an
+// injected SpokeClientBuilder, for tests that need to avoid dialing a
+// real spoke API server.
+func NewClusterCacheTrackerWithFactory(hubClient client.Client, log logr.Logger, clientFactory SpokeClientBuilder) *ClusterCacheTracker {
+	t := NewClusterCacheTracker(hubClient, log)
+	t.clientFactory = clientFactory
+	return t
+}
+
+func (t *ClusterCacheTracker) GetClient(ctx context.Context, cd client.ObjectKey) (client.Client, error) {
+	conn, err := t.getOrCreateConnection(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return conn.client, nil
+}
+
+func (t *ClusterCacheTracker) Watch(ctx context.Context, cd client.ObjectKey, obj client.Object, h handler.EventHandler) error {
+	conn, err := t.getOrCreateConnection(ctx, cd)
+	if err != nil {
+		return err
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return errors.Wrap(err, "determining GVK to watch")
+	}
+	gvkKey := gvk.String()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conn.watchedGVKs[gvkKey] {
+		return nil
+	}
+
+	informer, err := conn.cache.GetInformer(ctx, obj)
+	if err != nil {
+		return errors.Wrapf(err, "getting informer for %s in spoke cluster %s", gvkKey, cd)
+	}
+	if err := (&source.Informer{Informer: informer}).Start(ctx, h, nil); err != nil {
+		return errors.Wrapf(err, "starting watch for %s in spoke cluster %s", gvkKey, cd)
+	}
+	conn.watchedGVKs[gvkKey] = true
+	return nil
+}
+
+// getOrCreateConnection returns the cached connection for cd, building and
+// starting a new one (and its health-check goroutine) if none exists yet.
+func (t *ClusterCacheTracker) getOrCreateConnection(ctx context.Context, cd client.ObjectKey) (*spokeConnection, error) {
+	t.mu.Lock()
+	if conn, ok := t.connections[cd]; ok {
+		t.mu.Unlock()
+		spokeClusterCacheRequests.WithLabelValues("hit").Inc()
+		return conn, nil
+	}
+	t.mu.Unlock()
+	spokeClusterCacheRequests.WithLabelValues("miss").Inc()
+
+	restConfig, err := t.restConfigFor(ctx, cd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "building rest.Config for spoke cluster %s", cd)
+	}
+
+	spokeClient, spokeCache, err := t.clientFactory.NewClient(ctx, restConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "building spoke client for spoke cluster %s", cd)
+	}
+
+	healthClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "building health-check client for spoke cluster %s", cd)
+	}
+
+	cacheCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := spokeCache.Start(cacheCtx); err != nil {
+			t.log.Error(err, "spoke cache stopped", "clusterDeployment", cd)
+		}
+	}()
+	spokeCache.WaitForCacheSync(cacheCtx)
+
+	conn := &spokeConnection{
+		cache:       spokeCache,
+		client:      spokeClient,
+		cancel:      cancel,
+		watchedGVKs: make(map[string]bool),
+	}
+
+	t.mu.Lock()
+	if existing, ok := t.connections[cd]; ok {
+		// Another goroutine already won the race to build this
+		// connection while we were dialing - discard ours instead of
+		// overwriting theirs, which would otherwise leak our cache's
+		// informer goroutine and let our own healthCheck evict
+		// whichever connection occupies the map by the time it fires.
+		t.mu.Unlock()
+		cancel()
+		return existing, nil
+	}
+	t.connections[cd] = conn
+	t.mu.Unlock()
+
+	go t.healthCheck(cacheCtx, cd, healthClient, cancel)
+
+	return conn, nil
+}
+
+// healthCheck periodically probes the spoke API server's /healthz endpoint
+// and evicts the connection on failure, so a later GetClient/Watch call
+// rebuilds it (picking up a rotated kubeconfig, or simply retrying once the
+// spoke is reachable again) instead of handing out a client that will never
+// recover.
+func (t *ClusterCacheTracker) healthCheck(ctx context.Context, cd client.ObjectKey, healthClient kubernetes.Interface, cancel context.CancelFunc) {
+	ticker := time.NewTicker(spokeHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			healthCtx, healthCancel := context.WithTimeout(ctx, spokeHealthCheckInterval/2)
+			_, err := healthClient.Discovery().RESTClient().Get().AbsPath("/healthz").DoRaw(healthCtx)
+			healthCancel()
+			if err != nil {
+				t.log.Error(err, "spoke cluster health check failed, evicting cached connection", "clusterDeployment", cd)
+				t.evict(cd)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (t *ClusterCacheTracker) evict(cd client.ObjectKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.connections, cd)
+	spokeClusterCacheEvictions.Inc()
+}
+
+// restConfigFor reads the ClusterDeployment's admin kubeconfig Secret
+// (following Hive's "<name>-admin-kubeconfig" naming convention) off the hub
+// and turns it into a rest.Config for the spoke.
+func (t *ClusterCacheTracker) restConfigFor(ctx context.Context, cd client.ObjectKey) (*rest.Config, error) {
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: cd.Namespace, Name: fmt.Sprintf(adminKubeConfigStringTemplate, cd.Name)}
+	if err := t.client.Get(ctx, secretKey, secret); err != nil {
+		return nil, errors.Wrapf(err, "getting admin kubeconfig secret %s", secretKey)
+	}
+	kubeconfig, ok := secret.Data["kubeconfig"]
+	if !ok {
+		return nil, fmt.Errorf("admin kubeconfig secret %s has no %q data key", secretKey, "kubeconfig")
+	}
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
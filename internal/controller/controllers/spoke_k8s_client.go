@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+//go:generate mockgen -source=spoke_k8s_client.go -package=controllers -destination=mock_spoke_k8s_client.go
+
+// SpokeK8sClientFactory builds a SpokeK8sClient from a spoke cluster's admin
+// kubeconfig Secret. AgentReconciler calls it once per reconcile rather than
+// holding a long-lived connection, since day-2 join handling (CSR approval,
+// startup-taint removal) only needs a handful of point reads/writes against
+// the spoke API server.
+type SpokeK8sClientFactory interface {
+	Create(kubeconfigSecret *corev1.Secret) (SpokeK8sClient, error)
+}
+
+// SpokeK8sClient is the narrow set of spoke-cluster operations AgentReconciler
+// needs to finish a day-2 join: read the joined Node back, and drive its CSRs
+// and startup taints.
+type SpokeK8sClient interface {
+	// GetNode returns the spoke cluster's Node named name, or a NotFound
+	// error if it hasn't joined yet.
+	GetNode(name string) (*corev1.Node, error)
+
+	// ListCsrs returns every CertificateSigningRequest on the spoke cluster.
+	ListCsrs() (*certificatesv1.CertificateSigningRequestList, error)
+
+	// ApproveCsr approves csr.
+	ApproveCsr(csr *certificatesv1.CertificateSigningRequest) error
+
+	// DenyCsr denies csr, recording reason on the Denied condition it adds.
+	DenyCsr(csr *certificatesv1.CertificateSigningRequest, reason string) error
+
+	// AddNodeTaint JSON-patches taint onto the Node named nodeName if it
+	// isn't already present, matching taint's key+effect.
+	AddNodeTaint(nodeName string, taint corev1.Taint) error
+
+	// RemoveNodeTaint JSON-patches any taint matching taintKey off the Node
+	// named nodeName. A no-op if the Node carries no such taint.
+	RemoveNodeTaint(nodeName string, taintKey string) error
+}
+
+// spokeK8sClient is the default SpokeK8sClient, backed by a client-go
+// clientset built from an admin kubeconfig Secret.
+type spokeK8sClient struct {
+	clientset kubernetes.Interface
+}
+
+var _ SpokeK8sClientFactory = &defaultSpokeK8sClientFactory{}
+
+// defaultSpokeK8sClientFactory is SpokeK8sClientFactory's production
+// implementation.
+type defaultSpokeK8sClientFactory struct{}
+
+// NewSpokeK8sClientFactory returns the production SpokeK8sClientFactory used
+// by AgentReconciler.
+func NewSpokeK8sClientFactory() SpokeK8sClientFactory {
+	return &defaultSpokeK8sClientFactory{}
+}
+
+func (defaultSpokeK8sClientFactory) Create(kubeconfigSecret *corev1.Secret) (SpokeK8sClient, error) {
+	kubeconfig, ok := kubeconfigSecret.Data["kubeconfig"]
+	if !ok {
+		return nil, errors.Errorf("secret %s/%s has no \"kubeconfig\" data key", kubeconfigSecret.Namespace, kubeconfigSecret.Name)
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing admin kubeconfig")
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "building spoke clientset")
+	}
+	return &spokeK8sClient{clientset: clientset}, nil
+}
+
+func (c *spokeK8sClient) GetNode(name string) (*corev1.Node, error) {
+	return c.clientset.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func (c *spokeK8sClient) ListCsrs() (*certificatesv1.CertificateSigningRequestList, error) {
+	return c.clientset.CertificatesV1().CertificateSigningRequests().List(context.Background(), metav1.ListOptions{})
+}
+
+func (c *spokeK8sClient) ApproveCsr(csr *certificatesv1.CertificateSigningRequest) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:    certificatesv1.CertificateApproved,
+		Reason:  "NodeCSRApprove",
+		Message: "This CSR was approved by the assisted-service",
+		Status:  corev1.ConditionTrue,
+	})
+	_, err := c.clientset.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.Background(), csr.Name, csr, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "approving csr %s", csr.Name)
+}
+
+func (c *spokeK8sClient) DenyCsr(csr *certificatesv1.CertificateSigningRequest, reason string) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:    certificatesv1.CertificateDenied,
+		Reason:  "NodeCSRDeny",
+		Message: reason,
+		Status:  corev1.ConditionTrue,
+	})
+	_, err := c.clientset.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.Background(), csr.Name, csr, metav1.UpdateOptions{})
+	return errors.Wrapf(err, "denying csr %s", csr.Name)
+}
+
+func (c *spokeK8sClient) AddNodeTaint(nodeName string, taint corev1.Taint) error {
+	node, err := c.GetNode(nodeName)
+	if err != nil {
+		return err
+	}
+	if taintPresent(node.Spec.Taints, taint.Key, taint.Effect) {
+		return nil
+	}
+	return c.patchNodeTaints(nodeName, append(node.Spec.Taints, taint))
+}
+
+func (c *spokeK8sClient) RemoveNodeTaint(nodeName string, taintKey string) error {
+	node, err := c.GetNode(nodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	remaining := node.Spec.Taints[:0]
+	for _, t := range node.Spec.Taints {
+		if t.Key != taintKey {
+			remaining = append(remaining, t)
+		}
+	}
+	if len(remaining) == len(node.Spec.Taints) {
+		return nil
+	}
+	return c.patchNodeTaints(nodeName, remaining)
+}
+
+// nodeTaintsPatch is the JSON-patch body patchNodeTaints sends: a single
+// "replace" of the whole taints array, so the write is atomic even though
+// AddNodeTaint/RemoveNodeTaint compute the new array from a GetNode read
+// that could otherwise race a concurrent taint change.
+type nodeTaintsPatch struct {
+	Op    string         `json:"op"`
+	Path  string         `json:"path"`
+	Value []corev1.Taint `json:"value"`
+}
+
+func (c *spokeK8sClient) patchNodeTaints(nodeName string, taints []corev1.Taint) error {
+	if taints == nil {
+		taints = []corev1.Taint{}
+	}
+	patch, err := json.Marshal([]nodeTaintsPatch{{Op: "replace", Path: "/spec/taints", Value: taints}})
+	if err != nil {
+		return errors.Wrap(err, "marshaling node taints patch")
+	}
+	_, err = c.clientset.CoreV1().Nodes().Patch(context.Background(), nodeName, types.JSONPatchType, patch, metav1.PatchOptions{})
+	return errors.Wrapf(err, "patching taints on node %s", nodeName)
+}
+
+func taintPresent(taints []corev1.Taint, key string, effect corev1.TaintEffect) bool {
+	for _, t := range taints {
+		if t.Key == key && t.Effect == effect {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AgentNodeStatus is the subset of a matched spoke Node's state that
+// AgentReconciler mirrors onto the Agent CR's status once installation has
+// handed the host off to the cluster. It is intentionally a plain struct
+// rather than a patch against v1beta1.AgentStatus so this package doesn't
+// need to import the CRD types to compute it; the reconciler is responsible
+// for copying these fields onto Agent.Status.{NodeRef,NodeReady,
+// KubeletVersion,LastHeartbeatTime}.
+type AgentNodeStatus struct {
+	NodeRef           *corev1.ObjectReference
+	NodeReady         bool
+	KubeletVersion    string
+	LastHeartbeatTime metav1.Time
+}
+
+// matchNodeForAgent finds the spoke Node corresponding to an Agent by MAC
+// address: each of the host's inventory interface MAC addresses (BMC MAC is
+// included as just another candidate, since some platforms report it as the
+// primary NIC's MAC too) is compared against Node.status.addresses, since
+// nothing in the install flow otherwise guarantees the Agent's hostname
+// matches the Node's name.
+func matchNodeForAgent(ctx context.Context, spokeReader client.Reader, macAddresses []string) (*corev1.Node, error) {
+	if len(macAddresses) == 0 {
+		return nil, nil
+	}
+	wanted := make(map[string]bool, len(macAddresses))
+	for _, mac := range macAddresses {
+		wanted[normalizeMAC(mac)] = true
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := spokeReader.List(ctx, nodes); err != nil {
+		return nil, errors.Wrap(err, "listing spoke nodes")
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == "MAC" && wanted[normalizeMAC(addr.Address)] {
+				return node, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// normalizeMAC lower-cases a MAC address so inventory-reported and
+// Node-reported forms compare equal regardless of case.
+func normalizeMAC(mac string) string {
+	out := make([]byte, len(mac))
+	for i := 0; i < len(mac); i++ {
+		c := mac[i]
+		if c >= 'A' && c <= 'F' {
+			c += 'a' - 'A'
+		}
+		out[i] = c
+	}
+	return string(out)
+}
+
+// agentNodeStatusFromNode derives the Agent status fields AgentReconciler
+// mirrors from node.
+func agentNodeStatusFromNode(node *corev1.Node) AgentNodeStatus {
+	status := AgentNodeStatus{
+		NodeRef: &corev1.ObjectReference{
+			Kind:      "Node",
+			Name:      node.Name,
+			UID:       node.UID,
+			Namespace: node.Namespace,
+		},
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			status.NodeReady = cond.Status == corev1.ConditionTrue
+			status.LastHeartbeatTime = cond.LastHeartbeatTime
+			break
+		}
+	}
+	status.KubeletVersion = node.Status.NodeInfo.KubeletVersion
+	return status
+}
+
+// deleteOrCordonNode implements the opt-in Agent finalizer behavior: when an
+// Agent with spec.DeleteNodeOnDelete is removed, its matched spoke Node is
+// deleted outright; otherwise (when cordonNode is requested instead) the Node
+// is only cordoned, mirroring what Cluster API does for deleted Machines so
+// workloads are evicted before the underlying host is reused or decommissioned.
+func deleteOrCordonNode(ctx context.Context, spokeClient client.Client, nodeName string, deleteNode bool) error {
+	node := &corev1.Node{}
+	if err := spokeClient.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "getting spoke node %s", nodeName)
+	}
+
+	if deleteNode {
+		if err := spokeClient.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "deleting spoke node %s", nodeName)
+		}
+		return nil
+	}
+
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	if err := spokeClient.Update(ctx, node); err != nil {
+		return errors.Wrapf(err, "cordoning spoke node %s", nodeName)
+	}
+	return nil
+}
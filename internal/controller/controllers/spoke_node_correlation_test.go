@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func nodeWithMAC(name, mac string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: "MAC", Address: mac}},
+		},
+	}
+}
+
+func spokeClientWithNodes(nodes ...*corev1.Node) client.Client {
+	builder := fakeclient.NewClientBuilder()
+	for _, n := range nodes {
+		builder = builder.WithObjects(n)
+	}
+	return builder.Build()
+}
+
+var _ = Describe("matchNodeForAgent", func() {
+	It("matches a node by MAC address, case-insensitively", func() {
+		c := spokeClientWithNodes(nodeWithMAC("node-1", "AA:BB:CC:DD:EE:FF"))
+		node, err := matchNodeForAgent(context.Background(), c, []string{"aa:bb:cc:dd:ee:ff"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).ToNot(BeNil())
+		Expect(node.Name).To(Equal("node-1"))
+	})
+
+	It("returns nil when no node matches any candidate MAC", func() {
+		c := spokeClientWithNodes(nodeWithMAC("node-1", "AA:BB:CC:DD:EE:FF"))
+		node, err := matchNodeForAgent(context.Background(), c, []string{"11:22:33:44:55:66"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(BeNil())
+	})
+
+	It("returns nil without listing nodes when no candidate MACs are given", func() {
+		c := spokeClientWithNodes()
+		node, err := matchNodeForAgent(context.Background(), c, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(BeNil())
+	})
+})
+
+var _ = Describe("agentNodeStatusFromNode", func() {
+	It("derives NodeRef, NodeReady and kubelet version from the matched node", func() {
+		heartbeat := metav1.Now()
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "abc-123"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: heartbeat},
+				},
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.27.3"},
+			},
+		}
+
+		status := agentNodeStatusFromNode(node)
+		Expect(status.NodeRef.Name).To(Equal("node-1"))
+		Expect(status.NodeReady).To(BeTrue())
+		Expect(status.KubeletVersion).To(Equal("v1.27.3"))
+		Expect(status.LastHeartbeatTime).To(Equal(heartbeat))
+	})
+
+	It("reports NodeReady false when the node has no Ready condition yet", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+		status := agentNodeStatusFromNode(node)
+		Expect(status.NodeReady).To(BeFalse())
+	})
+})
+
+var _ = Describe("deleteOrCordonNode", func() {
+	It("deletes the node when deleteNode is true", func() {
+		c := spokeClientWithNodes(nodeWithMAC("node-1", "AA:BB:CC:DD:EE:FF"))
+		Expect(deleteOrCordonNode(context.Background(), c, "node-1", true)).To(Succeed())
+
+		node := &corev1.Node{}
+		err := c.Get(context.Background(), client.ObjectKey{Name: "node-1"}, node)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("cordons the node instead of deleting it when deleteNode is false", func() {
+		c := spokeClientWithNodes(nodeWithMAC("node-1", "AA:BB:CC:DD:EE:FF"))
+		Expect(deleteOrCordonNode(context.Background(), c, "node-1", false)).To(Succeed())
+
+		node := &corev1.Node{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Name: "node-1"}, node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("is a no-op when the node no longer exists", func() {
+		c := spokeClientWithNodes()
+		Expect(deleteOrCordonNode(context.Background(), c, "missing-node", true)).To(Succeed())
+	})
+})
@@ -0,0 +1,141 @@
+package ignition
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	config_34_types "github.com/coreos/ignition/v2/config/v3_4/types"
+	"github.com/pkg/errors"
+)
+
+// RegistryTrustType is the containers/image policy.json trust type used to
+// verify images pulled from a mirrored registry.
+type RegistryTrustType string
+
+const (
+	RegistryTrustSignedBy               RegistryTrustType = "signedBy"
+	RegistryTrustSigstoreSigned         RegistryTrustType = "sigstoreSigned"
+	RegistryTrustInsecureAcceptAnything RegistryTrustType = "insecureAcceptAnything"
+)
+
+// RegistryTrustConfig is the per-registry detached-signature verification
+// policy a caller supplies alongside a mirror registries configuration.
+// GPGKeys holds one or more ASCII-armored GPG or cosign public keys, each
+// embedded as its own ignition storage file and referenced from policy.json.
+type RegistryTrustConfig struct {
+	Type             RegistryTrustType
+	GPGKeys          []string
+	Lookaside        string
+	LookasideStaging string
+}
+
+const (
+	containerPolicyPath      = "/etc/containers/policy.json"
+	containerRegistriesDDir  = "/etc/containers/registries.d"
+	containerSignatureKeyDir = "/etc/pki/containers/mirror-keys"
+)
+
+// containerSignaturePolicyFiles renders /etc/containers/policy.json and one
+// /etc/containers/registries.d/<host>.yaml per trusted registry, plus the GPG
+// or cosign keys they reference, so crio/podman enforce detached-signature
+// verification against the mirrored registries.
+func containerSignaturePolicyFiles(trust map[string]RegistryTrustConfig) ([]config_34_types.File, error) {
+	if len(trust) == 0 {
+		return nil, nil
+	}
+
+	hosts := make([]string, 0, len(trust))
+	for host := range trust {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var files []config_34_types.File
+	transports := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		cfg := trust[host]
+
+		keyPaths := make([]string, 0, len(cfg.GPGKeys))
+		for i, key := range cfg.GPGKeys {
+			path := fmt.Sprintf("%s/%s-%d.gpg", containerSignatureKeyDir, sanitizeRegistryHost(host), i)
+			files = append(files, fileFromString(path, "root", mirrorRegistriesFileMode, base64.StdEncoding.EncodeToString([]byte(key))))
+			keyPaths = append(keyPaths, path)
+		}
+
+		entry, err := policyEntryForRegistry(cfg, keyPaths)
+		if err != nil {
+			return nil, errors.Wrapf(err, "registry %s", host)
+		}
+		transports = append(transports, fmt.Sprintf(`"%s": [ %s ]`, host, entry))
+
+		files = append(files, fileFromString(
+			fmt.Sprintf("%s/%s.yaml", containerRegistriesDDir, host),
+			"root", mirrorRegistriesFileMode,
+			base64.StdEncoding.EncodeToString([]byte(registriesDYaml(host, cfg)))))
+	}
+
+	policy := fmt.Sprintf(`{
+  "default": [ { "type": "insecureAcceptAnything" } ],
+  "transports": {
+    "docker": { %s }
+  }
+}`, strings.Join(transports, ", "))
+	files = append(files, fileFromString(containerPolicyPath, "root", mirrorRegistriesFileMode,
+		base64.StdEncoding.EncodeToString([]byte(policy))))
+
+	return files, nil
+}
+
+// policyEntryForRegistry renders cfg's trust policy as one or more
+// comma-joined policy.json requirement objects - the call site wraps the
+// result in a JSON array, and policy.json accepts an image signed by any
+// one of several requirement objects in the same transport entry, so every
+// key in keyPaths needs its own object rather than only the first.
+func policyEntryForRegistry(cfg RegistryTrustConfig, keyPaths []string) (string, error) {
+	switch cfg.Type {
+	case RegistryTrustInsecureAcceptAnything:
+		return `{ "type": "insecureAcceptAnything" }`, nil
+	case RegistryTrustSignedBy:
+		if len(keyPaths) == 0 {
+			return "", errors.New("signedBy trust requires at least one GPG key")
+		}
+		return joinPolicyRequirements(keyPaths, func(keyPath string) string {
+			return fmt.Sprintf(`{ "type": "signedBy", "keyType": "GPGKeys", "keyPath": %q }`, keyPath)
+		}), nil
+	case RegistryTrustSigstoreSigned:
+		if len(keyPaths) == 0 {
+			return "", errors.New("sigstoreSigned trust requires at least one cosign key")
+		}
+		return joinPolicyRequirements(keyPaths, func(keyPath string) string {
+			return fmt.Sprintf(`{ "type": "sigstoreSigned", "keyPath": %q }`, keyPath)
+		}), nil
+	default:
+		return "", fmt.Errorf("unknown registry trust type %q", cfg.Type)
+	}
+}
+
+func joinPolicyRequirements(keyPaths []string, render func(string) string) string {
+	entries := make([]string, len(keyPaths))
+	for i, keyPath := range keyPaths {
+		entries[i] = render(keyPath)
+	}
+	return strings.Join(entries, ", ")
+}
+
+func registriesDYaml(host string, cfg RegistryTrustConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "docker:\n  %s:\n", host)
+	if cfg.Lookaside != "" {
+		fmt.Fprintf(&b, "    lookaside: %s\n", cfg.Lookaside)
+	}
+	if cfg.LookasideStaging != "" {
+		fmt.Fprintf(&b, "    lookaside-staging: %s\n", cfg.LookasideStaging)
+	}
+	return b.String()
+}
+
+func sanitizeRegistryHost(host string) string {
+	return strings.NewReplacer(":", "-", "/", "-").Replace(host)
+}
@@ -0,0 +1,212 @@
+package ignition
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	config_34_types "github.com/coreos/ignition/v2/config/v3_4/types"
+	"github.com/pkg/errors"
+)
+
+// DNSResolverProtocol is the encrypted transport used to reach an
+// EncryptedDNSResolver.
+type DNSResolverProtocol string
+
+const (
+	DNSResolverDoH DNSResolverProtocol = "DoH"
+	DNSResolverDoT DNSResolverProtocol = "DoT"
+)
+
+// EncryptedDNSResolver is one upstream resolver the discovery ignition
+// should route DNS queries through instead of whatever DHCP/the proxy
+// settings would otherwise hand out.
+//
+// Address is a `https://host[:port]/path` dns-query endpoint for DoH, or a
+// `tls://host:port` endpoint for DoT. BootstrapIP is the literal IP
+// assisted-service resolved Address's host to at config-generation time;
+// it's required unless the host is already a literal IP or appears in
+// NoProxy, since otherwise the host can't be looked up before DNS itself is
+// redirected to this resolver.
+type EncryptedDNSResolver struct {
+	Protocol    DNSResolverProtocol
+	Address     string
+	BootstrapIP string
+}
+
+// EncryptedDNSConfig carries the infra-env's encrypted-DNS settings.
+// Domains restricts routing to the listed domains (systemd-resolved's
+// per-link Domains=); an empty list routes every query through Resolvers.
+type EncryptedDNSConfig struct {
+	Resolvers []EncryptedDNSResolver
+	Domains   []string
+}
+
+const (
+	resolvedDropInPath = "/etc/systemd/resolved.conf.d/90-assisted-encrypted-dns.conf"
+	resolvConfPath     = "/etc/resolv.conf"
+	dohProxyConfPath   = "/etc/doh-proxy/config.yaml"
+	dohProxyListenAddr = "127.0.0.1:5053"
+	dohProxyUnitName   = "doh-proxy.service"
+)
+
+// encryptedDNSFiles renders the resolver configuration requested for the
+// discovery ignition. DoT resolvers are wired directly into a
+// systemd-resolved drop-in (DNS= plus DNSOverTLS=yes); systemd-resolved
+// can't speak DoH itself, so a DoH resolver instead gets a small
+// container-run proxy listening on loopback, with resolved pointed at that
+// proxy instead. /etc/resolv.conf is wired to the systemd-resolved stub
+// listener (127.0.0.53) either way.
+func encryptedDNSFiles(cfg *EncryptedDNSConfig, noProxy string) ([]config_34_types.File, []string, error) {
+	if cfg == nil || len(cfg.Resolvers) == 0 {
+		return nil, nil, nil
+	}
+
+	noProxyDomains := splitNoProxy(noProxy)
+
+	var dnsEntries []string
+	var dohResolver *EncryptedDNSResolver
+	for i := range cfg.Resolvers {
+		resolver := cfg.Resolvers[i]
+		host, err := resolverHost(resolver)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resolver.BootstrapIP == "" && net.ParseIP(host) == nil && !domainAllowed(host, noProxyDomains) {
+			return nil, nil, errors.Errorf(
+				"encrypted DNS resolver %q needs a bootstrap IP, or its hostname must be listed in NoProxy, "+
+					"so it can be reached before DNS is redirected to it", resolver.Address)
+		}
+
+		switch resolver.Protocol {
+		case DNSResolverDoT:
+			ip := resolver.BootstrapIP
+			if ip == "" {
+				ip = host
+			}
+			dnsEntries = append(dnsEntries, fmt.Sprintf("%s#%s", ip, host))
+		case DNSResolverDoH:
+			if dohResolver != nil {
+				return nil, nil, errors.New("only one DoH resolver is supported")
+			}
+			r := resolver
+			dohResolver = &r
+		default:
+			return nil, nil, errors.Errorf("unknown encrypted DNS resolver protocol %q", resolver.Protocol)
+		}
+	}
+
+	var files []config_34_types.File
+	var units []string
+
+	if dohResolver != nil {
+		files = append(files, fileFromString(dohProxyConfPath, "root", 0o644,
+			base64.StdEncoding.EncodeToString([]byte(dohProxyConfigYAML(*dohResolver)))))
+		units = append(units, dohProxyUnit())
+		dnsEntries = append(dnsEntries, dohProxyListenAddr)
+	}
+
+	files = append(files,
+		fileFromString(resolvedDropInPath, "root", 0o644,
+			base64.StdEncoding.EncodeToString([]byte(resolvedDropIn(dnsEntries, cfg.Domains)))),
+		fileFromString(resolvConfPath, "root", 0o644,
+			base64.StdEncoding.EncodeToString([]byte("nameserver 127.0.0.53\n"))),
+	)
+
+	return files, units, nil
+}
+
+func resolverHost(r EncryptedDNSResolver) (string, error) {
+	switch r.Protocol {
+	case DNSResolverDoH:
+		if !strings.HasPrefix(r.Address, "https://") {
+			return "", errors.Errorf("DoH resolver address %q must be an https:// dns-query URL", r.Address)
+		}
+		u, err := url.Parse(r.Address)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid DoH resolver address %q", r.Address)
+		}
+		return u.Hostname(), nil
+	case DNSResolverDoT:
+		if !strings.HasPrefix(r.Address, "tls://") {
+			return "", errors.Errorf("DoT resolver address %q must be a tls://host:port URL", r.Address)
+		}
+		host, _, err := net.SplitHostPort(strings.TrimPrefix(r.Address, "tls://"))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid DoT resolver address %q", r.Address)
+		}
+		return host, nil
+	default:
+		return "", errors.Errorf("unknown encrypted DNS resolver protocol %q", r.Protocol)
+	}
+}
+
+// splitNoProxy parses a comma-separated NO_PROXY list into bare domains
+// (leading dots, which some callers use to mean "and subdomains", stripped
+// since domainAllowed already matches subdomains).
+func splitNoProxy(noProxy string) []string {
+	var domains []string
+	for _, d := range strings.Split(noProxy, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, strings.TrimPrefix(d, "."))
+		}
+	}
+	return domains
+}
+
+func domainAllowed(host string, noProxyDomains []string) bool {
+	for _, d := range noProxyDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolvedDropIn(dnsEntries, domains []string) string {
+	var b strings.Builder
+	b.WriteString("[Resolve]\n")
+	if len(dnsEntries) > 0 {
+		fmt.Fprintf(&b, "DNS=%s\n", strings.Join(dnsEntries, " "))
+		b.WriteString("DNSOverTLS=yes\n")
+	}
+	if len(domains) > 0 {
+		fmt.Fprintf(&b, "Domains=%s\n", strings.Join(domains, " "))
+	}
+	return b.String()
+}
+
+func dohProxyConfigYAML(r EncryptedDNSResolver) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "upstream: %s\n", r.Address)
+	fmt.Fprintf(&b, "listen: %s\n", dohProxyListenAddr)
+	if r.BootstrapIP != "" {
+		fmt.Fprintf(&b, "bootstrap-ip: %s\n", r.BootstrapIP)
+	}
+	return b.String()
+}
+
+// dohProxyUnit renders the systemd unit that runs a small podman container
+// translating plaintext DNS on dohProxyListenAddr into DoH requests against
+// the upstream configured in dohProxyConfPath.
+func dohProxyUnit() string {
+	contents := fmt.Sprintf(`[Unit]
+Description=Proxy plaintext DNS to the configured DoH resolver
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStartPre=-/usr/bin/podman kill doh-proxy
+ExecStartPre=-/usr/bin/podman rm doh-proxy
+ExecStart=/usr/bin/podman run --name doh-proxy --network=host --rm -v %s:%s:Z quay.io/openshift/doh-proxy:latest --config %s
+ExecStop=/usr/bin/podman stop doh-proxy
+Restart=always
+
+[Install]
+WantedBy=multi-user.target
+`, dohProxyConfPath, dohProxyConfPath, dohProxyConfPath)
+
+	return fmt.Sprintf(`{ "name": %q, "enabled": true, "contents": %q }`, dohProxyUnitName, contents)
+}
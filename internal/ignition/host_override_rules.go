@@ -0,0 +1,164 @@
+package ignition
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+)
+
+// HostIgnitionOverrideRule is a cluster-level rule that merges an ignition
+// fragment into a host's per-role ignition whenever a CEL expression
+// evaluated against the host's inventory facts returns true.
+//
+// Expression has access to `cpu`, `disks`, `interfaces`, `systemVendor`,
+// `hostname` and `role`, matching the corresponding fields of models.Inventory
+// (and models.Host for hostname/role), e.g.:
+//
+//	cpu.architecture == "x86_64" && disks.exists(d, d.size_bytes > 2e12)
+type HostIgnitionOverrideRule struct {
+	Name       string
+	Expression string
+	Ignition   json.RawMessage
+}
+
+// compiledHostOverrideRule pairs a HostIgnitionOverrideRule with its compiled,
+// type-checked CEL program so it only needs to be parsed once per cluster.
+type compiledHostOverrideRule struct {
+	rule    HostIgnitionOverrideRule
+	program cel.Program
+}
+
+func hostOverrideRuleCelEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("cpu", cel.DynType),
+		cel.Variable("disks", cel.DynType),
+		cel.Variable("interfaces", cel.DynType),
+		cel.Variable("systemVendor", cel.DynType),
+		cel.Variable("hostname", cel.StringType),
+		cel.Variable("role", cel.StringType),
+	)
+}
+
+// compileHostIgnitionOverrideRules parses and type-checks every rule once.
+// It rejects expressions that reference unknown identifiers or that do not
+// evaluate to a boolean, so misconfigured rules are caught at cluster-update
+// time rather than while generating ignitions for every host.
+func compileHostIgnitionOverrideRules(rules []HostIgnitionOverrideRule) ([]compiledHostOverrideRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	env, err := hostOverrideRuleCelEnv()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build CEL environment for host ignition override rules")
+	}
+
+	compiled := make([]compiledHostOverrideRule, 0, len(rules))
+	for _, rule := range rules {
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, errors.Wrapf(issues.Err(), "invalid override rule %q", rule.Name)
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("override rule %q must evaluate to a boolean, got %s", rule.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to build program for override rule %q", rule.Name)
+		}
+		if !json.Valid(rule.Ignition) {
+			return nil, fmt.Errorf("override rule %q does not carry a valid JSON ignition fragment", rule.Name)
+		}
+		compiled = append(compiled, compiledHostOverrideRule{rule: rule, program: program})
+	}
+	return compiled, nil
+}
+
+// matchingHostOverrideFragments evaluates every compiled rule against a
+// host's inventory, in the deterministic (declaration) order the rules were
+// given, and returns the ignition fragments of the rules that matched.
+// An evaluation error for a single rule is returned to the caller so it can
+// be surfaced per-host without aborting ignition generation for the rest of
+// the cluster.
+func matchingHostOverrideFragments(host *models.Host, rules []compiledHostOverrideRule) ([]json.RawMessage, error) {
+	if len(rules) == 0 || host.Inventory == "" {
+		return nil, nil
+	}
+
+	var inventory models.Inventory
+	if err := json.Unmarshal([]byte(host.Inventory), &inventory); err != nil {
+		return nil, errors.Wrap(err, "failed to parse host inventory")
+	}
+
+	vars := map[string]interface{}{
+		"cpu":          toDyn(inventory.CPU),
+		"disks":        toDyn(inventory.Disks),
+		"interfaces":   toDyn(inventory.Interfaces),
+		"systemVendor": toDyn(inventory.SystemVendor),
+		"hostname":     inventory.Hostname,
+		"role":         string(host.Role),
+	}
+
+	var fragments []json.RawMessage
+	var evalErrs []string
+	for _, r := range rules {
+		out, _, err := r.program.Eval(vars)
+		if err != nil {
+			evalErrs = append(evalErrs, fmt.Sprintf("rule %q: %v", r.rule.Name, err))
+			continue
+		}
+		if matched, ok := out.Value().(bool); ok && matched {
+			fragments = append(fragments, r.rule.Ignition)
+		} else if refVal, ok := out.(ref.Val); ok {
+			if b, ok := refVal.Value().(bool); ok && b {
+				fragments = append(fragments, r.rule.Ignition)
+			}
+		}
+	}
+
+	if len(evalErrs) > 0 {
+		sort.Strings(evalErrs)
+		return fragments, fmt.Errorf("host %s: %d override rule(s) failed to evaluate: %s",
+			host.ID, len(evalErrs), evalErrs[0])
+	}
+	return fragments, nil
+}
+
+// toDyn round-trips a typed inventory substruct through JSON so CEL's dynamic
+// type machinery can address its fields by the same names the API uses.
+func toDyn(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// applyHostIgnitionOverrideRules merges every matching rule's fragment into
+// base using the same ignition merge semantics used for explicit
+// IgnitionConfigOverrides, returning the possibly-updated ignition. Per-host
+// evaluation errors are returned alongside whatever fragments did evaluate
+// successfully, so callers can log-and-continue rather than failing the
+// whole cluster.
+func applyHostIgnitionOverrideRules(base []byte, host *models.Host, rules []compiledHostOverrideRule) ([]byte, error) {
+	fragments, evalErr := matchingHostOverrideFragments(host, rules)
+
+	out := base
+	for _, fragment := range fragments {
+		merged, err := MergeIgnitionConfig(out, fragment)
+		if err != nil {
+			return base, errors.Wrapf(err, "failed to merge matched override rule into host %s ignition", host.ID)
+		}
+		out = []byte(merged)
+	}
+	return out, evalErr
+}
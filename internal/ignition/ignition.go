@@ -0,0 +1,962 @@
+package ignition
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config_31 "github.com/coreos/ignition/v2/config/v3_1"
+	config_31_types "github.com/coreos/ignition/v2/config/v3_1/types"
+	config_32 "github.com/coreos/ignition/v2/config/v3_2"
+	config_32_types "github.com/coreos/ignition/v2/config/v3_2/types"
+	config_33 "github.com/coreos/ignition/v2/config/v3_3"
+	config_33_types "github.com/coreos/ignition/v2/config/v3_3/types"
+	config_34 "github.com/coreos/ignition/v2/config/v3_4"
+	config_34_types "github.com/coreos/ignition/v2/config/v3_4/types"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	bmh_v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/internal/host/hostutil"
+	"github.com/openshift/assisted-service/internal/operators"
+	"github.com/openshift/assisted-service/internal/provider/registry"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/pkg/mirrorregistries"
+	"github.com/openshift/assisted-service/pkg/s3wrapper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// latestIgnitionVersion is the highest ignition spec version this package natively
+// understands. ParseToLatest never returns anything newer than this, even if a
+// caller somehow hands it a config declaring a newer version.
+const latestIgnitionVersion = "3.4.0"
+
+// fileNames is the set of cluster-scoped ignition/metadata artifacts that are
+// produced alongside the per-host ignitions and uploaded to S3 as a unit.
+var fileNames = []string{
+	"bootstrap.ign",
+	"master.ign",
+	"worker.ign",
+	"metadata.json",
+	"kubeconfig-noingress",
+	"kubeadmin-password",
+	"install-config.yaml",
+}
+
+// Generator is responsible for mutating the ignition files produced by
+// openshift-install so that hosts discovered/managed by assisted-service boot
+// with the right identity, overrides and day-2 wiring.
+type Generator interface {
+	Generate(ctx context.Context, installConfig []byte) error
+	UploadToS3(ctx context.Context) error
+	UpdateEtcHosts(serviceIPs string) error
+}
+
+// installerGenerator implements Generator against a local workDir populated by
+// openshift-install, before the resulting artifacts are uploaded to S3.
+type installerGenerator struct {
+	log                     logrus.FieldLogger
+	workDir                 string
+	cacheDir                string
+	cluster                 *common.Cluster
+	releaseImage            string
+	releaseImageMirror      string
+	serviceCACertPath       string
+	installerImage          string
+	s3Client                s3wrapper.API
+	operatorManager         operators.API
+	providerRegistry        registry.ProviderRegistry
+	mirrorRegistriesBuilder mirrorregistries.MirrorRegistriesConfigBuilder
+	installInvoker          string
+	encodedDhcpFileContents string
+
+	// TargetIgnitionVersion is the ignition spec version that merged,
+	// generated output should be emitted at. It is selected from the
+	// bootstrap.ign produced by openshift-install so that assisted-service
+	// never downgrades a config it did not originate.
+	//
+	// TODO(deprecate-ignition-3.1.0)
+	TargetIgnitionVersion string
+
+	// hostIgnitionOverrideRules are compiled once per cluster and evaluated
+	// per-host in createHostIgnitions; see SetHostIgnitionOverrideRules.
+	hostIgnitionOverrideRules []compiledHostOverrideRule
+
+	// mirrorRegistriesTrust is the detached-signature verification policy
+	// rendered into the mirror registry files; see SetMirrorRegistriesTrust.
+	mirrorRegistriesTrust map[string]RegistryTrustConfig
+
+	// trustedOverrideKeys and hostOverrideSignatures gate per-host
+	// IgnitionConfigOverrides behind a detached signature; see
+	// SetTrustedOverrideKeys.
+	trustedOverrideKeys    []ed25519.PublicKey
+	hostOverrideSignatures map[strfmt.UUID][]byte
+
+	// recorder, when set, captures createHostIgnitions' per-host inputs and
+	// rendered output into reproducer bundles; see SetRecorderConfig.
+	recorder *RecorderConfig
+
+	// uploadConcurrency bounds how many UploadToS3 uploads run at once; see
+	// SetUploadConcurrency. Zero means defaultUploadConcurrency.
+	uploadConcurrency int
+
+	// manifestSigningKey, when set, signs the manifest.json UploadToS3
+	// writes alongside the uploaded artifacts; see SetManifestSigningKey.
+	manifestSigningKey ed25519.PrivateKey
+}
+
+// SetRecorderConfig enables the record-and-replay reproducer harness for
+// createHostIgnitions; see RecorderConfig. Leaving it unset (the default)
+// preserves the existing unrecorded behavior.
+func (g *installerGenerator) SetRecorderConfig(cfg *RecorderConfig) {
+	g.recorder = cfg
+}
+
+// SetUploadConcurrency overrides how many files UploadToS3 uploads at once.
+// Leaving it unset (the default) uses defaultUploadConcurrency.
+func (g *installerGenerator) SetUploadConcurrency(concurrency int) {
+	g.uploadConcurrency = concurrency
+}
+
+// SetManifestSigningKey signs UploadToS3's manifest.json with key, using the
+// same Ed25519 detached-signature mechanism as SignOverride/
+// verifyOverrideSignature, so a holder of the matching public key can
+// confirm the manifest (and, transitively, every artifact it lists the
+// SHA-256 of) was produced by this service. Leaving it unset (the default)
+// uploads an unsigned manifest.
+func (g *installerGenerator) SetManifestSigningKey(key ed25519.PrivateKey) {
+	g.manifestSigningKey = key
+}
+
+// SetTrustedOverrideKeys requires every host's IgnitionConfigOverrides to
+// carry a detached signature in signatures (keyed by host ID) that verifies
+// against one of keys before createHostIgnitions merges it. Leaving keys
+// empty (the default) preserves the existing unsigned-override behavior.
+func (g *installerGenerator) SetTrustedOverrideKeys(keys []ed25519.PublicKey, signatures map[strfmt.UUID][]byte) {
+	g.trustedOverrideKeys = keys
+	g.hostOverrideSignatures = signatures
+}
+
+// SetMirrorRegistriesTrust installs the per-registry signature verification
+// policy that mirrorRegistryFiles renders into master.ign/worker.ign and the
+// bootstrap ignition, alongside registries.conf and the mirror CA bundle.
+// Leaving it unset preserves the existing registries.conf/CA-only behavior.
+func (g *installerGenerator) SetMirrorRegistriesTrust(trust map[string]RegistryTrustConfig) {
+	g.mirrorRegistriesTrust = trust
+}
+
+// SetHostIgnitionOverrideRules compiles and installs the cluster-level,
+// CEL-keyed ignition override rules that createHostIgnitions evaluates
+// against each host's inventory, on top of any explicit per-host
+// IgnitionConfigOverrides.
+func (g *installerGenerator) SetHostIgnitionOverrideRules(rules []HostIgnitionOverrideRule) error {
+	compiled, err := compileHostIgnitionOverrideRules(rules)
+	if err != nil {
+		return err
+	}
+	g.hostIgnitionOverrideRules = compiled
+	return nil
+}
+
+// NewGenerator creates a Generator that mutates the ignition files found under workDir.
+func NewGenerator(workDir, cacheDir string, cluster *common.Cluster, releaseImage, releaseImageMirror,
+	serviceCACertPath, installerImage string, s3Client s3wrapper.API, log logrus.FieldLogger,
+	operatorManager operators.API, providerRegistry registry.ProviderRegistry,
+	mirrorRegistriesBuilder mirrorregistries.MirrorRegistriesConfigBuilder, installInvoker string) Generator {
+	return &installerGenerator{
+		log:                     log,
+		workDir:                 workDir,
+		cacheDir:                cacheDir,
+		cluster:                 cluster,
+		releaseImage:            releaseImage,
+		releaseImageMirror:      releaseImageMirror,
+		serviceCACertPath:       serviceCACertPath,
+		installerImage:          installerImage,
+		s3Client:                s3Client,
+		operatorManager:         operatorManager,
+		providerRegistry:        providerRegistry,
+		mirrorRegistriesBuilder: mirrorRegistriesBuilder,
+		installInvoker:          installInvoker,
+		// Default to 3.2.0 until a bootstrap.ign is inspected; updateBootstrap
+		// overrides this with whatever version openshift-install actually produced.
+		TargetIgnitionVersion: "3.2.0",
+	}
+}
+
+func (g *installerGenerator) Generate(ctx context.Context, installConfig []byte) error {
+	bootstrapPath := filepath.Join(g.workDir, "bootstrap.ign")
+	if err := g.updateBootstrap(ctx, bootstrapPath); err != nil {
+		return err
+	}
+	if err := g.updateIgnitions(); err != nil {
+		return err
+	}
+	return g.createHostIgnitions()
+}
+
+// anyConfig is the superset representation ParseToLatest normalizes onto: a
+// 3.4 config can always represent anything expressible by 3.1/3.2/3.3, so it
+// doubles as the version-agnostic internal representation other code in this
+// package works with.
+type anyConfig = config_34_types.Config
+
+// ParseToLatest parses raw ignition JSON, detecting its declared spec version,
+// and returns the highest-fidelity struct this package supports: a v3.1, v3.2,
+// v3.3 or v3.4 config is up-converted to a config_34_types.Config, but its
+// Ignition.Version field is preserved so callers (and MergeIgnitionConfig) can
+// still tell which version the content was actually authored against.
+func ParseToLatest(content []byte) (*anyConfig, error) {
+	var versionCheck struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+	if err := json.Unmarshal(content, &versionCheck); err != nil {
+		return nil, errors.Wrap(err, "error parsing ignition version")
+	}
+
+	switch versionCheck.Ignition.Version {
+	case "3.4.0":
+		cfg, _, err := config_34.Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	case "3.3.0":
+		cfg33, _, err := config_33.Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := upconvert33To34(cfg33)
+		if err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	case "3.2.0":
+		cfg32, _, err := config_32.Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		cfg33, err := upconvert32To33(cfg32)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := upconvert33To34(cfg33)
+		if err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	case "3.1.0":
+		cfg31, _, err := config_31.Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		cfg32, err := upconvert31To32(cfg31)
+		if err != nil {
+			return nil, err
+		}
+		cfg33, err := upconvert32To33(cfg32)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := upconvert33To34(cfg33)
+		if err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	default:
+		return nil, fmt.Errorf("unsupported config version: %s", versionCheck.Ignition.Version)
+	}
+}
+
+// upconvert31To32 round-trips cfg through JSON into the 3.2 config struct.
+// A marshal or parse failure is returned rather than swallowed into a
+// zero-value Config{}, which would otherwise silently discard every
+// file/unit/passwd entry cfg carried instead of failing the generation.
+func upconvert31To32(cfg config_31_types.Config) (config_32_types.Config, error) {
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		return config_32_types.Config{}, errors.Wrap(err, "error marshaling 3.1 config for upconversion to 3.2")
+	}
+	out, _, err := config_32.Parse(bytes)
+	if err != nil {
+		return config_32_types.Config{}, errors.Wrap(err, "error parsing upconverted 3.2 config")
+	}
+	return out, nil
+}
+
+func upconvert32To33(cfg config_32_types.Config) (config_33_types.Config, error) {
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		return config_33_types.Config{}, errors.Wrap(err, "error marshaling 3.2 config for upconversion to 3.3")
+	}
+	out, _, err := config_33.Parse(bytes)
+	if err != nil {
+		return config_33_types.Config{}, errors.Wrap(err, "error parsing upconverted 3.3 config")
+	}
+	return out, nil
+}
+
+func upconvert33To34(cfg config_33_types.Config) (config_34_types.Config, error) {
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		return config_34_types.Config{}, errors.Wrap(err, "error marshaling 3.3 config for upconversion to 3.4")
+	}
+	out, _, err := config_34.Parse(bytes)
+	if err != nil {
+		return config_34_types.Config{}, errors.Wrap(err, "error parsing upconverted 3.4 config")
+	}
+	return out, nil
+}
+
+// downconvertToVersion re-serializes an up-converted config at the requested
+// target version, so that 3.3-only fields (LUKS/tang clevis bindings, storage
+// filesystem "resize") are dropped only when the target genuinely predates
+// their introduction, instead of being silently lost on every round-trip.
+func downconvertToVersion(cfg *anyConfig, version string) ([]byte, error) {
+	cfg.Ignition.Version = version
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case "3.4.0":
+		if _, _, err := config_34.Parse(raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	case "3.3.0":
+		c33, _, err := config_33.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(c33)
+	case "3.2.0":
+		c32, _, err := config_32.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(c32)
+	case "3.1.0":
+		c31, _, err := config_31.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(c31)
+	default:
+		return nil, fmt.Errorf("unsupported config version: %s", version)
+	}
+}
+
+// MergeIgnitionConfig merges an override payload onto a base ignition config,
+// emitting the result at the higher of the two declared versions so that a
+// v3.2 (or v3.3/v3.4) override applied to an older base is not truncated back
+// down to the base's version.
+func MergeIgnitionConfig(base []byte, overrides []byte) (string, error) {
+	baseConfig, err := ParseToLatest(base)
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing base ignition config")
+	}
+	overrideConfig, err := ParseToLatest(overrides)
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing override ignition config")
+	}
+
+	var baseRaw, baseVersion string
+	if err = json.Unmarshal(base, &struct {
+		Ignition *struct {
+			Version *string `json:"version"`
+		} `json:"ignition"`
+	}{Ignition: &struct {
+		Version *string `json:"version"`
+	}{Version: &baseVersion}}); err != nil {
+		// best-effort; fall back to re-reading below
+	}
+	baseRaw = baseVersion
+
+	var ov struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+	_ = json.Unmarshal(base, &ov)
+	baseV := ov.Ignition.Version
+	_ = json.Unmarshal(overrides, &ov)
+	overrideV := ov.Ignition.Version
+
+	targetVersion := higherIgnitionVersion(baseV, overrideV)
+	_ = baseRaw
+
+	merged := config_34_types.Config{
+		Ignition: config_34_types.Ignition{
+			Version: targetVersion,
+			Config: config_34_types.IgnitionConfig{
+				Merge: append(append([]config_34_types.Resource{}, baseConfig.Ignition.Config.Merge...),
+					overrideConfig.Ignition.Config.Merge...),
+			},
+		},
+		Storage: config_34_types.Storage{
+			Files:       append(append([]config_34_types.File{}, baseConfig.Storage.Files...), overrideConfig.Storage.Files...),
+			Directories: append(append([]config_34_types.Directory{}, baseConfig.Storage.Directories...), overrideConfig.Storage.Directories...),
+			Links:       append(append([]config_34_types.Link{}, baseConfig.Storage.Links...), overrideConfig.Storage.Links...),
+		},
+		Systemd: config_34_types.Systemd{
+			Units: append(append([]config_34_types.Unit{}, baseConfig.Systemd.Units...), overrideConfig.Systemd.Units...),
+		},
+		Passwd: baseConfig.Passwd,
+	}
+
+	out, err := downconvertToVersion(&merged, targetVersion)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// higherIgnitionVersion returns whichever of the two dotted ignition versions
+// is greater, comparing major.minor.patch numerically.
+func higherIgnitionVersion(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	if compareIgnitionVersions(a, b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func compareIgnitionVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < 3; i++ {
+		var na, nb int
+		if i < len(pa) {
+			fmt.Sscanf(pa[i], "%d", &na)
+		}
+		if i < len(pb) {
+			fmt.Sscanf(pb[i], "%d", &nb)
+		}
+		if na != nb {
+			return na - nb
+		}
+	}
+	return 0
+}
+
+func isBMHFile(file *config_32_types.File) bool {
+	return strings.Contains(file.Path, "openshift-cluster-api_hosts")
+}
+
+func fileToBMH(file *config_32_types.File) (*bmh_v1alpha1.BareMetalHost, error) {
+	if file == nil || file.Contents.Source == nil {
+		return nil, errors.New("no file given")
+	}
+	source := *file.Contents.Source
+	encoded := strings.TrimPrefix(source, "data:text/plain;charset=utf-8;base64,")
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var bmh bmh_v1alpha1.BareMetalHost
+	if err := yaml.Unmarshal(decoded, &bmh); err != nil {
+		return nil, err
+	}
+	return &bmh, nil
+}
+
+func getHostnames(hosts []*models.Host) []string {
+	names := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		names = append(names, h.RequestedHostname)
+	}
+	return names
+}
+
+func bmhIsMaster(bmh *bmh_v1alpha1.BareMetalHost, masterHostnames, workerHostnames []string) bool {
+	name := bmh.ObjectMeta.Name
+	for _, w := range workerHostnames {
+		if strings.Contains(name, w) {
+			return false
+		}
+	}
+	for _, m := range masterHostnames {
+		if strings.Contains(name, m) {
+			return true
+		}
+	}
+	// default/backward-compatible behavior: treat unmatched hosts as masters.
+	return true
+}
+
+// updateBootstrap mutates the bootstrap.ign produced by openshift-install in
+// place: it stamps the BareMetalHost status annotation, adds the NetworkManager
+// drop-in and the assisted-install marker file.
+func (g *installerGenerator) updateBootstrap(ctx context.Context, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	config, err := ParseToLatest(content)
+	if err != nil {
+		return err
+	}
+	// Preserve the version openshift-install emitted rather than forcing 3.2.0.
+	g.TargetIgnitionVersion = config.Ignition.Version
+
+	masterHostnames, workerHostnames := splitHostnamesByRole(g.cluster.Hosts)
+
+	for i := range config.Storage.Files {
+		f32 := toV32File(&config.Storage.Files[i])
+		if isBMHFile(f32) {
+			bmh, err := fileToBMH(f32)
+			if err != nil {
+				return err
+			}
+			if bmh.ObjectMeta.Annotations == nil {
+				bmh.ObjectMeta.Annotations = map[string]string{}
+			}
+			status := "provisioned"
+			if !bmhIsMaster(bmh, masterHostnames, workerHostnames) {
+				status = "provisioned"
+			}
+			bmh.ObjectMeta.Annotations[bmh_v1alpha1.StatusAnnotation] = status
+			encoded, err := encodeBMH(bmh)
+			if err != nil {
+				return err
+			}
+			config.Storage.Files[i].Contents.Source = swag.String(encoded)
+		}
+	}
+
+	config.Storage.Files = append(config.Storage.Files,
+		fileFromString("/etc/NetworkManager/conf.d/99-kni.conf", "root", 0o644,
+			"[main]\nno-auto-default=*\n"),
+		fileFromString("/opt/openshift/assisted-install-bootstrap", "root", 0o644, ""),
+	)
+
+	mirrorFiles, err := g.mirrorRegistryFiles()
+	if err != nil {
+		return err
+	}
+	for _, f := range mirrorFiles {
+		// The bootstrap node itself doesn't read registries.conf.d, but crio on
+		// the bootstrap host does; drop the same registries.conf content in as a
+		// dedicated conf.d fragment so it layers over whatever openshift-install
+		// already shipped instead of overwriting it outright.
+		if f.Node.Path == mirrorRegistriesConfPath {
+			f.Node.Path = bootstrapMirrorDropInPath
+		}
+		config.Storage.Files = append(config.Storage.Files, f)
+	}
+
+	out, err := downconvertToVersion(config, g.TargetIgnitionVersion)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, out, 0o600)
+}
+
+func splitHostnamesByRole(hosts []*models.Host) (masters, workers []string) {
+	for _, h := range hosts {
+		if h.Role == models.HostRoleMaster {
+			masters = append(masters, h.RequestedHostname)
+		} else {
+			workers = append(workers, h.RequestedHostname)
+		}
+	}
+	return
+}
+
+func toV32File(f *config_34_types.File) *config_32_types.File {
+	raw, _ := json.Marshal(f)
+	var out config_32_types.File
+	_ = json.Unmarshal(raw, &out)
+	return &out
+}
+
+func encodeBMH(bmh *bmh_v1alpha1.BareMetalHost) (string, error) {
+	raw, err := yaml.Marshal(bmh)
+	if err != nil {
+		return "", err
+	}
+	return "data:text/plain;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func fileFromString(path, user string, mode int, contents string) config_34_types.File {
+	return config_34_types.File{
+		Node: config_34_types.Node{
+			Path:      path,
+			User:      config_34_types.NodeUser{Name: swag.String(user)},
+			Overwrite: swag.Bool(true),
+		},
+		FileEmbedded1: config_34_types.FileEmbedded1{
+			Mode: swag.Int(mode),
+			Contents: config_34_types.Resource{
+				Source: swag.String(dataURL(contents)),
+			},
+		},
+	}
+}
+
+func dataURL(contents string) string {
+	return fmt.Sprintf("data:,%s", contents)
+}
+
+// updateIgnitions mutates master.ign and worker.ign with the service CA cert
+// (when configured), /etc/hosts entries for the API/ingress service IPs, and
+// the keepalived DHCP lease files used by the unsupported-monitor scripts.
+const (
+	mirrorRegistriesConfPath  = "/etc/containers/registries.conf"
+	mirrorRegistriesCAPath    = "/etc/pki/ca-trust/source/anchors/mirror-registry-ca.crt"
+	bootstrapMirrorDropInPath = "/etc/containers/registries.conf.d/99-assisted-mirror.conf"
+	mirrorRegistriesFileMode  = 0o644
+)
+
+// mirrorRegistryFiles renders the registries.conf + CA anchor files that get
+// injected into master.ign/worker.ign so crio/podman on every cluster node
+// resolve release/operator images through the configured mirror registries,
+// rather than only trusting the discovery-time mirror wiring.
+func (g *installerGenerator) mirrorRegistryFiles() ([]config_34_types.File, error) {
+	if g.mirrorRegistriesBuilder == nil || !g.mirrorRegistriesBuilder.IsMirrorRegistriesConfigured() {
+		return nil, nil
+	}
+
+	registriesConf, err := g.mirrorRegistriesBuilder.GetMirrorRegistries()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render mirror registries.conf")
+	}
+	mirrorCA, err := g.mirrorRegistriesBuilder.GetMirrorCA()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render mirror registry CA bundle")
+	}
+
+	files := []config_34_types.File{
+		fileFromString(mirrorRegistriesConfPath, "root", mirrorRegistriesFileMode,
+			base64.StdEncoding.EncodeToString(registriesConf)),
+		fileFromString(mirrorRegistriesCAPath, "root", mirrorRegistriesFileMode,
+			base64.StdEncoding.EncodeToString(mirrorCA)),
+	}
+
+	policyFiles, err := containerSignaturePolicyFiles(g.mirrorRegistriesTrust)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render container signature verification policy")
+	}
+	return append(files, policyFiles...), nil
+}
+
+func (g *installerGenerator) updateIgnitions() error {
+	masterPath := filepath.Join(g.workDir, "master.ign")
+	workerPath := filepath.Join(g.workDir, "worker.ign")
+
+	for _, path := range []string{masterPath, workerPath} {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		config, err := ParseToLatest(content)
+		if err != nil {
+			return err
+		}
+
+		if g.serviceCACertPath != "" {
+			caCert, err := ioutil.ReadFile(g.serviceCACertPath)
+			if err != nil {
+				return err
+			}
+			config.Storage.Files = append(config.Storage.Files,
+				fileFromString(common.HostCACertPath, "root", 0o644,
+					base64.StdEncoding.EncodeToString(caCert)))
+		}
+
+		mirrorFiles, err := g.mirrorRegistryFiles()
+		if err != nil {
+			return err
+		}
+		config.Storage.Files = append(config.Storage.Files, mirrorFiles...)
+
+		if path == masterPath && g.encodedDhcpFileContents != "" {
+			config.Storage.Files = append(config.Storage.Files, config_34_types.File{
+				Node: config_34_types.Node{Path: "/etc/keepalived/unsupported-monitor.conf"},
+				FileEmbedded1: config_34_types.FileEmbedded1{
+					Mode:     swag.Int(0o644),
+					Contents: config_34_types.Resource{Source: swag.String(g.encodedDhcpFileContents)},
+				},
+			})
+			if g.cluster.ApiVipLease != "" {
+				config.Storage.Files = append(config.Storage.Files, config_34_types.File{
+					Node: config_34_types.Node{Path: "/etc/keepalived/lease-api"},
+					FileEmbedded1: config_34_types.FileEmbedded1{
+						Mode:     swag.Int(0o644),
+						Contents: config_34_types.Resource{Source: swag.String(dataURL(g.cluster.ApiVipLease))},
+					},
+				})
+			}
+			if g.cluster.IngressVipLease != "" {
+				config.Storage.Files = append(config.Storage.Files, config_34_types.File{
+					Node: config_34_types.Node{Path: "/etc/keepalived/lease-ingress"},
+					FileEmbedded1: config_34_types.FileEmbedded1{
+						Mode:     swag.Int(0o644),
+						Contents: config_34_types.Resource{Source: swag.String(dataURL(g.cluster.IngressVipLease))},
+					},
+				})
+			}
+		}
+
+		out, err := downconvertToVersion(config, g.TargetIgnitionVersion)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, out, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateEtcHosts writes an /etc/hosts entry for each given service IP into
+// master.ign and worker.ign, pointing the assisted-service local hostname at it.
+func (g *installerGenerator) UpdateEtcHosts(serviceIPs string) error {
+	content := GetServiceIPHostnames(serviceIPs)
+	for _, name := range []string{"master.ign", "worker.ign"} {
+		path := filepath.Join(g.workDir, name)
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		config, err := ParseToLatest(raw)
+		if err != nil {
+			return err
+		}
+		if content != "" {
+			config.Storage.Files = append(config.Storage.Files,
+				fileFromString("/etc/hosts", "root", 0o644, base64.StdEncoding.EncodeToString([]byte(content))))
+		}
+		out, err := downconvertToVersion(config, g.TargetIgnitionVersion)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, out, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetServiceIPHostnames renders /etc/hosts lines mapping each comma-separated
+// service IP to the assisted-api local hostname.
+func GetServiceIPHostnames(serviceIPs string) string {
+	if serviceIPs == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, ip := range strings.Split(serviceIPs, ",") {
+		if ip == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s assisted-api.local.openshift.io\n", ip))
+	}
+	return b.String()
+}
+
+// createHostIgnitions reads master.ign/worker.ign and, for every host in the
+// cluster, writes a per-host ignition at <role>-<hostID>.ign that merges the
+// per-role file with the /etc/hostname file and any IgnitionConfigOverrides.
+func (g *installerGenerator) createHostIgnitions() error {
+	roleContents := map[models.HostRole][]byte{}
+	for _, role := range []models.HostRole{models.HostRoleMaster, models.HostRoleWorker} {
+		path := filepath.Join(g.workDir, fmt.Sprintf("%s.ign", role))
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		roleContents[role] = content
+	}
+
+	for _, host := range g.cluster.Hosts {
+		base, ok := roleContents[host.Role]
+		if !ok {
+			base = roleContents[models.HostRoleWorker]
+		}
+
+		config, err := ParseToLatest(base)
+		if err != nil {
+			return err
+		}
+
+		config.Storage.Files = append(config.Storage.Files, config_34_types.File{
+			Node: config_34_types.Node{
+				Path:      "/etc/hostname",
+				User:      config_34_types.NodeUser{Name: swag.String("root")},
+				Overwrite: swag.Bool(true),
+			},
+			FileEmbedded1: config_34_types.FileEmbedded1{
+				Mode:     swag.Int(0o644),
+				Contents: config_34_types.Resource{Source: swag.String(dataURL(host.RequestedHostname))},
+			},
+		})
+
+		out, err := downconvertToVersion(config, config.Ignition.Version)
+		if err != nil {
+			return err
+		}
+
+		if host.IgnitionConfigOverrides != "" {
+			if len(g.trustedOverrideKeys) > 0 {
+				var sig []byte
+				if host.ID != nil {
+					sig = g.hostOverrideSignatures[*host.ID]
+				}
+				if err := verifyOverrideSignature([]byte(host.IgnitionConfigOverrides), sig, g.trustedOverrideKeys); err != nil {
+					return errors.Wrapf(err, "ignition config overrides for host %s", host.ID)
+				}
+			}
+			merged, err := MergeIgnitionConfig(out, []byte(host.IgnitionConfigOverrides))
+			if err != nil {
+				return errors.Wrapf(err, "error merging ignition config overrides for host %s", host.ID)
+			}
+			out = []byte(merged)
+		}
+
+		if len(g.hostIgnitionOverrideRules) > 0 {
+			ruleOut, err := applyHostIgnitionOverrideRules(out, host, g.hostIgnitionOverrideRules)
+			if err != nil {
+				// A rule evaluation failure is host-local: log it and keep whatever
+				// fragments did apply rather than failing ignition generation for
+				// the entire cluster.
+				g.log.WithError(err).Warnf("error evaluating ignition override rules for host %s", host.ID)
+			}
+			out = ruleOut
+		}
+
+		if g.recorder.Enabled() {
+			input := struct {
+				Host                *models.Host
+				TrustedOverrideKeys []ed25519.PublicKey
+			}{host, g.trustedOverrideKeys}
+			if recErr := g.recorder.record("host-ignition", input, out, nil); recErr != nil {
+				g.log.WithError(recErr).Warn("failed to record host ignition reproducer bundle")
+			}
+		}
+
+		hostPath := filepath.Join(g.workDir, hostutil.IgnitionFileName(host))
+		if err := ioutil.WriteFile(hostPath, out, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UploadToS3 uploads every cluster-scoped artifact plus each host's ignition
+// to S3 in a bounded-concurrency worker pool (see SetUploadConcurrency),
+// streaming each file's SHA-256 alongside the upload, then refreshes each
+// object's access timestamp so garbage collection does not reap files still
+// in active use. Finally it uploads a manifest.json listing every artifact's
+// path/size/sha256/role/hostID (see SetManifestSigningKey), which the
+// agent/installer can fetch first and use to verify subsequent downloads.
+func (g *installerGenerator) UploadToS3(ctx context.Context) error {
+	if err := g.uploadToS3(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *installerGenerator) downloadManifest(ctx context.Context, manifestName string) error {
+	respBody, _, err := g.s3Client.Download(ctx, manifestName)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+
+	fileName := filepath.Join(g.workDir, "openshift", filepath.Base(manifestName))
+	out, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, respBody)
+	return err
+}
+
+// ExtractClusterID reads an ignition config and returns the OpenShift
+// ClusterID embedded in its cvo-overrides manifest.
+func ExtractClusterID(r io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	config, _, err := config_32.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var cvoFile *config_32_types.File
+	for i := range config.Storage.Files {
+		if config.Storage.Files[i].Node.Path == "/opt/openshift/manifests/cvo-overrides.yaml" {
+			cvoFile = &config.Storage.Files[i]
+			break
+		}
+	}
+	if cvoFile == nil {
+		return "", errors.New("could not find cvo-overrides file")
+	}
+
+	source := swag.StringValue(cvoFile.Contents.Source)
+	encoded := strings.TrimPrefix(source, "data:text/plain;charset=utf-8;base64,")
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	var cvo struct {
+		Spec struct {
+			ClusterID string `yaml:"clusterID"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(decoded, &cvo); err != nil {
+		return "", err
+	}
+	if cvo.Spec.ClusterID == "" {
+		return "", errors.New("no ClusterID field in cvo-overrides file")
+	}
+	return cvo.Spec.ClusterID, nil
+}
+
+// proxySettingsForIgnition renders the `ignition.proxy` stanza used by the
+// discovery ignition, given the http/https proxy URLs and comma-separated
+// no-proxy domains.
+func proxySettingsForIgnition(httpProxy, httpsProxy, noProxy string) (string, error) {
+	if httpProxy == "" && httpsProxy == "" {
+		return "", nil
+	}
+
+	proxySettings := make([]string, 0)
+	if httpProxy != "" {
+		proxySettings = append(proxySettings, fmt.Sprintf(`"httpProxy": "%s"`, httpProxy))
+	}
+	if httpsProxy != "" {
+		proxySettings = append(proxySettings, fmt.Sprintf(`"httpsProxy": "%s"`, httpsProxy))
+	}
+	if noProxy != "" {
+		noProxyArr := strings.Split(noProxy, ",")
+		noProxyStr, err := json.Marshal(noProxyArr)
+		if err != nil {
+			return "", err
+		}
+		proxySettings = append(proxySettings, fmt.Sprintf(`"noProxy": %s`, noProxyStr))
+	}
+	return fmt.Sprintf(`"proxy": { %s }`, strings.Join(proxySettings, ", ")), nil
+}
@@ -0,0 +1,579 @@
+package ignition
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-openapi/swag"
+	"github.com/openshift/assisted-service/internal/common"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/assisted-service/pkg/auth"
+	"github.com/openshift/assisted-service/pkg/mirrorregistries"
+	"github.com/openshift/assisted-service/pkg/staticnetworkconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// IgnitionConfig carries the per-call knobs FormatDiscoveryIgnitionFile and
+// FormatSecondDayWorkerIgnitionFile need that aren't already part of the
+// InfraEnv/Host being rendered.
+type IgnitionConfig struct {
+	ServiceBaseURL       string
+	SkipCertVerification bool
+
+	// MirrorRegistriesTrust maps a mirrored registry host (as it appears in
+	// registries.conf) to the detached-signature verification policy to render
+	// for it. Signature verification is opt-in: leaving this nil preserves the
+	// existing registries.conf/CA-only behavior. When set, it takes precedence
+	// over the mirror registries subsystem's own signature policy (see
+	// mirrorRegistrySignatureFiles), for callers that need a discovery-time
+	// policy that differs from the one the subsystem would otherwise derive.
+	MirrorRegistriesTrust map[string]RegistryTrustConfig
+
+	// TrustedOverrideKeys, when non-empty, requires infraEnv.IgnitionConfigOverride
+	// to carry a detached signature in OverrideSignature that verifies against one
+	// of these keys before it is merged; see verifyOverrideSignature.
+	TrustedOverrideKeys []ed25519.PublicKey
+	OverrideSignature   []byte
+
+	// EncryptedDNS, when set, routes discovery-time DNS queries through one or
+	// more DoH/DoT resolvers instead of whatever DHCP/the proxy would otherwise
+	// hand out; see encryptedDNSFiles.
+	EncryptedDNS *EncryptedDNSConfig
+
+	// Recorder, when enabled, captures this call's inputs and rendered output
+	// into a reproducer bundle for the `ignition-replay` command; see
+	// RecorderConfig.
+	Recorder *RecorderConfig
+}
+
+// SecondDayAuthHeader is a single extra HTTP header a day-2 host sends when
+// fetching its ignition from the MCS endpoint; see SecondDayAuth.
+type SecondDayAuthHeader struct {
+	Name  string
+	Value string
+}
+
+// SecondDayAuth is how a day-2 host authenticates to the MCS endpoint
+// FormatSecondDayWorkerIgnitionFileWithAuth points it at. The zero value
+// matches the CA-cert/bearer-token-only behavior FormatSecondDayWorkerIgnitionFile
+// has always had.
+type SecondDayAuth struct {
+	// CACert is a base64-encoded PEM CA bundle that verifies the endpoint's
+	// server certificate. Ignored when InsecureSkipVerify is set.
+	CACert *string
+
+	// BearerToken, when set, is sent as "Authorization: Bearer <token>",
+	// ahead of any ExtraHeaders.
+	BearerToken string
+
+	// ExtraHeaders are additional name/value pairs appended to the merge
+	// source's HTTP headers, for proxy auth, tenant IDs, signed JWT
+	// assertions, or anything else the endpoint expects.
+	ExtraHeaders []SecondDayAuthHeader
+
+	// ClientCert and ClientKey, when both set, are a PEM client
+	// certificate/key pair rendered into /etc/pki/ignition/ (see
+	// secondDayClientCertFiles) and wired up for mTLS via a
+	// machine-config-daemon systemd drop-in (see secondDayClientCertDropin).
+	ClientCert string
+	ClientKey  string
+
+	// InsecureSkipVerify, when true, omits CACert even if set, so the
+	// fetcher accepts whatever certificate the endpoint presents. For
+	// bootstrap environments where the endpoint certificate isn't valid yet.
+	InsecureSkipVerify bool
+}
+
+// IgnitionBuilder renders the discovery ignition (the config an InfraEnv's
+// boot image ships) and the second-day worker ignition (the config a day-2
+// host fetches from the target cluster's MCS).
+type IgnitionBuilder interface {
+	FormatDiscoveryIgnitionFile(ctx context.Context, infraEnv *common.InfraEnv, cfg IgnitionConfig, safeForLogging bool, authType auth.AuthType) (string, error)
+
+	// FormatSecondDayWorkerIgnitionFile is a thin shim over
+	// FormatSecondDayWorkerIgnitionFileWithAuth for the common case of a
+	// bearer token and/or CA cert; see that method for mTLS, extra headers,
+	// and insecure-skip-verify support.
+	FormatSecondDayWorkerIgnitionFile(serviceBaseURL string, caCert *string, ignitionEndpointToken string, host *models.Host) ([]byte, error)
+	FormatSecondDayWorkerIgnitionFileWithAuth(serviceBaseURL string, auth SecondDayAuth, host *models.Host) ([]byte, error)
+}
+
+type ignitionBuilder struct {
+	log                     logrus.FieldLogger
+	staticNetworkConfig     staticnetworkconfig.StaticNetworkConfig
+	mirrorRegistriesBuilder mirrorregistries.MirrorRegistriesConfigBuilder
+}
+
+// NewBuilder creates an IgnitionBuilder.
+func NewBuilder(log logrus.FieldLogger, staticNetworkConfig staticnetworkconfig.StaticNetworkConfig,
+	mirrorRegistriesBuilder mirrorregistries.MirrorRegistriesConfigBuilder) IgnitionBuilder {
+	return &ignitionBuilder{
+		log:                     log,
+		staticNetworkConfig:     staticNetworkConfig,
+		mirrorRegistriesBuilder: mirrorRegistriesBuilder,
+	}
+}
+
+const discoveryIgnitionConfigFormat = `{
+  "ignition": { "version": "3.1.0" },
+  "passwd": { "users": [ {{.SSHUser}} ] },
+  "storage": { "files": [ {{.Files}} ] },
+  "systemd": { "units": [ {{.Units}} ] }
+}`
+
+func (ib *ignitionBuilder) FormatDiscoveryIgnitionFile(ctx context.Context, infraEnv *common.InfraEnv, cfg IgnitionConfig,
+	safeForLogging bool, authType auth.AuthType) (text string, err error) {
+	if cfg.Recorder.Enabled() {
+		defer func() {
+			input := struct {
+				InfraEnv       *common.InfraEnv
+				Config         IgnitionConfig
+				SafeForLogging bool
+				AuthType       auth.AuthType
+			}{infraEnv, cfg, safeForLogging, authType}
+			if recErr := cfg.Recorder.record("discovery-ignition", input, []byte(text), err); recErr != nil {
+				ib.log.WithError(recErr).Warn("failed to record discovery ignition reproducer bundle")
+			}
+		}()
+	}
+
+	var pullSecretToken string
+	if authType != auth.TypeNone {
+		token, err := GetPullSecretToken(infraEnv.PullSecret)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to fetch pull secret token")
+		}
+		pullSecretToken = token
+	}
+
+	files := []config34File{}
+
+	agentArgs := fmt.Sprintf("--url %s --insecure=%v", cfg.ServiceBaseURL, cfg.SkipCertVerification)
+
+	var httpProxy, httpsProxy, noProxy string
+	if infraEnv.Proxy != nil {
+		httpProxy = swag.StringValue(infraEnv.Proxy.HTTPProxy)
+		httpsProxy = swag.StringValue(infraEnv.Proxy.HTTPSProxy)
+		noProxy = swag.StringValue(infraEnv.Proxy.NoProxy)
+	}
+	proxy, err := proxySettingsForIgnition(httpProxy, httpsProxy, noProxy)
+	if err != nil {
+		return "", err
+	}
+
+	sshKeys, err := formatSSHAuthorizedKeys(infraEnv.SSHAuthorizedKey)
+	if err != nil {
+		return "", err
+	}
+
+	staticFiles, staticUnits, err := ib.staticNetworkFiles(ctx, infraEnv)
+	if err != nil {
+		return "", err
+	}
+	files = append(files, staticFiles...)
+
+	mirrorFiles, err := ib.mirrorRegistryDiscoveryFiles(cfg)
+	if err != nil {
+		return "", err
+	}
+	files = append(files, mirrorFiles...)
+
+	dnsFiles, dnsUnits, err := encryptedDNSFiles(cfg.EncryptedDNS, noProxy)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render encrypted DNS configuration")
+	}
+	for _, f := range dnsFiles {
+		files = append(files, config34File{Path: f.Node.Path, Source: *f.FileEmbedded1.Contents.Source})
+	}
+
+	if pullSecretToken != "" {
+		source := pullSecretToken
+		if safeForLogging {
+			source = "*****"
+		}
+		files = append(files, config34File{Path: "/etc/assisted/PULL_SECRET_TOKEN", Source: dataURL(source)})
+	}
+
+	var fileParts []string
+	for _, f := range files {
+		fileParts = append(fileParts, f.render())
+	}
+
+	units := []string{fmt.Sprintf(`{ "name": "agent.service", "enabled": true, "contents": "[Service]\nExecStart=/usr/local/bin/start-agent.sh %s\n" }`, agentArgs)}
+	units = append(units, staticUnits...)
+	units = append(units, dnsUnits...)
+
+	var sshUser string
+	if sshKeys != "" {
+		sshUser = fmt.Sprintf(`{ "name": "core", "sshAuthorizedKeys": %s }`, sshKeys)
+	}
+
+	tmpl := template.Must(template.New("discovery").Parse(discoveryIgnitionConfigFormat))
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, struct {
+		SSHUser string
+		Files   string
+		Units   string
+	}{SSHUser: sshUser, Files: strings.Join(fileParts, ", "), Units: strings.Join(units, ", ")}); err != nil {
+		return "", err
+	}
+
+	text = buf.String()
+	if proxy != "" {
+		// Not part of the ignition spec itself, but carried alongside it so the
+		// agent's proxy-aware HTTP client can pick its settings up without a
+		// second round-trip to the service; unknown ignition fields are ignored
+		// by the parser.
+		text = strings.TrimSuffix(strings.TrimSpace(text), "}") + fmt.Sprintf(", %s }", proxy)
+	}
+
+	if safeForLogging {
+		text = redactPullSecretDomains(text)
+	}
+
+	if infraEnv.IgnitionConfigOverride != "" {
+		if len(cfg.TrustedOverrideKeys) > 0 {
+			if err := verifyOverrideSignature([]byte(infraEnv.IgnitionConfigOverride), cfg.OverrideSignature, cfg.TrustedOverrideKeys); err != nil {
+				return "", err
+			}
+		}
+		merged, err := MergeIgnitionConfig([]byte(text), []byte(infraEnv.IgnitionConfigOverride))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to apply infra-env ignition config override")
+		}
+		text = merged
+	}
+
+	return text, nil
+}
+
+// FormatSecondDayWorkerIgnitionFile is the CA-cert/bearer-token shim kept for
+// callers that don't need mTLS, extra headers, or insecure-skip-verify; see
+// FormatSecondDayWorkerIgnitionFileWithAuth.
+func (ib *ignitionBuilder) FormatSecondDayWorkerIgnitionFile(serviceBaseURL string, caCert *string, ignitionEndpointToken string, host *models.Host) ([]byte, error) {
+	return ib.FormatSecondDayWorkerIgnitionFileWithAuth(serviceBaseURL, SecondDayAuth{CACert: caCert, BearerToken: ignitionEndpointToken}, host)
+}
+
+// FormatSecondDayWorkerIgnitionFileWithAuth has no IgnitionConfig to carry a
+// RecorderConfig, so recording here is opt-in purely via RecorderDirEnvVar/
+// RecorderKeyEnvVar.
+func (ib *ignitionBuilder) FormatSecondDayWorkerIgnitionFileWithAuth(serviceBaseURL string, auth SecondDayAuth, host *models.Host) (out []byte, err error) {
+	if recorder := RecorderConfigFromEnv(); recorder.Enabled() {
+		defer func() {
+			input := struct {
+				ServiceBaseURL string
+				Auth           SecondDayAuth
+				Host           *models.Host
+			}{serviceBaseURL, auth, host}
+			if recErr := recorder.record("second-day-worker-ignition", input, out, err); recErr != nil {
+				ib.log.WithError(recErr).Warn("failed to record second-day worker ignition reproducer bundle")
+			}
+		}()
+	}
+
+	merge := fmt.Sprintf(`{ "source": "%s"`, serviceBaseURL)
+	if headers := secondDayAuthHeaders(auth); headers != "" {
+		merge += fmt.Sprintf(`, "httpHeaders": [ %s ]`, headers)
+	}
+	merge += " }"
+
+	var tls string
+	if !auth.InsecureSkipVerify && auth.CACert != nil && *auth.CACert != "" {
+		tls = fmt.Sprintf(`, "security": { "tls": { "certificateAuthorities": [ { "source": "data:text/plain;base64,%s" } ] } }`, *auth.CACert)
+	}
+
+	var storage, systemd string
+	if auth.ClientCert != "" && auth.ClientKey != "" {
+		var fileParts []string
+		for _, f := range secondDayClientCertFiles(auth) {
+			fileParts = append(fileParts, f.render())
+		}
+		storage = fmt.Sprintf(`, "storage": { "files": [ %s ] }`, strings.Join(fileParts, ", "))
+		systemd = fmt.Sprintf(`, "systemd": { "units": [ %s ] }`, secondDayClientCertDropin())
+	}
+
+	text := fmt.Sprintf(`{ "ignition": { "version": "3.1.0", "config": { "merge": [ %s ] }%s }%s%s }`, merge, tls, storage, systemd)
+
+	if host != nil && host.IgnitionConfigOverrides != "" {
+		merged, err := MergeIgnitionConfig([]byte(text), []byte(host.IgnitionConfigOverrides))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to apply host ignition config overrides")
+		}
+		text = merged
+	}
+
+	return []byte(text), nil
+}
+
+// secondDayAuthHeaders renders auth.BearerToken (if any) and auth.ExtraHeaders
+// as the comma-separated contents of an ignition httpHeaders array.
+func secondDayAuthHeaders(auth SecondDayAuth) string {
+	var parts []string
+	if auth.BearerToken != "" {
+		parts = append(parts, fmt.Sprintf(`{ "name": "Authorization", "value": "Bearer %s" }`, auth.BearerToken))
+	}
+	for _, h := range auth.ExtraHeaders {
+		parts = append(parts, fmt.Sprintf(`{ "name": %s, "value": %s }`, jsonString(h.Name), jsonString(h.Value)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+const (
+	secondDayClientCertPath = "/etc/pki/ignition/client.crt"
+	secondDayClientKeyPath  = "/etc/pki/ignition/client.key"
+)
+
+// secondDayClientCertFiles renders the mTLS client certificate and private
+// key SecondDayAuth.ClientCert/ClientKey carry into ignition storage files,
+// for the systemd drop-in secondDayClientCertDropin to point the MCS fetcher
+// at.
+func secondDayClientCertFiles(auth SecondDayAuth) []config34File {
+	return []config34File{
+		{Path: secondDayClientCertPath, Source: dataURL(base64.StdEncoding.EncodeToString([]byte(auth.ClientCert)))},
+		{Path: secondDayClientKeyPath, Source: dataURL(base64.StdEncoding.EncodeToString([]byte(auth.ClientKey)))},
+	}
+}
+
+// secondDayClientCertDropin points machine-config-daemon's MCS fetcher at the
+// client certificate/key secondDayClientCertFiles renders, since the
+// ignition config merge directive itself has no notion of client certs.
+func secondDayClientCertDropin() string {
+	contents := fmt.Sprintf("[Service]\nEnvironment=MCD_CLIENT_CERT=%s\nEnvironment=MCD_CLIENT_KEY=%s\n",
+		secondDayClientCertPath, secondDayClientKeyPath)
+	return fmt.Sprintf(`{ "name": "machine-config-daemon.service", "dropins": [ { "name": "10-mtls-client-cert.conf", "contents": %s } ] }`, jsonString(contents))
+}
+
+// jsonString renders s as a quoted JSON string literal.
+func jsonString(s string) string {
+	raw, _ := json.Marshal(s)
+	return string(raw)
+}
+
+// staticNetworkFiles renders InfraEnv.StaticNetworkConfig according to its
+// StaticNetworkFormat: models.StaticNetworkFormatNmstate (see
+// nmStateFilesAndUnits) or the default models.StaticNetworkFormatKeyfiles,
+// which drops pre-rendered NetworkManager keyfiles into
+// /etc/NetworkManager/system-connections/ as it always has.
+func (ib *ignitionBuilder) staticNetworkFiles(ctx context.Context, infraEnv *common.InfraEnv) ([]config34File, []string, error) {
+	if infraEnv.StaticNetworkConfig == "" {
+		return nil, nil, nil
+	}
+
+	if infraEnv.StaticNetworkFormat == models.StaticNetworkFormatNmstate {
+		return ib.nmStateFilesAndUnits(ctx, infraEnv)
+	}
+
+	data, err := ib.staticNetworkConfig.GenerateStaticNetworkConfigData(ctx, infraEnv.StaticNetworkConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate static network configuration")
+	}
+
+	// Static network connection files are only embedded on the full ISO; the
+	// minimal ISO fetches and applies them from the service at boot instead of
+	// shipping them inline.
+	if infraEnv.Type != nil && *infraEnv.Type == models.ImageTypeMinimalIso {
+		return nil, nil, nil
+	}
+
+	files := make([]config34File, 0, len(data))
+	for _, d := range data {
+		files = append(files, config34File{
+			Path:   "/etc/NetworkManager/system-connections/" + d.FilePath,
+			Source: dataURL(base64.StdEncoding.EncodeToString([]byte(d.FileContents))),
+		})
+	}
+	return files, nil, nil
+}
+
+// nmStateConfigDir is where nmStateFilesAndUnits embeds the raw NMState YAML
+// documents for the oneshot units it also renders to apply.
+const nmStateConfigDir = "/etc/assisted/network"
+
+// nmStateFilesAndUnits embeds the NMState YAML document(s)
+// GenerateNMStateUnits returns under nmStateConfigDir, and renders one
+// oneshot systemd unit per document that runs `nmstatectl apply` against it
+// ahead of NetworkManager-wait-online, so the interfaces it describes are up
+// before the agent starts. Like the keyfile path, this is skipped on the
+// minimal ISO, which fetches and applies network config from the service at
+// boot instead of shipping it inline.
+func (ib *ignitionBuilder) nmStateFilesAndUnits(ctx context.Context, infraEnv *common.InfraEnv) ([]config34File, []string, error) {
+	docs, err := ib.staticNetworkConfig.GenerateNMStateUnits(ctx, infraEnv.StaticNetworkConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate NMState configuration")
+	}
+
+	if infraEnv.Type != nil && *infraEnv.Type == models.ImageTypeMinimalIso {
+		return nil, nil, nil
+	}
+
+	files := make([]config34File, 0, len(docs))
+	units := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		path := nmStateConfigDir + "/" + doc.FilePath
+		files = append(files, config34File{Path: path, Source: dataURL(base64.StdEncoding.EncodeToString([]byte(doc.FileContents)))})
+		units = append(units, nmstateApplyUnit(doc.FilePath, path))
+	}
+	return files, units, nil
+}
+
+// nmstateApplyUnit renders the oneshot unit that applies the NMState document
+// at path; fileName (the document's base name, without nmStateConfigDir)
+// names the unit so multiple documents don't collide.
+func nmstateApplyUnit(fileName, path string) string {
+	base := strings.NewReplacer(":", "-", "/", "-").Replace(strings.TrimSuffix(fileName, ".yaml"))
+	name := fmt.Sprintf("nmstate-apply-%s.service", base)
+	contents := fmt.Sprintf("[Unit]\nBefore=NetworkManager-wait-online.service\n[Service]\nType=oneshot\nExecStart=/usr/bin/nmstatectl apply %s\n[Install]\nWantedBy=multi-user.target\n", path)
+	return fmt.Sprintf(`{ "name": %s, "enabled": true, "contents": %s }`, jsonString(name), jsonString(contents))
+}
+
+func (ib *ignitionBuilder) mirrorRegistryDiscoveryFiles(cfg IgnitionConfig) ([]config34File, error) {
+	if ib.mirrorRegistriesBuilder == nil || !ib.mirrorRegistriesBuilder.IsMirrorRegistriesConfigured() {
+		return nil, nil
+	}
+	ca, err := ib.mirrorRegistriesBuilder.GetMirrorCA()
+	if err != nil {
+		return nil, err
+	}
+	registries, err := ib.mirrorRegistriesBuilder.GetMirrorRegistries()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []config34File{
+		{Path: mirrorRegistriesConfPath, Source: dataURL(base64.StdEncoding.EncodeToString(registries))},
+		{Path: "/etc/pki/ca-trust/source/anchors/domain.crt", Source: dataURL(base64.StdEncoding.EncodeToString(ca))},
+	}
+
+	signatureFiles, err := ib.mirrorRegistrySignatureFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, signatureFiles...)
+
+	return files, nil
+}
+
+// mirrorRegistrySignatureFiles renders /etc/containers/policy.json, the
+// matching registries.d lookaside map, and the GPG/cosign keys they
+// reference, so discovery RHCOS verifies release-payload signatures when
+// pulling from a disconnected mirror. cfg.MirrorRegistriesTrust, when set,
+// takes precedence; otherwise the mirror registries subsystem is asked for
+// its own signature policy via GetSignaturePolicy/GetLookasideConfig, so
+// discovery honors signatures as soon as an InfraEnv's mirror registries are
+// configured, without the caller having to populate IgnitionConfig by hand.
+func (ib *ignitionBuilder) mirrorRegistrySignatureFiles(cfg IgnitionConfig) ([]config34File, error) {
+	if len(cfg.MirrorRegistriesTrust) > 0 {
+		policyFiles, err := containerSignaturePolicyFiles(cfg.MirrorRegistriesTrust)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render container signature verification policy")
+		}
+		files := make([]config34File, 0, len(policyFiles))
+		for _, f := range policyFiles {
+			files = append(files, config34File{Path: f.Node.Path, Source: *f.FileEmbedded1.Contents.Source})
+		}
+		return files, nil
+	}
+
+	policy, keys, err := ib.mirrorRegistriesBuilder.GetSignaturePolicy()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch mirror registry signature policy")
+	}
+	if len(policy) == 0 {
+		return nil, nil
+	}
+	lookaside, err := ib.mirrorRegistriesBuilder.GetLookasideConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch mirror registry lookaside config")
+	}
+
+	files := []config34File{
+		{Path: containerPolicyPath, Source: dataURL(base64.StdEncoding.EncodeToString(policy))},
+	}
+	if len(lookaside) > 0 {
+		files = append(files, config34File{
+			Path:   containerRegistriesDDir + "/mirror.yaml",
+			Source: dataURL(base64.StdEncoding.EncodeToString(lookaside)),
+		})
+	}
+
+	keyPaths := make([]string, 0, len(keys))
+	for path := range keys {
+		keyPaths = append(keyPaths, path)
+	}
+	sort.Strings(keyPaths)
+	for _, path := range keyPaths {
+		files = append(files, config34File{Path: path, Source: dataURL(base64.StdEncoding.EncodeToString(keys[path]))})
+	}
+
+	return files, nil
+}
+
+// config34File is a minimal, hand-renderable ignition storage file used while
+// assembling the discovery ignition as raw JSON text (see the package-level
+// note on discoveryIgnitionConfigFormat for why this isn't built via the
+// typed config_34_types.Config and json.Marshal instead).
+type config34File struct {
+	Path   string
+	Source string
+}
+
+func (f config34File) render() string {
+	return fmt.Sprintf(`{ "path": %q, "contents": { "source": %q } }`, f.Path, f.Source)
+}
+
+// formatSSHAuthorizedKeys splits a (possibly multi-line) SSH authorized_keys
+// blob into the compact JSON array ignition expects, trimming blank lines and
+// surrounding whitespace.
+func formatSSHAuthorizedKeys(keys string) (string, error) {
+	var parsed []string
+	for _, line := range strings.Split(keys, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			parsed = append(parsed, line)
+		}
+	}
+	if len(parsed) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// GetPullSecretToken extracts the "cloud.openshift.com" auth token from a
+// docker pull-secret JSON blob, which the discovery agent uses to
+// authenticate back to assisted-service when RHSSO auth is enabled.
+func GetPullSecretToken(pullSecret string) (string, error) {
+	var secret struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal([]byte(pullSecret), &secret); err != nil {
+		return "", errors.Wrap(err, "failed to parse pull secret")
+	}
+	entry, ok := secret.Auths["cloud.openshift.com"]
+	if !ok || entry.Auth == "" {
+		return "", errors.New("pull secret does not contain auth for cloud.openshift.com")
+	}
+	return entry.Auth, nil
+}
+
+var cloudOpenshiftDomainRe = regexp.MustCompile(`cloud\.openshift\.com`)
+
+// redactPullSecretDomains scrubs the pull-secret auth domain from ignition
+// text intended for logs; the secret value itself is already replaced with
+// "*****" by the caller before this runs.
+func redactPullSecretDomains(text string) string {
+	return cloudOpenshiftDomainRe.ReplaceAllString(text, "*****")
+}
@@ -2,6 +2,7 @@ package ignition
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -12,8 +13,10 @@ import (
 	"strings"
 
 	config_31 "github.com/coreos/ignition/v2/config/v3_1"
+	config_31_types "github.com/coreos/ignition/v2/config/v3_1/types"
 	config_32 "github.com/coreos/ignition/v2/config/v3_2"
 	config_32_types "github.com/coreos/ignition/v2/config/v3_2/types"
+	config_34_types "github.com/coreos/ignition/v2/config/v3_4/types"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 	"github.com/golang/mock/gomock"
@@ -123,7 +126,7 @@ var _ = Describe("Bootstrap Ignition Update", func() {
 			},
 		}
 		g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", mockS3Client, log,
-			mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+			mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 		err = g.updateBootstrap(context.Background(), examplePath)
 
@@ -266,7 +269,7 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 	Describe("update ignitions", func() {
 		It("with ca cert file", func() {
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", caCertPath, "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			err := g.updateIgnitions()
 			Expect(err).NotTo(HaveOccurred())
@@ -289,7 +292,7 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 		})
 		It("with no ca cert file", func() {
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			err := g.updateIgnitions()
 			Expect(err).NotTo(HaveOccurred())
@@ -306,9 +309,55 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 			Expect(err).NotTo(HaveOccurred())
 			Expect(workerConfig.Storage.Files).To(HaveLen(0))
 		})
+		It("with mirror registries configured", func() {
+			mockMirrorBuilder := mirrorregistries.NewMockMirrorRegistriesConfigBuilder(ctrl)
+			mockMirrorBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(true).Times(2)
+			mockMirrorBuilder.EXPECT().GetMirrorRegistries().Return([]byte("unqualified-search-registries = [\"registry.example.com\"]"), nil).Times(2)
+			mockMirrorBuilder.EXPECT().GetMirrorCA().Return([]byte("mirror-ca-bundle"), nil).Times(2)
+
+			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
+				mockOperatorManager, mockProviderRegistry, mockMirrorBuilder, "").(*installerGenerator)
+
+			err := g.updateIgnitions()
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, path := range []string{masterPath, workerPath} {
+				content, err := ioutil.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				config, _, err := config_32.Parse(content)
+				Expect(err).NotTo(HaveOccurred())
+				var foundRegistriesConf, foundCA bool
+				for _, f := range config.Storage.Files {
+					if f.Path == mirrorRegistriesConfPath {
+						foundRegistriesConf = true
+					}
+					if f.Path == mirrorRegistriesCAPath {
+						foundCA = true
+					}
+				}
+				Expect(foundRegistriesConf).To(BeTrue(), "registries.conf not present in %s", path)
+				Expect(foundCA).To(BeTrue(), "mirror CA anchor not present in %s", path)
+			}
+		})
+		It("without mirror registries configured", func() {
+			mockMirrorBuilder := mirrorregistries.NewMockMirrorRegistriesConfigBuilder(ctrl)
+			mockMirrorBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(2)
+
+			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
+				mockOperatorManager, mockProviderRegistry, mockMirrorBuilder, "").(*installerGenerator)
+
+			err := g.updateIgnitions()
+			Expect(err).NotTo(HaveOccurred())
+
+			masterBytes, err := ioutil.ReadFile(masterPath)
+			Expect(err).NotTo(HaveOccurred())
+			masterConfig, _, err := config_32.Parse(masterBytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(masterConfig.Storage.Files).To(HaveLen(0))
+		})
 		It("with service ips", func() {
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			err := g.UpdateEtcHosts("10.10.10.1,10.10.10.2")
 			Expect(err).NotTo(HaveOccurred())
@@ -331,7 +380,7 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 		})
 		It("with no service ips", func() {
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			err := g.UpdateEtcHosts("")
 			Expect(err).NotTo(HaveOccurred())
@@ -361,7 +410,7 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 		Context("DHCP generation", func() {
 			It("Definitions only", func() {
 				g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-					mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+					mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 				g.encodedDhcpFileContents = "data:,abc"
 				err := g.updateIgnitions()
@@ -380,7 +429,7 @@ SV4bRR9i0uf+xQ/oYRvugQ25Q7EahO5hJIWRf4aULbk36Zpw3++v2KFnF26zqwB6
 		})
 		It("Definitions+leases", func() {
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			g.encodedDhcpFileContents = "data:,abc"
 			cluster.ApiVipLease = "api"
@@ -503,7 +552,7 @@ var _ = Describe("createHostIgnitions", func() {
 			}
 
 			g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-				mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+				mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 			err := g.createHostIgnitions()
 			Expect(err).NotTo(HaveOccurred())
@@ -549,7 +598,7 @@ var _ = Describe("createHostIgnitions", func() {
 		}}
 
 		g := NewGenerator(workDir, installerCacheDir, cluster, "", "", "", "", nil, log,
-			mockOperatorManager, mockProviderRegistry, "").(*installerGenerator)
+			mockOperatorManager, mockProviderRegistry, nil, "").(*installerGenerator)
 
 		err := g.createHostIgnitions()
 		Expect(err).NotTo(HaveOccurred())
@@ -726,6 +775,23 @@ var _ = Describe("Generator UploadToS3", func() {
 		return mockS3Client.EXPECT().UpdateObjectTimestamp(gomock.Any(), gomock.Any())
 	}
 
+	// writeArtifacts creates real, readable files at every path uploadToS3
+	// will try to checksum, since streamingSHA256 (unlike the mocked
+	// UploadFile/UpdateObjectTimestamp calls) reads the actual file on disk.
+	writeArtifacts := func() {
+		for _, f := range fileNames {
+			Expect(ioutil.WriteFile(filepath.Join(generator.workDir, f), []byte("content-"+f), 0600)).To(Succeed())
+		}
+		for _, host := range cluster.Hosts {
+			name := hostutil.IgnitionFileName(host)
+			Expect(ioutil.WriteFile(filepath.Join(generator.workDir, name), []byte("content-"+name), 0600)).To(Succeed())
+		}
+	}
+
+	manifestKey := func() string {
+		return filepath.Join(cluster.ID.String(), s3ManifestObjectName)
+	}
+
 	Context("cluster with known hosts", func() {
 		BeforeEach(func() {
 			hostID1 := strfmt.UUID(uuid.New().String())
@@ -735,6 +801,7 @@ var _ = Describe("Generator UploadToS3", func() {
 				{ID: &hostID2, Status: swag.String(models.HostStatusKnown), Role: models.HostRoleMaster},
 			}
 			generator.cluster = cluster
+			writeArtifacts()
 		})
 
 		It("validate upload files names", func() {
@@ -750,6 +817,8 @@ var _ = Describe("Generator UploadToS3", func() {
 				mockS3Client.EXPECT().UploadFile(gomock.Any(), fullPath, key).Return(nil).Times(1)
 				mockS3Client.EXPECT().UpdateObjectTimestamp(gomock.Any(), key).Return(true, nil).Times(1)
 			}
+			mockS3Client.EXPECT().UploadFile(gomock.Any(), gomock.Any(), manifestKey()).Return(nil).Times(1)
+			mockS3Client.EXPECT().UpdateObjectTimestamp(gomock.Any(), manifestKey()).Return(true, nil).Times(1)
 
 			Expect(generator.UploadToS3(ctx)).Should(Succeed())
 		})
@@ -771,6 +840,72 @@ var _ = Describe("Generator UploadToS3", func() {
 			err := generator.UploadToS3(ctx)
 			Expect(err).Should(HaveOccurred())
 		})
+
+		It("partial upload failure never uploads the manifest", func() {
+			// Every per-file upload is allowed to succeed except one, which
+			// always fails; the manifest must never be written regardless of
+			// which of the concurrent uploads lost the race to fail first.
+			mockUploadFile().DoAndReturn(func(_ context.Context, _, key string) error {
+				if key == filepath.Join(cluster.ID.String(), "worker.ign") {
+					return errors.New("upload error")
+				}
+				return nil
+			}).AnyTimes()
+			mockUploadObjectTimestamp().Return(true, nil).AnyTimes()
+			mockS3Client.EXPECT().UploadFile(gomock.Any(), gomock.Any(), manifestKey()).Times(0)
+
+			err := generator.UploadToS3(ctx)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("checksum mismatch aborts the batch before any manifest upload", func() {
+			// Truncating a file after it's been stat'd but simulating the
+			// same effect here by shrinking it on disk before upload is
+			// exercised directly against streamingSHA256 below; at this
+			// level we simulate the same failure mode via a missing file,
+			// which streamingSHA256 also surfaces as an upload-aborting
+			// error before any S3 call is made for it.
+			Expect(os.Remove(filepath.Join(generator.workDir, "worker.ign"))).To(Succeed())
+			mockUploadFile().Return(nil).AnyTimes()
+			mockUploadObjectTimestamp().Return(true, nil).AnyTimes()
+			mockS3Client.EXPECT().UploadFile(gomock.Any(), gomock.Any(), manifestKey()).Times(0)
+
+			err := generator.UploadToS3(ctx)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("concurrent uploads preserve per-artifact ordering in the manifest", func() {
+			generator.uploadConcurrency = 4
+			mockUploadFile().Return(nil).AnyTimes()
+			mockUploadObjectTimestamp().Return(true, nil).AnyTimes()
+
+			var manifestPath string
+			mockS3Client.EXPECT().UploadFile(gomock.Any(), gomock.Any(), manifestKey()).DoAndReturn(
+				func(_ context.Context, path, _ string) error {
+					manifestPath = path
+					return nil
+				}).Times(1)
+
+			Expect(generator.UploadToS3(ctx)).Should(Succeed())
+
+			raw, err := ioutil.ReadFile(manifestPath)
+			Expect(err).ToNot(HaveOccurred())
+			var manifest s3Manifest
+			Expect(json.Unmarshal(raw, &manifest)).To(Succeed())
+
+			wantOrder := make([]string, 0, len(fileNames)+len(cluster.Hosts))
+			for _, f := range fileNames {
+				wantOrder = append(wantOrder, filepath.Join(cluster.ID.String(), f))
+			}
+			for _, host := range cluster.Hosts {
+				wantOrder = append(wantOrder, filepath.Join(cluster.ID.String(), hostutil.IgnitionFileName(host)))
+			}
+			gotOrder := make([]string, len(manifest.Entries))
+			for i, e := range manifest.Entries {
+				gotOrder[i] = e.Path
+			}
+			Expect(gotOrder).To(Equal(wantOrder))
+		})
 	})
 })
 
@@ -1129,6 +1264,33 @@ var _ = Describe("IgnitionBuilder", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("rejects an infra-env override when signature verification is enabled and no signature is given", func() {
+		pub, _, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		infraEnv.IgnitionConfigOverride = `{"ignition": {"version": "3.1.0"}, "storage": {"files": [{"path": "/tmp/example", "contents": {"source": "data:text/plain;base64,aGVscGltdHJhcHBlZGluYXN3YWdnZXJzcGVj"}}]}}`
+		mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+
+		config := IgnitionConfig{TrustedOverrideKeys: []ed25519.PublicKey{pub}}
+		_, err = builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+		Expect(err).To(MatchError(ErrOverrideSignatureInvalid))
+	})
+
+	It("applies an infra-env override when signature verification is enabled and the signature is valid", func() {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		override := `{"ignition": {"version": "3.1.0"}, "storage": {"files": [{"path": "/tmp/example", "contents": {"source": "data:text/plain;base64,aGVscGltdHJhcHBlZGluYXN3YWdnZXJzcGVj"}}]}}`
+		infraEnv.IgnitionConfigOverride = override
+		mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+
+		config := IgnitionConfig{
+			TrustedOverrideKeys: []ed25519.PublicKey{pub},
+			OverrideSignature:   SignOverride([]byte(override), priv),
+		}
+		text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(ContainSubstring("/tmp/example"))
+	})
+
 	It("applies day2 overrides successfuly", func() {
 		hostID := strfmt.UUID(uuid.New().String())
 		cluster.Hosts = []*models.Host{{
@@ -1145,6 +1307,80 @@ var _ = Describe("IgnitionBuilder", func() {
 		Expect(text).Should(ContainSubstring("/tmp/example"))
 	})
 
+	Context("encrypted DNS", func() {
+		It("renders a systemd-resolved drop-in for a DoT resolver with a bootstrap IP", func() {
+			infraEnv.Proxy = &models.Proxy{}
+			config := IgnitionConfig{EncryptedDNS: &EncryptedDNSConfig{
+				Resolvers: []EncryptedDNSResolver{{Protocol: DNSResolverDoT, Address: "tls://dot.example.com:853", BootstrapIP: "192.0.2.1"}},
+			}}
+			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+			text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+			Expect(err).NotTo(HaveOccurred())
+
+			ignConfig, report, err := config_32.Parse([]byte(text))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.IsFatal()).To(BeFalse())
+
+			dropIn := findFileByPath(ignConfig.Storage.Files, resolvedDropInPath)
+			Expect(dropIn).NotTo(BeNil())
+			contents, err := decodeDataURLContents(swag.StringValue(dropIn.FileEmbedded1.Contents.Source))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("DNS=192.0.2.1#dot.example.com"))
+			Expect(string(contents)).To(ContainSubstring("DNSOverTLS=yes"))
+
+			resolvConf := findFileByPath(ignConfig.Storage.Files, resolvConfPath)
+			Expect(resolvConf).NotTo(BeNil())
+		})
+
+		It("renders a DoH proxy unit and points resolved at it", func() {
+			infraEnv.Proxy = &models.Proxy{}
+			config := IgnitionConfig{EncryptedDNS: &EncryptedDNSConfig{
+				Resolvers: []EncryptedDNSResolver{{Protocol: DNSResolverDoH, Address: "https://doh.example.com/dns-query", BootstrapIP: "192.0.2.2"}},
+			}}
+			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+			text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+			Expect(err).NotTo(HaveOccurred())
+
+			ignConfig, report, err := config_32.Parse([]byte(text))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.IsFatal()).To(BeFalse())
+
+			var dohUnit *config_32_types.Unit
+			for i := range ignConfig.Systemd.Units {
+				if ignConfig.Systemd.Units[i].Name == dohProxyUnitName {
+					dohUnit = &ignConfig.Systemd.Units[i]
+				}
+			}
+			Expect(dohUnit).NotTo(BeNil())
+			Expect(swag.StringValue(dohUnit.Contents)).To(ContainSubstring("doh-proxy"))
+
+			dropIn := findFileByPath(ignConfig.Storage.Files, resolvedDropInPath)
+			Expect(dropIn).NotTo(BeNil())
+			contents, err := decodeDataURLContents(swag.StringValue(dropIn.FileEmbedded1.Contents.Source))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("DNS=" + dohProxyListenAddr))
+		})
+
+		It("refuses a DoT resolver with neither a bootstrap IP nor a NoProxy entry", func() {
+			infraEnv.Proxy = &models.Proxy{}
+			config := IgnitionConfig{EncryptedDNS: &EncryptedDNSConfig{
+				Resolvers: []EncryptedDNSResolver{{Protocol: DNSResolverDoT, Address: "tls://dot.example.com:853"}},
+			}}
+			_, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("allows a bootstrap-IP-less DoT resolver whose hostname is in NoProxy", func() {
+			infraEnv.Proxy = &models.Proxy{NoProxy: swag.String("dot.example.com")}
+			config := IgnitionConfig{EncryptedDNS: &EncryptedDNSConfig{
+				Resolvers: []EncryptedDNSResolver{{Protocol: DNSResolverDoT, Address: "tls://dot.example.com:853"}},
+			}}
+			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+			_, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, config, false, auth.TypeRHSSO)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	Context("static network config", func() {
 		formattedInput := "some formated input"
 		staticnetworkConfigOutput := []staticnetworkconfig.StaticNetworkConfigData{
@@ -1198,6 +1434,53 @@ var _ = Describe("IgnitionBuilder", func() {
 			}
 			Expect(count).Should(Equal(0))
 		})
+
+		Context("NMState format", func() {
+			nmstateOutput := []staticnetworkconfig.NMStateFile{
+				{
+					FilePath:     "aabbccddeeff.yaml",
+					FileContents: "interfaces:\n- name: nic10\n  type: ethernet\n",
+				},
+			}
+
+			It("embeds the NMState yaml and a oneshot apply unit for full isos", func() {
+				mockStaticNetworkConfig.EXPECT().GenerateNMStateUnits(gomock.Any(), formattedInput).Return(nmstateOutput, nil).Times(1)
+				infraEnv.StaticNetworkConfig = formattedInput
+				infraEnv.StaticNetworkFormat = models.StaticNetworkFormatNmstate
+				infraEnv.Type = common.ImageTypePtr(models.ImageTypeFullIso)
+				mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+				text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, IgnitionConfig{}, false, auth.TypeRHSSO)
+				Expect(err).NotTo(HaveOccurred())
+				config, report, err := config_31.Parse([]byte(text))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.IsFatal()).To(BeFalse())
+
+				Expect(findFileByPath(toV32Files(config.Storage.Files), nmStateConfigDir+"/aabbccddeeff.yaml")).NotTo(BeNil())
+				var sawApplyUnit bool
+				for _, u := range config.Systemd.Units {
+					if u.Name == "nmstate-apply-aabbccddeeff.service" {
+						sawApplyUnit = true
+					}
+				}
+				Expect(sawApplyUnit).To(BeTrue(), "nmstate apply unit not present in %s", text)
+			})
+
+			It("doesn't include NMState config for minimal isos", func() {
+				mockStaticNetworkConfig.EXPECT().GenerateNMStateUnits(gomock.Any(), formattedInput).Return(nmstateOutput, nil).Times(1)
+				infraEnv.StaticNetworkConfig = formattedInput
+				infraEnv.StaticNetworkFormat = models.StaticNetworkFormatNmstate
+				infraEnv.Type = common.ImageTypePtr(models.ImageTypeMinimalIso)
+				mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+				text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, IgnitionConfig{}, false, auth.TypeRHSSO)
+				Expect(err).NotTo(HaveOccurred())
+				config, report, err := config_31.Parse([]byte(text))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.IsFatal()).To(BeFalse())
+
+				Expect(findFileByPath(toV32Files(config.Storage.Files), nmStateConfigDir+"/aabbccddeeff.yaml")).To(BeNil())
+				Expect(config.Systemd.Units).Should(HaveLen(1), "only agent.service should be present")
+			})
+		})
 	})
 
 	Context("mirror registries config", func() {
@@ -1206,6 +1489,7 @@ var _ = Describe("IgnitionBuilder", func() {
 			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(true).Times(1)
 			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorCA().Return([]byte("some ca config"), nil).Times(1)
 			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorRegistries().Return([]byte("some mirror registries config"), nil).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetSignaturePolicy().Return(nil, nil, nil).Times(1)
 			text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, IgnitionConfig{}, false, auth.TypeRHSSO)
 			Expect(err).NotTo(HaveOccurred())
 			config, report, err := config_31.Parse([]byte(text))
@@ -1219,6 +1503,60 @@ var _ = Describe("IgnitionBuilder", func() {
 			}
 			Expect(count).Should(Equal(2))
 		})
+
+		It("produce ignition with the mirror registries subsystem's signature policy", func() {
+			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(true).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorCA().Return([]byte("some ca config"), nil).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorRegistries().Return([]byte("some mirror registries config"), nil).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetSignaturePolicy().Return(
+				[]byte(`{"default": [ { "type": "reject" } ]}`),
+				map[string][]byte{containerSignatureKeyDir + "/mirror.example.com-0.gpg": []byte("gpg key material")},
+				nil).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetLookasideConfig().Return([]byte("docker:\n  mirror.example.com:\n    lookaside: https://mirror.example.com/signatures\n"), nil).Times(1)
+
+			text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, IgnitionConfig{}, false, auth.TypeRHSSO)
+			Expect(err).NotTo(HaveOccurred())
+			config, report, err := config_31.Parse([]byte(text))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.IsFatal()).To(BeFalse())
+
+			var sawPolicy, sawLookaside, sawKey bool
+			for _, f := range config.Storage.Files {
+				switch {
+				case f.Path == containerPolicyPath:
+					sawPolicy = true
+				case f.Path == containerRegistriesDDir+"/mirror.yaml":
+					sawLookaside = true
+				case strings.HasPrefix(f.Path, containerSignatureKeyDir):
+					sawKey = true
+				}
+			}
+			Expect(sawPolicy).To(BeTrue(), "policy.json not present in %s", text)
+			Expect(sawLookaside).To(BeTrue(), "registries.d lookaside map not present in %s", text)
+			Expect(sawKey).To(BeTrue(), "GPG key file not present in %s", text)
+		})
+
+		It("prefers an explicit IgnitionConfig.MirrorRegistriesTrust over the subsystem's signature policy", func() {
+			mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(true).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorCA().Return([]byte("some ca config"), nil).Times(1)
+			mockMirrorRegistriesConfigBuilder.EXPECT().GetMirrorRegistries().Return([]byte("some mirror registries config"), nil).Times(1)
+
+			cfg := IgnitionConfig{MirrorRegistriesTrust: map[string]RegistryTrustConfig{
+				"mirror.example.com": {Type: RegistryTrustInsecureAcceptAnything},
+			}}
+			text, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, cfg, false, auth.TypeRHSSO)
+			Expect(err).NotTo(HaveOccurred())
+			config, _, err := config_31.Parse([]byte(text))
+			Expect(err).NotTo(HaveOccurred())
+
+			var sawPolicy bool
+			for _, f := range config.Storage.Files {
+				if f.Path == containerPolicyPath {
+					sawPolicy = true
+				}
+			}
+			Expect(sawPolicy).To(BeTrue(), "policy.json not present in %s", text)
+		})
 	})
 })
 
@@ -1375,5 +1713,304 @@ var _ = Describe("FormatSecondDayWorkerIgnitionFile", func() {
 			Expect(ignConfig.Ignition.Security.TLS.CertificateAuthorities).Should(HaveLen(1))
 			Expect(swag.StringValue(ignConfig.Ignition.Security.TLS.CertificateAuthorities[0].Source)).Should(Equal("data:text/plain;base64," + encodedCa))
 		})
+
+		It("are rendered properly with mTLS client cert and key", func() {
+			auth := SecondDayAuth{ClientCert: "client cert pem", ClientKey: "client key pem"}
+			ign, err := builder.FormatSecondDayWorkerIgnitionFileWithAuth("https://url.com", auth, mockHost)
+			Expect(err).NotTo(HaveOccurred())
+
+			ignConfig, _, err := config_31.Parse(ign)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findFileByPath(toV32Files(ignConfig.Storage.Files), secondDayClientCertPath)).NotTo(BeNil())
+			Expect(findFileByPath(toV32Files(ignConfig.Storage.Files), secondDayClientKeyPath)).NotTo(BeNil())
+			Expect(ignConfig.Systemd.Units).Should(HaveLen(1))
+			Expect(ignConfig.Systemd.Units[0].Name).Should(Equal("machine-config-daemon.service"))
+		})
+
+		It("are rendered properly with extra headers", func() {
+			auth := SecondDayAuth{
+				BearerToken:  "xyzabc123",
+				ExtraHeaders: []SecondDayAuthHeader{{Name: "X-Tenant-Id", Value: "tenant-1"}},
+			}
+			ign, err := builder.FormatSecondDayWorkerIgnitionFileWithAuth("https://url.com", auth, mockHost)
+			Expect(err).NotTo(HaveOccurred())
+
+			ignConfig, _, err := config_31.Parse(ign)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ignConfig.Ignition.Config.Merge[0].HTTPHeaders).Should(HaveLen(2))
+			Expect(ignConfig.Ignition.Config.Merge[0].HTTPHeaders[0].Name).Should(Equal("Authorization"))
+			Expect(ignConfig.Ignition.Config.Merge[0].HTTPHeaders[1].Name).Should(Equal("X-Tenant-Id"))
+			Expect(swag.StringValue(ignConfig.Ignition.Config.Merge[0].HTTPHeaders[1].Value)).Should(Equal("tenant-1"))
+		})
+
+		It("omits the CA when InsecureSkipVerify is set, even if a CA was provided", func() {
+			ca := base64.StdEncoding.EncodeToString([]byte("some ca"))
+			auth := SecondDayAuth{CACert: &ca, InsecureSkipVerify: true}
+			ign, err := builder.FormatSecondDayWorkerIgnitionFileWithAuth("https://url.com", auth, mockHost)
+			Expect(err).NotTo(HaveOccurred())
+
+			ignConfig, _, err := config_31.Parse(ign)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ignConfig.Ignition.Security.TLS.CertificateAuthorities).Should(HaveLen(0))
+		})
+	})
+})
+
+// toV32Files converts a slice of config_31 storage files to config_32_types
+// for reuse with findFileByPath, whose callers otherwise all work on v3.2+
+// configs.
+func toV32Files(files []config_31_types.File) []config_32_types.File {
+	out := make([]config_32_types.File, 0, len(files))
+	for i := range files {
+		raw, _ := json.Marshal(files[i])
+		var v32 config_32_types.File
+		_ = json.Unmarshal(raw, &v32)
+		out = append(out, v32)
+	}
+	return out
+}
+
+func decodeDataURLContents(source string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(source, "data:,"))
+}
+
+func findFileByPath(files []config_32_types.File, path string) *config_32_types.File {
+	for i := range files {
+		if files[i].Node.Path == path {
+			return &files[i]
+		}
+	}
+	return nil
+}
+
+var _ = Describe("verifyOverrideSignature", func() {
+	var (
+		pub  ed25519.PublicKey
+		priv ed25519.PrivateKey
+	)
+
+	BeforeEach(func() {
+		var err error
+		pub, priv, err = ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts a valid signature from a trusted key", func() {
+		payload := []byte(`{"ignition": {"version": "3.1.0"}}`)
+		sig := SignOverride(payload, priv)
+		Expect(verifyOverrideSignature(payload, sig, []ed25519.PublicKey{pub})).To(Succeed())
+	})
+
+	It("rejects a signature from an untrusted key", func() {
+		payload := []byte(`{"ignition": {"version": "3.1.0"}}`)
+		_, otherPriv, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		sig := SignOverride(payload, otherPriv)
+		Expect(verifyOverrideSignature(payload, sig, []ed25519.PublicKey{pub})).To(MatchError(ErrOverrideSignatureInvalid))
+	})
+
+	It("rejects a tampered payload", func() {
+		payload := []byte(`{"ignition": {"version": "3.1.0"}}`)
+		sig := SignOverride(payload, priv)
+		tampered := append([]byte{}, payload...)
+		tampered[0] = 'X'
+		Expect(verifyOverrideSignature(tampered, sig, []ed25519.PublicKey{pub})).To(MatchError(ErrOverrideSignatureInvalid))
+	})
+
+	It("rejects a missing signature", func() {
+		payload := []byte(`{"ignition": {"version": "3.1.0"}}`)
+		Expect(verifyOverrideSignature(payload, nil, []ed25519.PublicKey{pub})).To(MatchError(ErrOverrideSignatureInvalid))
+	})
+})
+
+var _ = Describe("containerSignaturePolicyFiles", func() {
+	It("returns nothing when no registry has a trust entry", func() {
+		files, err := containerSignaturePolicyFiles(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("renders policy.json and registries.d for a signedBy registry", func() {
+		trust := map[string]RegistryTrustConfig{
+			"mirror.example.com:5000": {
+				Type:      RegistryTrustSignedBy,
+				GPGKeys:   []string{"-----BEGIN PGP PUBLIC KEY BLOCK-----\nfake\n-----END PGP PUBLIC KEY BLOCK-----"},
+				Lookaside: "https://mirror.example.com/signatures",
+			},
+		}
+
+		files, err := containerSignaturePolicyFiles(trust)
+		Expect(err).NotTo(HaveOccurred())
+
+		var policy, registriesD, key *config_34_types.File
+		for i := range files {
+			switch {
+			case files[i].Node.Path == containerPolicyPath:
+				policy = &files[i]
+			case files[i].Node.Path == containerRegistriesDDir+"/mirror.example.com:5000.yaml":
+				registriesD = &files[i]
+			case strings.HasPrefix(files[i].Node.Path, containerSignatureKeyDir):
+				key = &files[i]
+			}
+		}
+		Expect(policy).NotTo(BeNil())
+		Expect(registriesD).NotTo(BeNil())
+		Expect(key).NotTo(BeNil())
+
+		policyContents, err := decodeDataURLContents(swag.StringValue(policy.FileEmbedded1.Contents.Source))
+		Expect(err).NotTo(HaveOccurred())
+		var parsedPolicy struct {
+			Transports struct {
+				Docker map[string][]struct {
+					Type    string `json:"type"`
+					KeyPath string `json:"keyPath"`
+				} `json:"docker"`
+			} `json:"transports"`
+		}
+		Expect(json.Unmarshal(policyContents, &parsedPolicy)).To(Succeed())
+		entries := parsedPolicy.Transports.Docker["mirror.example.com:5000"]
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Type).To(Equal("signedBy"))
+		Expect(entries[0].KeyPath).NotTo(BeEmpty())
+
+		registriesDContents, err := decodeDataURLContents(swag.StringValue(registriesD.FileEmbedded1.Contents.Source))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(registriesDContents)).To(ContainSubstring("lookaside: https://mirror.example.com/signatures"))
+	})
+
+	It("fails when a signedBy registry has no GPG key", func() {
+		trust := map[string]RegistryTrustConfig{
+			"mirror.example.com:5000": {Type: RegistryTrustSignedBy},
+		}
+		_, err := containerSignaturePolicyFiles(trust)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("keeps every rotated key in the policy, not just the first", func() {
+		trust := map[string]RegistryTrustConfig{
+			"mirror.example.com:5000": {
+				Type: RegistryTrustSignedBy,
+				GPGKeys: []string{
+					"-----BEGIN PGP PUBLIC KEY BLOCK-----\nkey-a\n-----END PGP PUBLIC KEY BLOCK-----",
+					"-----BEGIN PGP PUBLIC KEY BLOCK-----\nkey-b\n-----END PGP PUBLIC KEY BLOCK-----",
+				},
+			},
+		}
+
+		files, err := containerSignaturePolicyFiles(trust)
+		Expect(err).NotTo(HaveOccurred())
+
+		keyPaths := make(map[string]bool)
+		var policy *config_34_types.File
+		for i := range files {
+			switch {
+			case files[i].Node.Path == containerPolicyPath:
+				policy = &files[i]
+			case strings.HasPrefix(files[i].Node.Path, containerSignatureKeyDir):
+				keyPaths[files[i].Node.Path] = true
+			}
+		}
+		Expect(keyPaths).To(HaveLen(2))
+		Expect(policy).NotTo(BeNil())
+
+		policyContents, err := decodeDataURLContents(swag.StringValue(policy.FileEmbedded1.Contents.Source))
+		Expect(err).NotTo(HaveOccurred())
+		var parsedPolicy struct {
+			Transports struct {
+				Docker map[string][]struct {
+					Type    string `json:"type"`
+					KeyPath string `json:"keyPath"`
+				} `json:"docker"`
+			} `json:"transports"`
+		}
+		Expect(json.Unmarshal(policyContents, &parsedPolicy)).To(Succeed())
+		entries := parsedPolicy.Transports.Docker["mirror.example.com:5000"]
+		Expect(entries).To(HaveLen(2))
+		for _, entry := range entries {
+			Expect(keyPaths[entry.KeyPath]).To(BeTrue())
+		}
+	})
+})
+
+var _ = Describe("ignition recorder", func() {
+	var (
+		ctrl                              *gomock.Controller
+		log                               logrus.FieldLogger
+		infraEnv                          common.InfraEnv
+		builder                           IgnitionBuilder
+		mockStaticNetworkConfig           *staticnetworkconfig.MockStaticNetworkConfig
+		mockMirrorRegistriesConfigBuilder *mirrorregistries.MockMirrorRegistriesConfigBuilder
+		recorderDir                       string
+	)
+
+	BeforeEach(func() {
+		log = common.GetTestLog()
+		ctrl = gomock.NewController(GinkgoT())
+		mockStaticNetworkConfig = staticnetworkconfig.NewMockStaticNetworkConfig(ctrl)
+		mockMirrorRegistriesConfigBuilder = mirrorregistries.NewMockMirrorRegistriesConfigBuilder(ctrl)
+		builder = NewBuilder(log, mockStaticNetworkConfig, mockMirrorRegistriesConfigBuilder)
+		infraEnvID := strfmt.UUID("a64fff36-dcb1-11ea-87d0-0242ac130003")
+		infraEnv = common.InfraEnv{InfraEnv: models.InfraEnv{ID: &infraEnvID}}
+
+		var err error
+		recorderDir, err = ioutil.TempDir("", "ignition-recorder-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(recorderDir)).To(Succeed())
+	})
+
+	bundlePath := func() string {
+		entries, err := ioutil.ReadDir(recorderDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		return filepath.Join(recorderDir, entries[0].Name())
+	}
+
+	It("records a reproducer bundle and reports a diff when a replay's input is mutated", func() {
+		cfg := IgnitionConfig{ServiceBaseURL: "https://service.example.com", Recorder: &RecorderConfig{Dir: recorderDir}}
+		mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+		recorded, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, cfg, false, auth.TypeNone)
+		Expect(err).NotTo(HaveOccurred())
+
+		kind, input, output, callErr, err := LoadReproducerBundle(bundlePath(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kind).To(Equal("discovery-ignition"))
+		Expect(callErr).To(BeEmpty())
+		Expect(output).To(Equal(recorded))
+
+		var replayedInput struct {
+			Config IgnitionConfig
+		}
+		Expect(json.Unmarshal(input, &replayedInput)).To(Succeed())
+
+		// Mutate the recorded input the way a developer debugging a support
+		// case would, then replay it and confirm the harness flags the
+		// resulting ignition as different from what was originally recorded.
+		replayedInput.Config.ServiceBaseURL = "https://mutated.example.com"
+		mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+		replayed, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, replayedInput.Config, false, auth.TypeNone)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(DiffReplayOutput(output, replayed)).NotTo(BeEmpty())
+		Expect(DiffReplayOutput(output, recorded)).To(BeEmpty())
+	})
+
+	It("redacts the pull secret by default and recovers it with the recorder key", func() {
+		key := []byte("01234567890123456789012345678901")
+		cfg := IgnitionConfig{Recorder: &RecorderConfig{Dir: recorderDir, RedactionKey: key}}
+		infraEnv.PullSecret = "{\"auths\":{\"cloud.openshift.com\":{\"auth\":\"dG9rZW46dGVzdAo=\"}}}"
+		mockMirrorRegistriesConfigBuilder.EXPECT().IsMirrorRegistriesConfigured().Return(false).Times(1)
+		_, err := builder.FormatDiscoveryIgnitionFile(context.Background(), &infraEnv, cfg, false, auth.TypeRHSSO)
+		Expect(err).NotTo(HaveOccurred())
+
+		rawNoKey, err := ioutil.ReadFile(bundlePath())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(rawNoKey)).NotTo(ContainSubstring(infraEnv.PullSecret))
+
+		_, input, _, _, err := LoadReproducerBundle(bundlePath(), key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(input)).To(ContainSubstring(infraEnv.PullSecret))
 	})
 })
@@ -0,0 +1,38 @@
+package ignition
+
+import (
+	"crypto/ed25519"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOverrideSignatureInvalid is the stable sentinel returned by
+// verifyOverrideSignature so the REST layer can tell a bad/missing signature
+// apart from other ignition-generation failures and surface it as a 400
+// rather than a 500.
+var ErrOverrideSignatureInvalid = errors.New("ignition config override signature is missing or does not match a trusted key")
+
+// verifyOverrideSignature checks payload against sig using each of
+// trustedKeys in turn, succeeding as soon as one key verifies. It is used to
+// gate IgnitionConfigOverride / IgnitionConfigOverrides before they are fed
+// into MergeIgnitionConfig, once a caller has opted into override signature
+// verification by supplying a non-empty set of trusted keys.
+func verifyOverrideSignature(payload, sig []byte, trustedKeys []ed25519.PublicKey) error {
+	if len(sig) == 0 {
+		return ErrOverrideSignatureInvalid
+	}
+	for _, key := range trustedKeys {
+		if ed25519.Verify(key, payload, sig) {
+			return nil
+		}
+	}
+	return ErrOverrideSignatureInvalid
+}
+
+// SignOverride produces the detached Ed25519 signature for payload that
+// verifyOverrideSignature expects, so operators can script signing an
+// ignition config override file with a trusted private key before handing
+// it to the API.
+func SignOverride(payload []byte, key ed25519.PrivateKey) []byte {
+	return ed25519.Sign(key, payload)
+}
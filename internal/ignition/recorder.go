@@ -0,0 +1,317 @@
+package ignition
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RecorderConfig enables the record-and-replay reproducer harness for
+// FormatDiscoveryIgnitionFile, FormatSecondDayWorkerIgnitionFile and
+// createHostIgnitions: every call recorded this way writes its full input
+// set plus the rendered output into a single tar.gz "reproducer bundle"
+// under Dir, so a developer can feed it to the companion `ignition-replay`
+// command instead of reconstructing mocks and DB state from a support case
+// by hand.
+//
+// Pull secrets and IgnitionConfigOverride/OverrideSignature contents are
+// redacted before they're written. Leaving RedactionKey empty drops them
+// irrecoverably; setting it encrypts them instead, so a developer holding
+// the same key can pass it to `ignition-replay -key` to recover a faithful
+// replay.
+type RecorderConfig struct {
+	Dir          string
+	RedactionKey []byte
+}
+
+// Enabled reports whether recording was actually requested.
+func (c *RecorderConfig) Enabled() bool {
+	return c != nil && c.Dir != ""
+}
+
+// RecorderDirEnvVar and RecorderKeyEnvVar let entry points that don't carry
+// an IgnitionConfig (FormatSecondDayWorkerIgnitionFile, createHostIgnitions)
+// opt into recording without a dedicated flag on every caller.
+const (
+	RecorderDirEnvVar = "ASSISTED_IGNITION_RECORDER_DIR"
+	RecorderKeyEnvVar = "ASSISTED_IGNITION_RECORDER_KEY"
+)
+
+// RecorderConfigFromEnv builds a RecorderConfig from RecorderDirEnvVar/
+// RecorderKeyEnvVar, or returns nil if recording was not requested.
+func RecorderConfigFromEnv() *RecorderConfig {
+	dir := os.Getenv(RecorderDirEnvVar)
+	if dir == "" {
+		return nil
+	}
+	cfg := &RecorderConfig{Dir: dir}
+	if key := os.Getenv(RecorderKeyEnvVar); key != "" {
+		if decoded, err := base64.StdEncoding.DecodeString(key); err == nil {
+			cfg.RedactionKey = decoded
+		}
+	}
+	return cfg
+}
+
+// reproducerBundle is the manifest recorded into each bundle's manifest.json.
+// Input is the redacted JSON encoding of the call's arguments; Output/Err are
+// what the call returned.
+type reproducerBundle struct {
+	Kind   string          `json:"kind"`
+	Input  json.RawMessage `json:"input"`
+	Output string          `json:"output,omitempty"`
+	Err    string          `json:"err,omitempty"`
+}
+
+const bundleManifestName = "manifest.json"
+
+// record redacts and serializes input/output into a reproducer bundle named
+// <kind>-<unix-nano>.tar.gz under c.Dir. Recording failures are the caller's
+// to decide on; they never affect the ignition that was actually rendered.
+func (c *RecorderConfig) record(kind string, input interface{}, output []byte, callErr error) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal recorder input")
+	}
+	redacted, err := redactSecrets(raw, c.RedactionKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to redact recorder input")
+	}
+
+	bundle := reproducerBundle{Kind: kind, Input: redacted, Output: string(output)}
+	if callErr != nil {
+		bundle.Err = callErr.Error()
+	}
+	manifest, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create recorder directory %s", c.Dir)
+	}
+	path := filepath.Join(c.Dir, fmt.Sprintf("%s-%d.tar.gz", kind, time.Now().UnixNano()))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: bundleManifestName, Size: int64(len(manifest)), Mode: 0o644}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// DiffReplayOutput compares a bundle's recorded output against a freshly
+// produced one, as `ignition-replay` does after re-invoking the builder
+// against the bundle's (redaction-recovered) input. Returns "" when they
+// match, otherwise a human-readable description of the mismatch.
+func DiffReplayOutput(recorded, replayed string) string {
+	if recorded == replayed {
+		return ""
+	}
+	return fmt.Sprintf("replayed output does not match the recorded bundle:\n--- recorded\n%s\n--- replayed\n%s\n", recorded, replayed)
+}
+
+// LoadReproducerBundle reads a bundle written by RecorderConfig.record back
+// out, decrypting redacted fields with key when non-empty (a key mismatch or
+// an irrecoverably-redacted field is left as its redaction marker).
+func LoadReproducerBundle(path string, key []byte) (kind string, input json.RawMessage, output string, callErr string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", nil, "", "", err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", nil, "", "", errors.Errorf("%s: missing %s", path, bundleManifestName)
+		}
+		if err != nil {
+			return "", nil, "", "", err
+		}
+		if hdr.Name != bundleManifestName {
+			continue
+		}
+		raw, err := io.ReadAll(tr)
+		if err != nil {
+			return "", nil, "", "", err
+		}
+		var bundle reproducerBundle
+		if err := json.Unmarshal(raw, &bundle); err != nil {
+			return "", nil, "", "", err
+		}
+		recovered, err := recoverSecrets(bundle.Input, key)
+		if err != nil {
+			return "", nil, "", "", err
+		}
+		return bundle.Kind, recovered, bundle.Output, bundle.Err, nil
+	}
+}
+
+// sensitiveJSONFields are the JSON object keys redactSecrets scrubs wherever
+// they appear in a recorded call's input, regardless of which struct they
+// belong to or whether that struct's json tags are CamelCase (internal
+// structs like IgnitionConfig) or snake_case (swagger-generated models).
+var sensitiveJSONFields = map[string]bool{
+	"PullSecret":                true,
+	"pull_secret":               true,
+	"IgnitionConfigOverride":    true,
+	"ignition_config_override":  true,
+	"IgnitionConfigOverrides":   true,
+	"ignition_config_overrides": true,
+	"OverrideSignature":         true,
+	"override_signature":        true,
+	"IgnitionEndpointToken":     true,
+	"ignition_endpoint_token":   true,
+}
+
+const (
+	redactedMarker  = "<redacted>"
+	encryptedPrefix = "encrypted:"
+)
+
+func redactSecrets(raw []byte, key []byte) (json.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	redactValue(v, key)
+	return json.Marshal(v)
+}
+
+func redactValue(v interface{}, key []byte) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for field, val := range t {
+			if sensitiveJSONFields[field] {
+				if s, ok := val.(string); ok && s != "" {
+					t[field] = redactString(s, key)
+				}
+				continue
+			}
+			redactValue(val, key)
+		}
+	case []interface{}:
+		for _, e := range t {
+			redactValue(e, key)
+		}
+	}
+}
+
+func redactString(s string, key []byte) string {
+	if len(key) == 0 {
+		return redactedMarker
+	}
+	ciphertext, err := encryptValue(s, key)
+	if err != nil {
+		return redactedMarker
+	}
+	return encryptedPrefix + ciphertext
+}
+
+func recoverSecrets(raw json.RawMessage, key []byte) (json.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	recoverValue(v, key)
+	return json.Marshal(v)
+}
+
+func recoverValue(v interface{}, key []byte) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for field, val := range t {
+			if s, ok := val.(string); ok && strings.HasPrefix(s, encryptedPrefix) {
+				if plain, err := decryptValue(strings.TrimPrefix(s, encryptedPrefix), key); err == nil {
+					t[field] = plain
+				}
+				continue
+			}
+			recoverValue(val, key)
+		}
+	case []interface{}:
+		for _, e := range t {
+			recoverValue(e, key)
+		}
+	}
+}
+
+func encryptValue(plaintext string, key []byte) (string, error) {
+	gcm, err := gcmFromKey(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func decryptValue(encoded string, key []byte) (string, error) {
+	gcm, err := gcmFromKey(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted recorder value is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// gcmFromKey derives an AES-256-GCM cipher from key, padding/truncating it
+// to 32 bytes so any non-empty RecorderConfig.RedactionKey works.
+func gcmFromKey(key []byte) (cipher.AEAD, error) {
+	fixed := make([]byte, 32)
+	copy(fixed, key)
+	block, err := aes.NewCipher(fixed)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,205 @@
+package ignition
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/openshift/assisted-service/internal/host/hostutil"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+)
+
+// defaultUploadConcurrency is how many UploadToS3 uploads run at once when
+// SetUploadConcurrency hasn't overridden it.
+const defaultUploadConcurrency = 8
+
+// hashChunkSize bounds how much of a file UploadToS3 holds in memory at once
+// while streaming it to compute its SHA-256 ahead of the upload.
+const hashChunkSize = 5 * 1024 * 1024
+
+// s3ManifestObjectName is the well-known key (relative to the cluster's S3
+// prefix) UploadToS3 writes the signed artifact manifest to.
+const s3ManifestObjectName = "manifest.json"
+
+// manifestEntry describes one artifact UploadToS3 uploaded.
+type manifestEntry struct {
+	Path   string          `json:"path"`
+	Size   int64           `json:"size"`
+	SHA256 string          `json:"sha256"`
+	Role   models.HostRole `json:"role,omitempty"`
+	HostID *strfmt.UUID    `json:"host_id,omitempty"`
+}
+
+// s3Manifest is the JSON object UploadToS3 writes to s3ManifestObjectName.
+// Signature, when present, is the base64-encoded Ed25519 signature (see
+// SignOverride) of the JSON encoding of Entries, produced with
+// installerGenerator.manifestSigningKey.
+type s3Manifest struct {
+	Entries   []manifestEntry `json:"entries"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// uploadTask is one file uploadToS3 uploads; role/hostID are carried through
+// to the artifact's manifestEntry and are zero for cluster-scoped artifacts.
+type uploadTask struct {
+	name   string
+	role   models.HostRole
+	hostID *strfmt.UUID
+}
+
+func (g *installerGenerator) uploadToS3(ctx context.Context) error {
+	tasks := make([]uploadTask, 0, len(fileNames)+len(g.cluster.Hosts))
+	for _, name := range fileNames {
+		tasks = append(tasks, uploadTask{name: name})
+	}
+	for _, host := range g.cluster.Hosts {
+		tasks = append(tasks, uploadTask{name: hostutil.IgnitionFileName(host), role: host.Role, hostID: host.ID})
+	}
+
+	entries, err := g.uploadAll(ctx, tasks)
+	if err != nil {
+		// Abort before the manifest is ever uploaded, so a manifest object
+		// in S3 always lists artifacts that are actually present.
+		return err
+	}
+
+	return g.uploadManifest(ctx, entries)
+}
+
+// uploadAll runs one uploadOne per task in a worker pool bounded by
+// g.uploadConcurrency (defaultUploadConcurrency if unset), preserving each
+// task's position in the returned slice regardless of completion order so
+// the manifest lists artifacts in the same order callers passed them in.
+func (g *installerGenerator) uploadAll(ctx context.Context, tasks []uploadTask) ([]manifestEntry, error) {
+	concurrency := g.uploadConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultUploadConcurrency
+	}
+
+	entries := make([]manifestEntry, len(tasks))
+	errs := make([]error, len(tasks))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		i, task := i, task
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i], errs[i] = g.uploadOne(ctx, task)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// uploadOne streams task's file into a SHA-256 digest in hashChunkSize
+// chunks, uploads it, and refreshes its access timestamp.
+func (g *installerGenerator) uploadOne(ctx context.Context, task uploadTask) (manifestEntry, error) {
+	fullPath := filepath.Join(g.workDir, task.name)
+	key := filepath.Join(g.cluster.ID.String(), task.name)
+
+	sha256Hex, size, err := streamingSHA256(fullPath)
+	if err != nil {
+		return manifestEntry{}, errors.Wrapf(err, "failed to checksum %s before upload", task.name)
+	}
+
+	if err := g.s3Client.UploadFile(ctx, fullPath, key); err != nil {
+		return manifestEntry{}, errors.Wrapf(err, "failed to upload %s to s3", task.name)
+	}
+	if _, err := g.s3Client.UpdateObjectTimestamp(ctx, key); err != nil {
+		return manifestEntry{}, errors.Wrapf(err, "failed to set timestamp on %s", task.name)
+	}
+
+	return manifestEntry{Path: key, Size: size, SHA256: sha256Hex, Role: task.role, HostID: task.hostID}, nil
+}
+
+// uploadManifest writes the signed artifact manifest to a temporary file
+// under g.workDir and uploads it to s3ManifestObjectName.
+func (g *installerGenerator) uploadManifest(ctx context.Context, entries []manifestEntry) error {
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal upload manifest")
+	}
+
+	manifest := s3Manifest{Entries: entries}
+	if len(g.manifestSigningKey) > 0 {
+		manifest.Signature = base64.StdEncoding.EncodeToString(SignOverride(payload, g.manifestSigningKey))
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal upload manifest")
+	}
+
+	manifestPath := filepath.Join(g.workDir, s3ManifestObjectName)
+	if err := ioutil.WriteFile(manifestPath, raw, 0o600); err != nil {
+		return errors.Wrap(err, "failed to write upload manifest")
+	}
+
+	key := filepath.Join(g.cluster.ID.String(), s3ManifestObjectName)
+	if err := g.s3Client.UploadFile(ctx, manifestPath, key); err != nil {
+		return errors.Wrap(err, "failed to upload manifest to s3")
+	}
+	if _, err := g.s3Client.UpdateObjectTimestamp(ctx, key); err != nil {
+		return errors.Wrap(err, "failed to set timestamp on manifest")
+	}
+	return nil
+}
+
+// streamingSHA256 hashes path in hashChunkSize chunks so memory use stays
+// bounded regardless of the file's size, returning the hex digest and the
+// number of bytes actually read. It fails if that count doesn't match the
+// size os.Stat reported up front, catching a file that was truncated or
+// otherwise changed out from under the upload.
+func streamingSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	hasher := sha256.New()
+	buf := make([]byte, hashChunkSize)
+	var total int64
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			hasher.Write(buf[:n])
+			total += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	if total != info.Size() {
+		return "", 0, errors.Errorf("checksum mismatch for %s: read %d bytes but stat reported %d", path, total, info.Size())
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), total, nil
+}
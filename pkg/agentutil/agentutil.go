@@ -0,0 +1,25 @@
+// Package agentutil provides small, dependency-light helpers for
+// third-party controllers that only need to read an Agent's status and role
+// - not the full reconciler machinery - to decide whether to act on it.
+package agentutil
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Ready reports whether agent's v1beta1.ReadyCondition is True - the stable
+// summary a higher-level controller (e.g. a NodePool waiting on its hosts)
+// should watch instead of ANDing the Agent's sub-conditions together itself.
+func Ready(agent *v1beta1.Agent) bool {
+	cond := conditionsv1.FindStatusCondition(agent.Status.Conditions, v1beta1.ReadyCondition)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
+// IsControlPlane reports whether agent is labeled for the control-plane
+// role, the way a caller would check node-role.kubernetes.io/control-plane
+// on a Node.
+func IsControlPlane(agent *v1beta1.Agent) bool {
+	return agent.Labels[v1beta1.HostRoleLabel] == v1beta1.HostRoleMaster
+}
@@ -0,0 +1,51 @@
+package agentutil
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Ready", func() {
+	It("returns false when ReadyCondition hasn't been set yet", func() {
+		agent := &v1beta1.Agent{}
+		Expect(Ready(agent)).To(BeFalse())
+	})
+
+	It("returns true when ReadyCondition is True", func() {
+		agent := &v1beta1.Agent{}
+		agent.Status.Conditions = []conditionsv1.Condition{
+			{Type: v1beta1.ReadyCondition, Status: corev1.ConditionTrue, Reason: v1beta1.ReadyReason},
+		}
+		Expect(Ready(agent)).To(BeTrue())
+	})
+
+	It("returns false when ReadyCondition is False", func() {
+		agent := &v1beta1.Agent{}
+		agent.Status.Conditions = []conditionsv1.Condition{
+			{Type: v1beta1.ReadyCondition, Status: corev1.ConditionFalse, Reason: v1beta1.NotReadyReason},
+		}
+		Expect(Ready(agent)).To(BeFalse())
+	})
+})
+
+var _ = Describe("IsControlPlane", func() {
+	It("returns false when the role label is absent", func() {
+		agent := &v1beta1.Agent{}
+		Expect(IsControlPlane(agent)).To(BeFalse())
+	})
+
+	It("returns true when the role label is master", func() {
+		agent := &v1beta1.Agent{}
+		agent.Labels = map[string]string{v1beta1.HostRoleLabel: v1beta1.HostRoleMaster}
+		Expect(IsControlPlane(agent)).To(BeTrue())
+	})
+
+	It("returns false when the role label is worker", func() {
+		agent := &v1beta1.Agent{}
+		agent.Labels = map[string]string{v1beta1.HostRoleLabel: v1beta1.HostRoleWorker}
+		Expect(IsControlPlane(agent)).To(BeFalse())
+	})
+})
@@ -0,0 +1,79 @@
+// Package agenthealth evaluates a host's backend-reported status and
+// ValidationsInfo into a structured health result, decoupled from the Agent
+// CRD and from AgentReconciler itself. AgentReconciler composes an
+// AgentHealth's checks into the Agent's conditions; this package only knows
+// about models.Host.
+package agenthealth
+
+import "github.com/openshift/assisted-service/models"
+
+// CheckID identifies a single check within an AgentHealth result, the way a
+// GroupVersionKind identifies a Kubernetes type: Group names the subsystem
+// that owns the check (empty for the builtin core checks), and Name is the
+// check itself.
+type CheckID struct {
+	Group string
+	Name  string
+}
+
+// Core check names returned by the builtin checker, one per Agent condition
+// it feeds.
+const (
+	RequirementsMetCheck = "RequirementsMet"
+	ConnectedCheck       = "Connected"
+	InstalledCheck       = "Installed"
+	ValidatedCheck       = "Validated"
+	BoundCheck           = "Bound"
+)
+
+// CheckResult is one check's outcome: Healthy plus a Reason/Message pair in
+// the same vocabulary as conditionsv1.Condition, so AgentReconciler can copy
+// it onto an Agent condition without translation.
+type CheckResult struct {
+	ID      CheckID
+	Healthy bool
+	Reason  string
+	Message string
+}
+
+// ValidationCategoryResult is one ValidationsInfo category's (network,
+// hardware, operators, ...) breakdown into failing and pending check
+// messages, preserved in source order so aggregation reads the same as the
+// backend reported it.
+type ValidationCategoryResult struct {
+	Category string
+	Failing  []string
+	Pending  []string
+}
+
+// AgentHealth is a HealthChecker's structured verdict on a host: the five
+// core checks the builtin evaluator always produces, the ValidationsInfo
+// breakdown behind the Validated check, and Extra checks contributed by any
+// additional checkers Compose folded in.
+type AgentHealth struct {
+	RequirementsMet CheckResult
+	Connected       CheckResult
+	Installed       CheckResult
+	Validated       CheckResult
+	Bound           CheckResult
+	Categories      []ValidationCategoryResult
+	Extra           []CheckResult
+}
+
+// Checks returns every check AgentHealth carries - the five core ones plus
+// Extra - in a stable order, for a caller that wants to range over them
+// generically (e.g. an additional checker folded in by Compose).
+func (h AgentHealth) Checks() []CheckResult {
+	checks := append([]CheckResult{h.RequirementsMet, h.Connected, h.Installed, h.Validated, h.Bound}, h.Extra...)
+	return checks
+}
+
+// HealthChecker evaluates host's backend-reported state into an AgentHealth.
+// The builtin implementation reproduces AgentReconciler's historical
+// hardcoded mapping; additional checkers registered via RegisterChecker can
+// contribute further checks (e.g. disconnected-environment or
+// operator-specific readiness) without AgentReconciler or the builtin
+// checker knowing about them.
+type HealthChecker interface {
+	Evaluate(host *models.Host) (AgentHealth, error)
+}
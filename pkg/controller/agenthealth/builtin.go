@@ -0,0 +1,188 @@
+package agenthealth
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+	"github.com/pkg/errors"
+)
+
+// builtinChecker reproduces AgentReconciler's historical hardcoded mapping
+// from a host's backend status and ValidationsInfo to its five core checks.
+// It has no dependency on the Agent CRD or on conditionsv1; the reconciler
+// is responsible for copying its CheckResults onto the matching conditions.
+type builtinChecker struct{}
+
+// NewBuiltinChecker returns the default HealthChecker, reproducing the
+// condition mapping AgentReconciler used before this package existed.
+func NewBuiltinChecker() HealthChecker {
+	return &builtinChecker{}
+}
+
+func (c *builtinChecker) Evaluate(host *models.Host) (AgentHealth, error) {
+	categories, err := parseValidationsInfo(host.ValidationsInfo)
+	if err != nil {
+		return AgentHealth{}, err
+	}
+
+	status := ""
+	if host.Status != nil {
+		status = *host.Status
+	}
+	statusInfo := ""
+	if host.StatusInfo != nil {
+		statusInfo = *host.StatusInfo
+	}
+
+	return AgentHealth{
+		RequirementsMet: requirementsMetCheckApproved(status, host.Approved),
+		Connected:       connectedCheck(status),
+		Installed:       installedCheck(status, statusInfo),
+		Validated:       validatedCheck(status, categories),
+		Bound:           boundCheck(status),
+		Categories:      categories,
+	}, nil
+}
+
+func requirementsMetCheck(status string) CheckResult {
+	id := CheckID{Name: RequirementsMetCheck}
+	switch status {
+	case models.HostStatusBinding:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg}
+	case models.HostStatusUnbinding, models.HostStatusUnbindingPendingUserAction:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg}
+	case models.HostStatusInstalled, models.HostStatusAddedToExistingCluster, models.HostStatusError:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.AgentInstallationStoppedReason, Message: v1beta1.AgentInstallationStoppedMsg}
+	case models.HostStatusInstalling:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.AgentAlreadyInstallingReason, Message: v1beta1.AgentAlreadyInstallingMsg}
+	case models.HostStatusKnown, models.HostStatusKnownUnbound:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.AgentReadyReason, Message: v1beta1.AgentReadyMsg}
+	default:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.AgentNotReadyReason, Message: v1beta1.AgentNotReadyMsg}
+	}
+}
+
+// requirementsMetCheckApproved is requirementsMetCheck's Known/KnownUnbound
+// branch, refined by whether the Agent has been approved - kept as a
+// separate step so an unapproved host surfaces AgentIsNotApprovedReason
+// instead of the generic Ready reason.
+func requirementsMetCheckApproved(status string, approved bool) CheckResult {
+	result := requirementsMetCheck(status)
+	if !approved && (status == models.HostStatusKnown || status == models.HostStatusKnownUnbound) {
+		return CheckResult{ID: result.ID, Healthy: false, Reason: v1beta1.AgentIsNotApprovedReason, Message: v1beta1.AgentIsNotApprovedMsg}
+	}
+	return result
+}
+
+func connectedCheck(status string) CheckResult {
+	id := CheckID{Name: ConnectedCheck}
+	if status == models.HostStatusDisconnected || status == models.HostStatusDisconnectedUnbound {
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.AgentDisconnectedReason, Message: v1beta1.AgentDisonnectedMsg}
+	}
+	return CheckResult{ID: id, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg}
+}
+
+func installedCheck(status, statusInfo string) CheckResult {
+	id := CheckID{Name: InstalledCheck}
+	switch status {
+	case models.HostStatusBinding:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg}
+	case models.HostStatusUnbinding, models.HostStatusUnbindingPendingUserAction:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg}
+	case models.HostStatusInstalled, models.HostStatusAddedToExistingCluster:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.InstalledReason, Message: v1beta1.InstalledMsg + " " + statusInfo}
+	case models.HostStatusError:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.InstallationFailedReason, Message: v1beta1.InstallationFailedMsg + " " + statusInfo}
+	case models.HostStatusInstalling:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.InstallationInProgressReason, Message: v1beta1.InstallationInProgressMsg + " " + statusInfo}
+	default:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg}
+	}
+}
+
+func validatedCheck(status string, categories []ValidationCategoryResult) CheckResult {
+	id := CheckID{Name: ValidatedCheck}
+	switch status {
+	case models.HostStatusBinding:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg}
+	case models.HostStatusUnbinding, models.HostStatusUnbindingPendingUserAction:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg}
+	}
+
+	var failing, pending []string
+	for _, category := range categories {
+		failing = append(failing, category.Failing...)
+		pending = append(pending, category.Pending...)
+	}
+	switch {
+	case len(pending) > 0:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.ValidationsUserPendingReason,
+			Message: v1beta1.AgentValidationsUserPendingMsg + " " + strings.Join(append(failing, pending...), ",")}
+	case len(failing) > 0:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.ValidationsFailingReason,
+			Message: v1beta1.AgentValidationsFailingMsg + " " + strings.Join(failing, ",")}
+	default:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg}
+	}
+}
+
+func boundCheck(status string) CheckResult {
+	id := CheckID{Name: BoundCheck}
+	switch status {
+	case models.HostStatusBinding:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg}
+	case models.HostStatusUnbinding:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg}
+	case models.HostStatusUnbindingPendingUserAction:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnbindingPendingUserActionReason, Message: v1beta1.UnbindingPendingUserActionMsg}
+	case models.HostStatusKnownUnbound, models.HostStatusInsufficientUnbound, models.HostStatusDiscoveringUnbound, models.HostStatusDisconnectedUnbound:
+		return CheckResult{ID: id, Healthy: false, Reason: v1beta1.UnboundReason, Message: v1beta1.UnboundMsg}
+	default:
+		return CheckResult{ID: id, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg}
+	}
+}
+
+// validationCheck is one entry in a ValidationsInfo category's array, as the
+// backend reports it.
+type validationCheck struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// parseValidationsInfo decodes raw (a host's ValidationsInfo JSON blob) into
+// one ValidationCategoryResult per category, categories sorted by name for a
+// deterministic result. Returns (nil, nil) for an empty/unset raw.
+func parseValidationsInfo(raw string) ([]ValidationCategoryResult, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var parsed map[string][]validationCheck
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse validationsInfo")
+	}
+
+	names := make([]string, 0, len(parsed))
+	for name := range parsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]ValidationCategoryResult, 0, len(names))
+	for _, name := range names {
+		var failing, pending []string
+		for _, check := range parsed[name] {
+			switch check.Status {
+			case "failure":
+				failing = append(failing, check.Message)
+			case "pending":
+				pending = append(pending, check.Message)
+			}
+		}
+		results = append(results, ValidationCategoryResult{Category: name, Failing: failing, Pending: pending})
+	}
+	return results, nil
+}
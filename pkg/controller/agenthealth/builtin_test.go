@@ -0,0 +1,162 @@
+package agenthealth
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/models"
+)
+
+func statusPtr(s string) *string { return &s }
+
+var _ = Describe("builtinChecker", func() {
+	tests := []struct {
+		name           string
+		status         string
+		approved       bool
+		statusInfo     string
+		validationInfo string
+		expectReqMet   CheckResult
+		expectConn     CheckResult
+		expectInst     CheckResult
+		expectValid    CheckResult
+		expectBound    CheckResult
+	}{
+		{
+			name:           "PendingForInput",
+			status:         models.HostStatusPendingForInput,
+			validationInfo: `{"some-check":[{"id":"checking1","status":"failure","message":"Host check1 is not OK"},{"id":"checking2","status":"success","message":"Host check2 is OK"},{"id":"checking3","status":"failure","message":"Host check3 is not OK"},{"id":"checking4","status":"pending","message":"Host check4 is pending"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: false, Reason: v1beta1.AgentNotReadyReason, Message: v1beta1.AgentNotReadyMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: false, Reason: v1beta1.ValidationsUserPendingReason, Message: v1beta1.AgentValidationsUserPendingMsg + " Host check1 is not OK,Host check3 is not OK,Host check4 is pending"},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg},
+		},
+		{
+			name:           "Known approved",
+			status:         models.HostStatusKnown,
+			approved:       true,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: true, Reason: v1beta1.AgentReadyReason, Message: v1beta1.AgentReadyMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg},
+		},
+		{
+			name:           "Known not approved",
+			status:         models.HostStatusKnown,
+			approved:       false,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: false, Reason: v1beta1.AgentIsNotApprovedReason, Message: v1beta1.AgentIsNotApprovedMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg},
+		},
+		{
+			name:           "KnownUnbound",
+			status:         models.HostStatusKnownUnbound,
+			approved:       true,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: true, Reason: v1beta1.AgentReadyReason, Message: v1beta1.AgentReadyMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: false, Reason: v1beta1.UnboundReason, Message: v1beta1.UnboundMsg},
+		},
+		{
+			name:           "Installed",
+			status:         models.HostStatusInstalled,
+			statusInfo:     "Done",
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: true, Reason: v1beta1.AgentInstallationStoppedReason, Message: v1beta1.AgentInstallationStoppedMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: true, Reason: v1beta1.InstalledReason, Message: v1beta1.InstalledMsg + " Done"},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg},
+		},
+		{
+			name:           "Disconnected",
+			status:         models.HostStatusDisconnected,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: false, Reason: v1beta1.AgentNotReadyReason, Message: v1beta1.AgentNotReadyMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: false, Reason: v1beta1.AgentDisconnectedReason, Message: v1beta1.AgentDisonnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.InstallationNotStartedReason, Message: v1beta1.InstallationNotStartedMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: true, Reason: v1beta1.ValidationsPassingReason, Message: v1beta1.AgentValidationsPassingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: true, Reason: v1beta1.BoundReason, Message: v1beta1.BoundMsg},
+		},
+		{
+			name:           "Binding",
+			status:         models.HostStatusBinding,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: false, Reason: v1beta1.BindingReason, Message: v1beta1.BindingMsg},
+		},
+		{
+			name:           "Unbinding Pending User Action",
+			status:         models.HostStatusUnbindingPendingUserAction,
+			validationInfo: `{"some-check":[{"id":"checking","status":"success","message":"Host is checked"}]}`,
+			expectReqMet:   CheckResult{ID: CheckID{Name: RequirementsMetCheck}, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg},
+			expectConn:     CheckResult{ID: CheckID{Name: ConnectedCheck}, Healthy: true, Reason: v1beta1.AgentConnectedReason, Message: v1beta1.AgentConnectedMsg},
+			expectInst:     CheckResult{ID: CheckID{Name: InstalledCheck}, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg},
+			expectValid:    CheckResult{ID: CheckID{Name: ValidatedCheck}, Healthy: false, Reason: v1beta1.UnbindingReason, Message: v1beta1.UnbindingMsg},
+			expectBound:    CheckResult{ID: CheckID{Name: BoundCheck}, Healthy: false, Reason: v1beta1.UnbindingPendingUserActionReason, Message: v1beta1.UnbindingPendingUserActionMsg},
+		},
+	}
+
+	for i := range tests {
+		t := tests[i]
+		It(t.name, func() {
+			host := &models.Host{
+				Status:          statusPtr(t.status),
+				StatusInfo:      statusPtr(t.statusInfo),
+				ValidationsInfo: t.validationInfo,
+				Approved:        t.approved,
+			}
+			health, err := NewBuiltinChecker().Evaluate(host)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health.RequirementsMet).To(Equal(t.expectReqMet))
+			Expect(health.Connected).To(Equal(t.expectConn))
+			Expect(health.Installed).To(Equal(t.expectInst))
+			Expect(health.Validated).To(Equal(t.expectValid))
+			Expect(health.Bound).To(Equal(t.expectBound))
+		})
+	}
+})
+
+var _ = Describe("RegisterChecker and Compose", func() {
+	It("folds an additional checker's checks into Extra only when the feature gate is enabled", func() {
+		AdditionalCheckersEnabled = false
+		additionalCheckers = nil
+
+		extra := CheckResult{ID: CheckID{Group: "disconnected", Name: "MirrorRegistryReachable"}, Healthy: true, Reason: "Reachable", Message: "mirror registry reachable"}
+		RegisterChecker(stubCheckerFunc(func(*models.Host) (AgentHealth, error) {
+			return AgentHealth{Extra: []CheckResult{extra}}, nil
+		}))
+		host := &models.Host{Status: statusPtr(models.HostStatusKnown), Approved: true}
+
+		health, err := Compose(host)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(health.Extra).To(BeEmpty())
+
+		AdditionalCheckersEnabled = true
+		RegisterChecker(stubCheckerFunc(func(*models.Host) (AgentHealth, error) {
+			return AgentHealth{Extra: []CheckResult{extra}}, nil
+		}))
+
+		health, err = Compose(host)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(health.Extra).To(ContainElement(extra))
+
+		AdditionalCheckersEnabled = false
+		additionalCheckers = nil
+	})
+})
+
+type stubCheckerFunc func(*models.Host) (AgentHealth, error)
+
+func (f stubCheckerFunc) Evaluate(host *models.Host) (AgentHealth, error) { return f(host) }
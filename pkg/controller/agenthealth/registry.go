@@ -0,0 +1,42 @@
+package agenthealth
+
+import "github.com/openshift/assisted-service/models"
+
+// AdditionalCheckersEnabled feature-gates RegisterChecker. It defaults to
+// false so deploying an additional checker (e.g. a disconnected-environment
+// or operator-specific readiness validator) doesn't change any cluster's
+// Agent conditions until an operator opts in.
+var AdditionalCheckersEnabled = false
+
+var additionalCheckers []HealthChecker
+
+// RegisterChecker adds checker to the set Compose folds into the builtin
+// evaluation. A no-op unless AdditionalCheckersEnabled is true, so a checker
+// can be wired up at startup behind a feature flag without every deployment
+// picking it up immediately.
+func RegisterChecker(checker HealthChecker) {
+	if !AdditionalCheckersEnabled {
+		return
+	}
+	additionalCheckers = append(additionalCheckers, checker)
+}
+
+// Compose evaluates host through the builtin checker and every checker
+// registered via RegisterChecker, returning the builtin's five core checks
+// unchanged with each additional checker's checks appended under Extra. This
+// is what AgentReconciler calls to get the full set of checks to translate
+// into Agent conditions.
+func Compose(host *models.Host) (AgentHealth, error) {
+	health, err := NewBuiltinChecker().Evaluate(host)
+	if err != nil {
+		return AgentHealth{}, err
+	}
+	for _, checker := range additionalCheckers {
+		extra, err := checker.Evaluate(host)
+		if err != nil {
+			return AgentHealth{}, err
+		}
+		health.Extra = append(health.Extra, extra.Checks()...)
+	}
+	return health, nil
+}
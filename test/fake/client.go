@@ -0,0 +1,33 @@
+// Package fake provides a pre-wired fake controller-runtime client builder
+// for controller tests, so each test file doesn't need to re-derive the
+// scheme and the set of types with a status subresource.
+package fake
+
+import (
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/assisted-service/internal/controller/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// NewFakeClientBuilder returns a *fakeclient.ClientBuilder pre-wired with
+// scheme.Scheme and with WithStatusSubresource set for every CRD whose
+// reconciler calls Status().Update, so that call behaves the same way it
+// does against a real API server instead of silently no-oping (the behavior
+// controller-runtime v0.15+ fake clients default to for any type not listed
+// here).
+func NewFakeClientBuilder(initObjs ...client.Object) *fakeclient.ClientBuilder {
+	builder := fakeclient.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(
+			&v1beta1.Agent{},
+			&v1beta1.InfraEnv{},
+			&v1beta1.AgentClusterInstall{},
+			&v1beta1.NMStateConfig{},
+			&v1beta1.AgentHealthCheck{},
+		)
+	if len(initObjs) > 0 {
+		builder = builder.WithObjects(initObjs...)
+	}
+	return builder
+}